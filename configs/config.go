@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -17,6 +18,40 @@ type Config struct {
 	ArchiveAfter          time.Duration
 	ReminderCheckInterval time.Duration
 	LoggerBufferSize      int
+	StorageType           string // memory или file
+	DataDir               string
+	SnapshotEvery         int
+
+	// ReminderChannels - включенные каналы доставки напоминаний: console, email, webhook
+	ReminderChannels []string
+	SMTPHost         string
+	SMTPPort         int
+	SMTPUsername     string
+	SMTPPassword     string
+	SMTPFrom         string
+	WebhookSecret    string
+	WebhookTimeout   time.Duration
+
+	// Повторные попытки доставки напоминаний
+	ReminderMaxAttempts    int
+	ReminderRetryBaseDelay time.Duration
+	ReminderRetryMaxDelay  time.Duration
+
+	// Повторные попытки доставки webhook по подпискам на изменения событий
+	WebhookMaxAttempts    int
+	WebhookRetryBaseDelay time.Duration
+	WebhookRetryMaxDelay  time.Duration
+
+	// AdminToken включает административные эндпоинты
+	AdminToken string
+
+	// Проверка JWT: секрет для HS256/384/512 и/или открытый ключ RSA (PEM)
+	// для RS256/384/512. Без ключей принимаются только ключи API.
+	AuthJWTSecret        string
+	AuthJWTPublicKeyFile string
+	AuthJWTIssuer        string
+	AuthJWTAudience      string
+	AuthJWTLeeway        time.Duration
 }
 
 // Load загружает конфигурацию из .env файла, переменных окружения и флагов
@@ -27,11 +62,34 @@ func Load() *Config {
 	}
 
 	cfg := &Config{
-		Port:                  getEnv("PORT", ""),
-		CleanupInterval:       getDurationEnv("CLEANUP_INTERVAL", 0),
-		ArchiveAfter:          getDurationEnv("ARCHIVE_AFTER", 0),
-		ReminderCheckInterval: getDurationEnv("REMINDER_CHECK_INTERVAL", 0),
-		LoggerBufferSize:      getIntEnv("LOGGER_BUFFER_SIZE", 0),
+		Port:                   getEnv("PORT", ""),
+		CleanupInterval:        getDurationEnv("CLEANUP_INTERVAL", 0),
+		ArchiveAfter:           getDurationEnv("ARCHIVE_AFTER", 0),
+		ReminderCheckInterval:  getDurationEnv("REMINDER_CHECK_INTERVAL", 0),
+		LoggerBufferSize:       getIntEnv("LOGGER_BUFFER_SIZE", 0),
+		StorageType:            getEnv("STORAGE_TYPE", "memory"),
+		DataDir:                getEnv("DATA_DIR", "./data"),
+		SnapshotEvery:          getIntEnv("SNAPSHOT_EVERY", 1000),
+		ReminderChannels:       getListEnv("REMINDER_CHANNELS", []string{"console"}),
+		SMTPHost:               getEnv("SMTP_HOST", ""),
+		SMTPPort:               getIntEnv("SMTP_PORT", 587),
+		SMTPUsername:           getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:           getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:               getEnv("SMTP_FROM", ""),
+		WebhookSecret:          getEnv("WEBHOOK_SECRET", ""),
+		WebhookTimeout:         getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+		ReminderMaxAttempts:    getIntEnv("REMINDER_MAX_ATTEMPTS", 5),
+		ReminderRetryBaseDelay: getDurationEnv("REMINDER_RETRY_BASE_DELAY", 30*time.Second),
+		ReminderRetryMaxDelay:  getDurationEnv("REMINDER_RETRY_MAX_DELAY", time.Hour),
+		WebhookMaxAttempts:     getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+		WebhookRetryBaseDelay:  getDurationEnv("WEBHOOK_RETRY_BASE_DELAY", 10*time.Second),
+		WebhookRetryMaxDelay:   getDurationEnv("WEBHOOK_RETRY_MAX_DELAY", time.Hour),
+		AdminToken:             getEnv("ADMIN_TOKEN", ""),
+		AuthJWTSecret:          getEnv("AUTH_JWT_SECRET", ""),
+		AuthJWTPublicKeyFile:   getEnv("AUTH_JWT_PUBLIC_KEY_FILE", ""),
+		AuthJWTIssuer:          getEnv("AUTH_JWT_ISSUER", ""),
+		AuthJWTAudience:        getEnv("AUTH_JWT_AUDIENCE", ""),
+		AuthJWTLeeway:          getDurationEnv("AUTH_JWT_LEEWAY", 30*time.Second),
 	}
 
 	// Проверка обязательных параметров
@@ -50,8 +108,33 @@ func Load() *Config {
 	if cfg.LoggerBufferSize == 0 {
 		log.Fatal("LOGGER_BUFFER_SIZE is required. Set it in .env file or environment variable")
 	}
+	if cfg.StorageType != "memory" && cfg.StorageType != "file" {
+		log.Fatal("STORAGE_TYPE must be either memory or file")
+	}
+	if cfg.ReminderMaxAttempts < 1 {
+		log.Fatal("REMINDER_MAX_ATTEMPTS must be at least 1")
+	}
+	if cfg.WebhookMaxAttempts < 1 {
+		log.Fatal("WEBHOOK_MAX_ATTEMPTS must be at least 1")
+	}
+	for _, channel := range cfg.ReminderChannels {
+		switch channel {
+		case "console":
+		case "email":
+			if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+				log.Fatal("SMTP_HOST and SMTP_FROM are required for the email reminder channel")
+			}
+		case "webhook":
+			if cfg.WebhookSecret == "" {
+				log.Fatal("WEBHOOK_SECRET is required for the webhook reminder channel")
+			}
+		default:
+			log.Fatalf("Unknown reminder channel %q in REMINDER_CHANNELS", channel)
+		}
+	}
 
 	flag.StringVar(&cfg.Port, "port", cfg.Port, "Server port")
+	flag.StringVar(&cfg.DataDir, "data-dir", cfg.DataDir, "Data directory for file storage")
 	flag.Parse()
 
 	return cfg
@@ -73,6 +156,21 @@ func getIntEnv(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getListEnv разбирает список значений через запятую
+func getListEnv(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256" // SHA-256 для HS256 и RS256
+	_ "crypto/sha512" // SHA-384 и SHA-512 для HS384/512 и RS384/512
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// JWTConfig задает ключи и ограничения проверки JWT. Должен быть задан
+// хотя бы один ключ: HMACSecret для HS256/384/512 или RSAPublicKey для RS256/384/512.
+type JWTConfig struct {
+	HMACSecret   []byte
+	RSAPublicKey *rsa.PublicKey
+	// Issuer и Audience, если заданы, должны совпадать с iss и aud токена
+	Issuer   string
+	Audience string
+	// Leeway - допустимое расхождение часов при проверке exp и nbf
+	Leeway time.Duration
+}
+
+// JWTVerifier проверяет подпись и срок действия JWT (RFC 7519).
+// Пользователь берется из claim sub.
+type JWTVerifier struct {
+	cfg JWTConfig
+	now func() time.Time
+}
+
+// NewJWTVerifier создает проверку JWT
+func NewJWTVerifier(cfg JWTConfig) *JWTVerifier {
+	return &JWTVerifier{cfg: cfg, now: time.Now}
+}
+
+// jwtHeader - заголовок JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+}
+
+// jwtClaims - проверяемые поля JWT
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+// audience разбирает aud, заданный строкой или массивом строк
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Verify проверяет токен и возвращает пользователя. Токен без exp отклоняется,
+// чтобы утекший токен не действовал бессрочно.
+func (v *JWTVerifier) Verify(token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidCredentials)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed header", ErrInvalidCredentials)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidCredentials)
+	}
+	if err := v.verifySignature(header.Alg, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("%w: malformed claims", ErrInvalidCredentials)
+	}
+	if err := v.validateClaims(&claims); err != nil {
+		return nil, err
+	}
+	return &Principal{UserID: claims.Subject, Method: MethodJWT}, nil
+}
+
+// verifySignature проверяет подпись алгоритмом из заголовка. Алгоритм
+// должен соответствовать типу настроенного ключа: HMAC-секрет никогда
+// не используется для RS*, а открытый ключ RSA - для HS*.
+func (v *JWTVerifier) verifySignature(alg, signed string, signature []byte) error {
+	if len(alg) != 5 {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidCredentials, alg)
+	}
+	hash, ok := jwtHashes[alg[2:]]
+	if !ok {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidCredentials, alg)
+	}
+	switch {
+	case strings.HasPrefix(alg, "HS") && len(v.cfg.HMACSecret) > 0:
+		mac := hmac.New(hash.New, v.cfg.HMACSecret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidCredentials)
+		}
+		return nil
+	case strings.HasPrefix(alg, "RS") && v.cfg.RSAPublicKey != nil:
+		h := hash.New()
+		h.Write([]byte(signed))
+		if err := rsa.VerifyPKCS1v15(v.cfg.RSAPublicKey, hash, h.Sum(nil), signature); err != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidCredentials)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidCredentials, alg)
+	}
+}
+
+// jwtHashes сопоставляет размер из имени алгоритма (HS256, RS512) с хеш-функцией
+var jwtHashes = map[string]crypto.Hash{
+	"256": crypto.SHA256,
+	"384": crypto.SHA384,
+	"512": crypto.SHA512,
+}
+
+func (v *JWTVerifier) validateClaims(claims *jwtClaims) error {
+	now := v.now()
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: missing sub", ErrInvalidCredentials)
+	}
+	if claims.ExpiresAt == nil {
+		return fmt.Errorf("%w: missing exp", ErrInvalidCredentials)
+	}
+	if !now.Before(time.Unix(*claims.ExpiresAt, 0).Add(v.cfg.Leeway)) {
+		return fmt.Errorf("%w: token expired", ErrInvalidCredentials)
+	}
+	if claims.NotBefore != nil && now.Add(v.cfg.Leeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return fmt.Errorf("%w: token not yet valid", ErrInvalidCredentials)
+	}
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidCredentials)
+	}
+	if v.cfg.Audience != "" && !claims.Audience.contains(v.cfg.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidCredentials)
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ParseRSAPublicKey разбирает открытый ключ RSA в формате PEM
+// (PUBLIC KEY из PKIX или RSA PUBLIC KEY из PKCS #1)
+func ParseRSAPublicKey(data []byte) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return nil, errors.New("public key is not RSA")
+		}
+		return rsaKey, nil
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+func encodeSegment(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signHS256(secret []byte, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": "HS256", "typ": "JWT"}) + "." + encodeSegment(claims)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": "RS256", "typ": "JWT"}) + "." + encodeSegment(claims)
+	sum := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier_HMAC(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	secret := []byte("jwt-secret")
+	v := NewJWTVerifier(JWTConfig{HMACSecret: secret, Issuer: "issuer", Audience: "calendar"})
+	v.now = func() time.Time { return now }
+
+	valid := map[string]interface{}{"sub": "user1", "iss": "issuer", "aud": []string{"other", "calendar"}, "exp": now.Add(time.Hour).Unix()}
+	p, err := v.Verify(signHS256(secret, valid))
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if p.UserID != "user1" || p.Method != MethodJWT {
+		t.Errorf("Unexpected principal %+v", p)
+	}
+
+	tests := map[string]string{
+		"expired":        signHS256(secret, map[string]interface{}{"sub": "user1", "iss": "issuer", "aud": "calendar", "exp": now.Add(-time.Minute).Unix()}),
+		"without exp":    signHS256(secret, map[string]interface{}{"sub": "user1", "iss": "issuer", "aud": "calendar"}),
+		"not yet valid":  signHS256(secret, map[string]interface{}{"sub": "user1", "iss": "issuer", "aud": "calendar", "exp": now.Add(2 * time.Hour).Unix(), "nbf": now.Add(time.Hour).Unix()}),
+		"wrong audience": signHS256(secret, map[string]interface{}{"sub": "user1", "iss": "issuer", "aud": "other", "exp": now.Add(time.Hour).Unix()}),
+		"wrong secret":   signHS256([]byte("other"), valid),
+		"alg none":       encodeSegment(map[string]string{"alg": "none"}) + "." + encodeSegment(valid) + ".",
+		"malformed":      "not-a-token",
+	}
+	for name, token := range tests {
+		if _, err := v.Verify(token); !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("%s: expected ErrInvalidCredentials, got %v", name, err)
+		}
+	}
+}
+
+func TestJWTVerifier_RSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	publicKey, err := ParseRSAPublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatalf("Failed to parse public key: %v", err)
+	}
+	v := NewJWTVerifier(JWTConfig{RSAPublicKey: publicKey})
+
+	claims := map[string]interface{}{"sub": "user1", "exp": time.Now().Add(time.Hour).Unix()}
+	if p, err := v.Verify(signRS256(t, key, claims)); err != nil || p.UserID != "user1" {
+		t.Fatalf("Expected valid token, got %+v %v", p, err)
+	}
+
+	// HS256 с открытым ключом в роли секрета не принимается
+	if _, err := v.Verify(signHS256(der, claims)); !errors.Is(err, ErrInvalidCredentials) {
+		t.Errorf("Expected HS256 to be rejected without HMAC secret, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// Package auth проверяет учетные данные запросов и передает
+// аутентифицированного пользователя обработчикам через контекст
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrInvalidCredentials - учетные данные не прошли проверку
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	// ErrMissingCredentials - запрос не содержит учетных данных
+	ErrMissingCredentials = errors.New("missing credentials")
+)
+
+// Способы аутентификации
+const (
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api_key"
+)
+
+// Principal - пользователь, от имени которого выполняется запрос
+type Principal struct {
+	UserID string
+	Method string
+	// KeyID - ID ключа API, которым аутентифицирован запрос
+	KeyID string
+}
+
+type principalKey struct{}
+
+// WithPrincipal возвращает контекст с аутентифицированным пользователем
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает аутентифицированного пользователя запроса
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
@@ -0,0 +1,725 @@
+// Package caldav реализует подмножество CalDAV (RFC 4791), достаточное
+// для двусторонней синхронизации с календарными приложениями
+package caldav
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/auth"
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/ical"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+const (
+	// Prefix - префикс ресурсов CalDAV
+	Prefix = "/caldav/"
+
+	// calendarName - имя коллекции событий пользователя
+	calendarName = "calendar"
+	// objectSuffix - расширение календарных объектов
+	objectSuffix = ".ics"
+	// maxObjectSize ограничивает размер календарного объекта в PUT
+	maxObjectSize = 1 << 20
+
+	methodPropfind = "PROPFIND"
+	methodReport   = "REPORT"
+
+	allowedMethods = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+)
+
+// Предусловия CalDAV, нарушение которых возвращается в теле ошибки
+var (
+	condValidData       = xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"}
+	condValidObject     = xml.Name{Space: nsCalDAV, Local: "valid-calendar-object-resource"}
+	condSupportedComp   = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component"}
+	condSupportedData   = xml.Name{Space: nsCalDAV, Local: "supported-calendar-data"}
+	condSupportedFilter = xml.Name{Space: nsCalDAV, Local: "supported-filter"}
+	condSupportedReport = xml.Name{Space: nsDAV, Local: "supported-report"}
+)
+
+// Handler обслуживает ресурсы CalDAV пользователя:
+//
+//	/caldav/{user}/                 принципал и домашняя коллекция календарей
+//	/caldav/{user}/calendar/        календарь со всеми событиями пользователя
+//	/caldav/{user}/calendar/{uid}.ics  календарный объект: событие или серия с заменами
+//
+// Клиенты синхронизируются по CS:getctag коллекции и DAV:getetag объектов.
+type Handler struct {
+	service *service.EventService
+	logger  logger.Logger
+
+	// mu сериализует изменения, чтобы проверка If-Match и запись были атомарны
+	mu sync.Mutex
+}
+
+// NewHandler создает новый обработчик CalDAV
+func NewHandler(service *service.EventService, log logger.Logger) *Handler {
+	return &Handler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// resourceKind - тип ресурса CalDAV
+type resourceKind int
+
+const (
+	kindPrincipal resourceKind = iota
+	kindCalendar
+	kindObject
+)
+
+// resource - ресурс, адресованный путем запроса
+type resource struct {
+	kind   resourceKind
+	userID string
+	uid    string
+}
+
+// ServeHTTP разбирает путь ресурса и вызывает обработчик метода
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	res, ok := parsePath(r.URL.EscapedPath())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Календарь доступен только своему пользователю
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if principal.UserID != res.userID {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("DAV", "1, 3, calendar-access")
+		w.Header().Set("Allow", allowedMethods)
+		w.WriteHeader(http.StatusOK)
+	case methodPropfind:
+		h.propfind(w, r, res)
+	case methodReport:
+		h.report(w, r, res)
+	case http.MethodGet, http.MethodHead:
+		h.get(w, r, res)
+	case http.MethodPut:
+		h.put(w, r, res)
+	case http.MethodDelete:
+		h.delete(w, r, res)
+	default:
+		w.Header().Set("Allow", allowedMethods)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// propfind handles PROPFIND (RFC 4918, 9.1). Глубина infinity обрабатывается как 1.
+func (h *Handler) propfind(w http.ResponseWriter, r *http.Request, res resource) {
+	var req propfindRequest
+	parsed, err := decodeXML(r.Body, &req)
+	if err != nil {
+		http.Error(w, "Malformed PROPFIND body", http.StatusBadRequest)
+		return
+	}
+	sel := propSelection{all: !parsed || req.AllProp != nil, namesOnly: req.PropName != nil}
+	if req.Prop != nil {
+		sel.all, sel.names = false, req.Prop.Names
+	}
+	deep := r.Header.Get("Depth") != "0"
+
+	ms := newMultistatus()
+	switch res.kind {
+	case kindPrincipal:
+		sel.write(ms, principalHref(res.userID), principalProps(res.userID))
+		if deep {
+			ctag, err := h.ctag(res.userID)
+			if err != nil {
+				h.internalError(w, err)
+				return
+			}
+			sel.write(ms, calendarHref(res.userID), calendarProps(res.userID, ctag))
+		}
+
+	case kindCalendar:
+		objects, err := h.objects(res.userID)
+		if err != nil {
+			h.internalError(w, err)
+			return
+		}
+		sel.write(ms, calendarHref(res.userID), calendarProps(res.userID, ctagOf(objects)))
+		if deep {
+			for _, obj := range objects {
+				sel.write(ms, objectHref(res.userID, obj.uid), obj.props(sel.wants(propCalendarData)))
+			}
+		}
+
+	case kindObject:
+		obj, err := h.object(res.userID, res.uid)
+		if err != nil {
+			h.internalError(w, err)
+			return
+		}
+		if obj == nil {
+			http.NotFound(w, r)
+			return
+		}
+		sel.write(ms, objectHref(res.userID, obj.uid), obj.props(sel.wants(propCalendarData)))
+	}
+	ms.send(w)
+}
+
+// report handles REPORT calendar-query и calendar-multiget (RFC 4791, 7.8 и 7.9)
+func (h *Handler) report(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindCalendar {
+		sendError(w, http.StatusForbidden, condSupportedReport)
+		return
+	}
+	var req reportRequest
+	if _, err := decodeXML(r.Body, &req); err != nil {
+		http.Error(w, "Malformed REPORT body", http.StatusBadRequest)
+		return
+	}
+	sel := propSelection{names: []xml.Name{propGetETag}}
+	if req.Prop != nil {
+		sel.names = req.Prop.Names
+	}
+
+	objects, err := h.objects(res.userID)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+
+	ms := newMultistatus()
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		match, ok := queryMatcher(req.Filter)
+		if !ok {
+			sendError(w, http.StatusForbidden, condSupportedFilter)
+			return
+		}
+		for _, obj := range objects {
+			if match(obj) {
+				sel.write(ms, objectHref(res.userID, obj.uid), obj.props(sel.wants(propCalendarData)))
+			}
+		}
+
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		byUID := make(map[string]*object, len(objects))
+		for _, obj := range objects {
+			byUID[obj.uid] = obj
+		}
+		for _, href := range req.Hrefs {
+			href = strings.TrimSpace(href)
+			target, ok := parseHref(href)
+			obj := byUID[target.uid]
+			if !ok || target.kind != kindObject || target.userID != res.userID || obj == nil {
+				ms.status(href, http.StatusNotFound)
+				continue
+			}
+			sel.write(ms, objectHref(res.userID, obj.uid), obj.props(sel.wants(propCalendarData)))
+		}
+
+	default:
+		sendError(w, http.StatusForbidden, condSupportedReport)
+		return
+	}
+	ms.send(w)
+}
+
+// get handles GET и HEAD календарного объекта
+func (h *Handler) get(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindObject {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	obj, err := h.object(res.userID, res.uid)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	if obj == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("ETag", obj.etag())
+	if etagMatches(r.Header.Get("If-None-Match"), obj.etag()) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Length", strconv.Itoa(len(obj.data())))
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(obj.data())
+	}
+}
+
+// put handles PUT календарного объекта (RFC 4791, 5.3.2). Объект создается
+// или заменяется целиком; ETag в ответе не передается, так как сохраненное
+// представление отличается от присланного клиентом.
+func (h *Handler) put(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindObject {
+		w.Header().Set("Allow", "OPTIONS, PROPFIND, REPORT")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		if mediaType, _, _ := mime.ParseMediaType(ct); mediaType != "text/calendar" {
+			sendError(w, http.StatusUnsupportedMediaType, condSupportedData)
+			return
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	existing, err := h.object(res.userID, res.uid)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	if !preconditionsMet(r, existing) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	loc, err := h.service.UserLocation(res.userID)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	items, err := ical.Decode(io.LimitReader(r.Body, maxObjectSize), loc)
+	if err != nil {
+		sendError(w, http.StatusForbidden, condValidData)
+		return
+	}
+	if len(items) == 0 {
+		sendError(w, http.StatusForbidden, condSupportedComp)
+		return
+	}
+	events := make([]*domain.Event, len(items))
+	for i, item := range items {
+		if item.Err != nil {
+			sendError(w, http.StatusForbidden, condValidData)
+			return
+		}
+		// Объект содержит события одного UID, совпадающего с именем ресурса
+		if item.UID != res.uid {
+			sendError(w, http.StatusForbidden, condValidObject)
+			return
+		}
+		// Проверить события до сохранения, чтобы объект не сохранился частично
+		check := item.Event.Clone()
+		check.UserID = res.userID
+		if err := check.Validate(); err != nil {
+			sendError(w, http.StatusForbidden, condValidData)
+			return
+		}
+		events[i] = item.Event
+	}
+
+	results, err := h.service.ImportEvents(res.userID, events)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		if isDataError(result.Err) {
+			sendError(w, http.StatusForbidden, condValidObject)
+			return
+		}
+		h.internalError(w, result.Err)
+		return
+	}
+
+	if existing == nil {
+		w.Header().Set("Location", objectHref(res.userID, res.uid))
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// delete handles DELETE календарного объекта вместе с заменами вхождений
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request, res resource) {
+	if res.kind != kindObject {
+		http.Error(w, "Collections cannot be deleted", http.StatusForbidden)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	obj, err := h.object(res.userID, res.uid)
+	if err != nil {
+		h.internalError(w, err)
+		return
+	}
+	if obj == nil {
+		http.NotFound(w, r)
+		return
+	}
+	if !preconditionsMet(r, obj) {
+		w.WriteHeader(http.StatusPreconditionFailed)
+		return
+	}
+
+	// Удаление серии удаляет и ее замены
+	for _, event := range obj.events {
+		if event.IsOverride() && obj.events[0].IsRecurring() {
+			continue
+		}
+		if err := h.service.DeleteEvent(res.userID, event.ID); err != nil && !errors.Is(err, domain.ErrEventNotFound) {
+			h.internalError(w, err)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// objects возвращает календарные объекты пользователя, упорядоченные по UID
+func (h *Handler) objects(userID string) ([]*object, error) {
+	events, err := h.service.ExportEvents(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byUID := make(map[string]*object)
+	var result []*object
+	for _, event := range events {
+		uid := ical.UID(event)
+		obj, ok := byUID[uid]
+		if !ok {
+			obj = &object{uid: uid}
+			byUID[uid] = obj
+			result = append(result, obj)
+		}
+		obj.events = append(obj.events, event)
+	}
+	for _, obj := range result {
+		// Серия выводится перед своими заменами
+		sort.SliceStable(obj.events, func(i, j int) bool {
+			return !obj.events[i].IsOverride() && obj.events[j].IsOverride()
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].uid < result[j].uid })
+	return result, nil
+}
+
+// object возвращает календарный объект по UID или nil, если его нет
+func (h *Handler) object(userID, uid string) (*object, error) {
+	objects, err := h.objects(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objects {
+		if obj.uid == uid {
+			return obj, nil
+		}
+	}
+	return nil, nil
+}
+
+// ctag возвращает метку состояния календаря пользователя
+func (h *Handler) ctag(userID string) (string, error) {
+	objects, err := h.objects(userID)
+	if err != nil {
+		return "", err
+	}
+	return ctagOf(objects), nil
+}
+
+func (h *Handler) internalError(w http.ResponseWriter, err error) {
+	h.logger.Log(logger.LevelError, "CalDAV request failed", map[string]interface{}{
+		"error": err.Error(),
+	})
+	http.Error(w, "Internal server error", http.StatusInternalServerError)
+}
+
+// object - календарный объект: событие или серия с заменами вхождений
+type object struct {
+	uid    string
+	events []*domain.Event
+
+	encoded []byte
+}
+
+func (o *object) data() []byte {
+	if o.encoded == nil {
+		o.encoded = ical.EncodeObject(o.events)
+	}
+	return o.encoded
+}
+
+// etag - сильный тег представления объекта
+func (o *object) etag() string {
+	sum := sha256.Sum256(o.data())
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// props возвращает свойства объекта; calendar-data добавляется только по запросу
+func (o *object) props(withData bool) []property {
+	props := []property{
+		{name: propResourceType},
+		textProp(propGetETag, o.etag()),
+		textProp(propGetContentType, "text/calendar; charset=utf-8; component=vevent"),
+	}
+	if withData {
+		props = append(props, textProp(propCalendarData, string(o.data())))
+	}
+	return props
+}
+
+// matches проверяет, есть ли у объекта вхождения в интервале [start, end)
+func (o *object) matches(start, end time.Time) bool {
+	for _, event := range o.events {
+		if event.IsRecurring() {
+			if len(event.Occurrences(start, end)) > 0 {
+				return true
+			}
+			continue
+		}
+		if event.Overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// ctagOf вычисляет метку состояния календаря: она меняется при создании,
+// изменении и удалении любого события
+func ctagOf(objects []*object) string {
+	h := sha256.New()
+	for _, obj := range objects {
+		for _, event := range obj.events {
+			io.WriteString(h, event.ID+"\x00"+event.UpdatedAt.UTC().Format(time.RFC3339Nano)+"\x00")
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+func principalProps(userID string) []property {
+	return []property{
+		{name: propResourceType, inner: "<D:collection/><D:principal/>"},
+		textProp(propDisplayName, userID),
+		hrefProp(propCurrentUser, principalHref(userID)),
+		hrefProp(propPrincipalURL, principalHref(userID)),
+		hrefProp(propCalendarHomeSet, principalHref(userID)),
+	}
+}
+
+func calendarProps(userID, ctag string) []property {
+	return []property{
+		{name: propResourceType, inner: "<D:collection/><C:calendar/>"},
+		textProp(propDisplayName, "Events"),
+		textProp(propGetCTag, ctag),
+		hrefProp(propCurrentUser, principalHref(userID)),
+		{name: propSupportedComponent, inner: `<C:comp name="VEVENT"/>`},
+		{name: propPrivilegeSet, inner: "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>" +
+			"<D:privilege><D:write-content/></D:privilege><D:privilege><D:bind/></D:privilege><D:privilege><D:unbind/></D:privilege>"},
+		{name: propSupportedReportSet, inner: "<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+			"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>"},
+	}
+}
+
+// propSelection описывает запрошенные свойства: все, только имена или список
+type propSelection struct {
+	all       bool
+	namesOnly bool
+	names     []xml.Name
+}
+
+func (s propSelection) wants(name xml.Name) bool {
+	for _, n := range s.names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// write добавляет ответ о ресурсе с выбранными свойствами
+func (s propSelection) write(ms *multistatus, href string, props []property) {
+	if s.all || s.namesOnly {
+		found := make([]property, 0, len(props))
+		for _, p := range props {
+			if s.namesOnly {
+				p.inner = ""
+			}
+			found = append(found, p)
+		}
+		ms.propstat(href, found, nil)
+		return
+	}
+
+	var found []property
+	var missing []xml.Name
+	for _, name := range s.names {
+		ok := false
+		for _, p := range props {
+			if p.name == name {
+				found = append(found, p)
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			missing = append(missing, name)
+		}
+	}
+	ms.propstat(href, found, missing)
+}
+
+// queryMatcher строит проверку объектов по фильтру calendar-query.
+// Поддерживаются фильтры компонента VEVENT с time-range; для остальных
+// фильтров возвращается false.
+func queryMatcher(filter *compFilter) (func(*object) bool, bool) {
+	all := func(*object) bool { return true }
+	if filter == nil {
+		return all, true
+	}
+	if filter.Name != "VCALENDAR" || filter.TimeRange != nil || filter.IsNotDefined != nil || len(filter.PropFilters) > 0 || len(filter.CompFilters) > 1 {
+		return nil, false
+	}
+	if len(filter.CompFilters) == 0 {
+		return all, true
+	}
+
+	event := filter.CompFilters[0]
+	if event.IsNotDefined != nil || len(event.PropFilters) > 0 || len(event.CompFilters) > 0 {
+		return nil, false
+	}
+	if event.Name != "VEVENT" {
+		// В календаре нет других компонентов
+		return func(*object) bool { return false }, true
+	}
+	if event.TimeRange == nil {
+		return all, true
+	}
+	start, end, err := event.TimeRange.bounds()
+	if err != nil || !end.After(start) {
+		return nil, false
+	}
+	return func(obj *object) bool { return obj.matches(start, end) }, true
+}
+
+// preconditionsMet проверяет заголовки If-Match и If-None-Match для изменения
+// объекта existing (nil, если объекта нет)
+func preconditionsMet(r *http.Request, existing *object) bool {
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		if existing == nil || !etagMatches(ifMatch, existing.etag()) {
+			return false
+		}
+	}
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		if existing != nil && etagMatches(ifNoneMatch, existing.etag()) {
+			return false
+		}
+	}
+	return true
+}
+
+// etagMatches проверяет список тегов заголовка If-Match или If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// isDataError проверяет, вызвана ли ошибка сохранения содержимым объекта
+func isDataError(err error) bool {
+	for _, target := range []error{
+		domain.ErrInvalidEventText,
+		domain.ErrInvalidDate,
+		domain.ErrInvalidEndTime,
+		domain.ErrInvalidTimeZone,
+		domain.ErrInvalidReminder,
+		domain.ErrInvalidRecurrence,
+		domain.ErrEventNotFound,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePath разбирает путь ресурса после Prefix
+func parsePath(path string) (resource, bool) {
+	rest, ok := strings.CutPrefix(path, Prefix)
+	if !ok {
+		return resource{}, false
+	}
+	segments := strings.Split(strings.TrimSuffix(rest, "/"), "/")
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil || decoded == "" {
+			return resource{}, false
+		}
+		segments[i] = decoded
+	}
+
+	res := resource{userID: segments[0]}
+	switch {
+	case len(segments) == 1:
+		res.kind = kindPrincipal
+	case len(segments) == 2 && segments[1] == calendarName:
+		res.kind = kindCalendar
+	case len(segments) == 3 && segments[1] == calendarName && strings.HasSuffix(segments[2], objectSuffix):
+		res.kind = kindObject
+		res.uid = strings.TrimSuffix(segments[2], objectSuffix)
+		if res.uid == "" {
+			return resource{}, false
+		}
+	default:
+		return resource{}, false
+	}
+	return res, true
+}
+
+// parseHref разбирает href из тела запроса: абсолютный URL или путь
+func parseHref(href string) (resource, bool) {
+	u, err := url.Parse(href)
+	if err != nil {
+		return resource{}, false
+	}
+	return parsePath(u.EscapedPath())
+}
+
+func principalHref(userID string) string {
+	return Prefix + url.PathEscape(userID) + "/"
+}
+
+func calendarHref(userID string) string {
+	return principalHref(userID) + calendarName + "/"
+}
+
+func objectHref(userID, uid string) string {
+	return calendarHref(userID) + url.PathEscape(uid) + objectSuffix
+}
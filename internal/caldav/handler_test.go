@@ -0,0 +1,352 @@
+package caldav
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/auth"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Log(logger.LogLevel, string, map[string]interface{}) {}
+
+func (nopLogger) Close() error { return nil }
+
+// client - минимальный клиент CalDAV, повторяющий запросы календарных приложений
+type client struct {
+	t      *testing.T
+	server *httptest.Server
+}
+
+// response - ответ о ресурсе из 207 Multi-Status
+type response struct {
+	Href      string `xml:"href"`
+	Status    string `xml:"status"`
+	Propstats []struct {
+		Status string `xml:"status"`
+		Prop   struct {
+			Inner []byte `xml:",innerxml"`
+		} `xml:"prop"`
+	} `xml:"propstat"`
+}
+
+// prop возвращает текст найденного свойства
+func (r response) prop(local string) (string, bool) {
+	for _, ps := range r.Propstats {
+		if !strings.Contains(ps.Status, " 200 ") {
+			continue
+		}
+		d := xml.NewDecoder(strings.NewReader("<prop>" + string(ps.Prop.Inner) + "</prop>"))
+		for {
+			token, err := d.Token()
+			if err != nil {
+				break
+			}
+			if start, ok := token.(xml.StartElement); ok && start.Name.Local == local {
+				var value struct {
+					Text string `xml:",chardata"`
+					Href string `xml:"href"`
+				}
+				d.DecodeElement(&value, &start)
+				if value.Href != "" {
+					return value.Href, true
+				}
+				return value.Text, true
+			}
+		}
+	}
+	return "", false
+}
+
+// asUser передает обработчику аутентифицированного пользователя,
+// как это делает AuthMiddleware
+func asUser(h http.Handler, userID string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), &auth.Principal{UserID: userID})))
+	})
+}
+
+func newTestClient(t *testing.T) *client {
+	events := service.NewEventService(storage.NewMemoryRepository())
+	server := httptest.NewServer(asUser(NewHandler(events, nopLogger{}), "user1"))
+	t.Cleanup(server.Close)
+	return &client{t: t, server: server}
+}
+
+func (c *client) do(method, path, body string, header map[string]string) *http.Response {
+	c.t.Helper()
+	req, err := http.NewRequest(method, c.server.URL+path, strings.NewReader(body))
+	if err != nil {
+		c.t.Fatalf("NewRequest failed: %v", err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	resp, err := c.server.Client().Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s failed: %v", method, path, err)
+	}
+	c.t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// multistatus выполняет PROPFIND или REPORT и разбирает ответы по href
+func (c *client) multistatus(method, path, depth, body string) map[string]response {
+	c.t.Helper()
+	resp := c.do(method, path, body, map[string]string{"Depth": depth, "Content-Type": "application/xml"})
+	if resp.StatusCode != http.StatusMultiStatus {
+		data, _ := io.ReadAll(resp.Body)
+		c.t.Fatalf("%s %s: expected 207, got %d: %s", method, path, resp.StatusCode, data)
+	}
+	var ms struct {
+		Responses []response `xml:"response"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		c.t.Fatalf("Malformed multistatus: %v", err)
+	}
+	result := make(map[string]response, len(ms.Responses))
+	for _, r := range ms.Responses {
+		result[r.Href] = r
+	}
+	return result
+}
+
+func (c *client) put(path, data string, header map[string]string) int {
+	c.t.Helper()
+	if header == nil {
+		header = map[string]string{}
+	}
+	header["Content-Type"] = "text/calendar; charset=utf-8"
+	return c.do(http.MethodPut, path, data, header).StatusCode
+}
+
+func vcalendar(events ...string) string {
+	return "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//Test//EN\r\n" + strings.Join(events, "") + "END:VCALENDAR\r\n"
+}
+
+func vevent(uid, start, summary string, extra ...string) string {
+	return "BEGIN:VEVENT\r\nUID:" + uid + "\r\nDTSTAMP:20240101T000000Z\r\nDTSTART:" + start + "\r\n" +
+		"DURATION:PT1H\r\nSUMMARY:" + summary + "\r\n" + strings.Join(extra, "") + "END:VEVENT\r\n"
+}
+
+const (
+	principalPath = "/caldav/user1/"
+	calendarPath  = "/caldav/user1/calendar/"
+
+	propfindCollection = `<?xml version="1.0"?><d:propfind xmlns:d="DAV:" xmlns:cs="http://calendarserver.org/ns/" xmlns:c="urn:ietf:params:xml:ns:caldav">` +
+		`<d:prop><d:resourcetype/><d:displayname/><cs:getctag/><c:supported-calendar-component-set/><d:getetag/></d:prop></d:propfind>`
+)
+
+func TestHandler_Discovery(t *testing.T) {
+	c := newTestClient(t)
+
+	resp := c.do(http.MethodOptions, calendarPath, "", nil)
+	if dav := resp.Header.Get("DAV"); !strings.Contains(dav, "calendar-access") {
+		t.Errorf("Expected calendar-access in DAV header, got %q", dav)
+	}
+
+	// Клиент находит домашнюю коллекцию через принципала
+	ms := c.multistatus("PROPFIND", "/caldav/user1/", "0",
+		`<propfind xmlns="DAV:"><prop><current-user-principal/><calendar-home-set xmlns="urn:ietf:params:xml:ns:caldav"/><getetag/></prop></propfind>`)
+	principal := ms[principalPath]
+	if href, ok := principal.prop("calendar-home-set"); !ok || href != principalPath {
+		t.Fatalf("Unexpected calendar-home-set %q", href)
+	}
+	if len(principal.Propstats) != 2 {
+		t.Errorf("Expected getetag to be reported as missing, got %+v", principal.Propstats)
+	}
+
+	ms = c.multistatus("PROPFIND", principalPath, "1", propfindCollection)
+	calendar, ok := ms[calendarPath]
+	if !ok {
+		t.Fatalf("Expected calendar collection in home, got %v", ms)
+	}
+	if _, ok := calendar.prop("getctag"); !ok {
+		t.Errorf("Expected getctag on the calendar")
+	}
+
+	// Пустое тело PROPFIND равносильно allprop
+	ms = c.multistatus("PROPFIND", calendarPath, "0", "")
+	if _, ok := ms[calendarPath].prop("displayname"); !ok {
+		t.Errorf("Expected displayname in allprop response")
+	}
+
+	if resp := c.do("PROPFIND", "/caldav/user1/other/", "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown collection, got %d", resp.StatusCode)
+	}
+	if resp := c.do("MKCALENDAR", calendarPath, "", nil); resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for MKCALENDAR, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_Sync(t *testing.T) {
+	c := newTestClient(t)
+	ctag := func() string {
+		value, _ := c.multistatus("PROPFIND", calendarPath, "0", propfindCollection)[calendarPath].prop("getctag")
+		return value
+	}
+	initial := ctag()
+
+	standup := calendarPath + "standup@example.com.ics"
+	if code := c.put(standup, vcalendar(
+		vevent("standup@example.com", "20240115T090000Z", "Standup", "RRULE:FREQ=WEEKLY;COUNT=4\r\n"),
+		vevent("standup@example.com", "20240122T100000Z", "Standup (moved)", "RECURRENCE-ID:20240122T090000Z\r\n"),
+	), map[string]string{"If-None-Match": "*"}); code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", code)
+	}
+	dentist := calendarPath + "dentist.ics"
+	if code := c.put(dentist, vcalendar(vevent("dentist", "20240301T120000Z", "Dentist")), nil); code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d", code)
+	}
+	// Объект с существующим UID не создается повторно
+	if code := c.put(dentist, vcalendar(vevent("dentist", "20240301T120000Z", "Dentist")), map[string]string{"If-None-Match": "*"}); code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for If-None-Match on existing object, got %d", code)
+	}
+
+	afterCreate := ctag()
+	if afterCreate == initial {
+		t.Fatalf("ctag should change after PUT")
+	}
+
+	// Depth 1 перечисляет объекты с их ETag; серия и замена - один объект
+	ms := c.multistatus("PROPFIND", calendarPath, "1", propfindCollection)
+	if len(ms) != 3 {
+		t.Fatalf("Expected calendar and 2 objects, got %d responses", len(ms))
+	}
+	etag, ok := ms[standup].prop("getetag")
+	if !ok || etag == "" {
+		t.Fatalf("Expected etag for %s", standup)
+	}
+
+	ms = c.multistatus("REPORT", calendarPath, "1",
+		`<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/><c:calendar-data/></d:prop>`+
+			`<d:href>`+standup+`</d:href><d:href>`+calendarPath+`missing.ics</d:href></c:calendar-multiget>`)
+	data, _ := ms[standup].prop("calendar-data")
+	if strings.Count(data, "BEGIN:VEVENT") != 2 || !strings.Contains(data, "RECURRENCE-ID:20240122T090000Z") {
+		t.Errorf("Expected series with override in calendar-data:\n%s", data)
+	}
+	if strings.Contains(data, "METHOD:") {
+		t.Errorf("Calendar object resource must not contain METHOD")
+	}
+	if missing := ms[calendarPath+"missing.ics"]; !strings.Contains(missing.Status, "404") {
+		t.Errorf("Expected 404 for missing href, got %+v", missing)
+	}
+
+	resp := c.do(http.MethodGet, standup, "", nil)
+	if resp.Header.Get("ETag") != etag {
+		t.Errorf("GET ETag %q differs from PROPFIND %q", resp.Header.Get("ETag"), etag)
+	}
+
+	// Изменение с актуальным ETag применяется, с устаревшим - отклоняется
+	updated := vcalendar(vevent("standup@example.com", "20240115T090000Z", "Weekly standup", "RRULE:FREQ=WEEKLY;COUNT=4\r\n"))
+	if code := c.put(standup, updated, map[string]string{"If-Match": etag}); code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", code)
+	}
+	if code := c.put(standup, updated, map[string]string{"If-Match": etag}); code != http.StatusPreconditionFailed {
+		t.Errorf("Expected 412 for stale If-Match, got %d", code)
+	}
+	data, _ = c.multistatus("REPORT", calendarPath, "1",
+		`<c:calendar-multiget xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><c:calendar-data/></d:prop><d:href>`+standup+`</d:href></c:calendar-multiget>`)[standup].prop("calendar-data")
+	if !strings.Contains(data, "SUMMARY:Weekly standup") || strings.Contains(data, "RECURRENCE-ID") {
+		t.Errorf("Expected updated series without the override:\n%s", data)
+	}
+	if ctag() == afterCreate {
+		t.Errorf("ctag should change after update")
+	}
+
+	resp = c.do(http.MethodDelete, dentist, "", nil)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", resp.StatusCode)
+	}
+	if resp := c.do(http.MethodGet, dentist, "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 after DELETE, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_CalendarQuery(t *testing.T) {
+	c := newTestClient(t)
+	c.put(calendarPath+"weekly.ics", vcalendar(vevent("weekly", "20240101T090000Z", "Weekly", "RRULE:FREQ=WEEKLY;COUNT=3\r\n")), nil)
+	c.put(calendarPath+"later.ics", vcalendar(vevent("later", "20240601T090000Z", "Later")), nil)
+
+	query := func(timeRange string) map[string]response {
+		return c.multistatus("REPORT", calendarPath, "1",
+			`<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><d:getetag/></d:prop>`+
+				`<c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT">`+timeRange+
+				`</c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`)
+	}
+
+	if ms := query(""); len(ms) != 2 {
+		t.Errorf("Expected all objects without time-range, got %d", len(ms))
+	}
+	// Третье вхождение серии попадает в интервал, хотя серия началась раньше
+	ms := query(`<c:time-range start="20240115T000000Z" end="20240116T000000Z"/>`)
+	if _, ok := ms[calendarPath+"weekly.ics"]; !ok || len(ms) != 1 {
+		t.Errorf("Expected only the weekly series, got %v", ms)
+	}
+	if ms := query(`<c:time-range start="20240201T000000Z"/>`); len(ms) != 1 {
+		t.Errorf("Expected open-ended range to match one object, got %d", len(ms))
+	}
+
+	resp := c.do("REPORT", calendarPath,
+		`<c:calendar-query xmlns:c="urn:ietf:params:xml:ns:caldav"><c:filter><c:comp-filter name="VCALENDAR"><c:comp-filter name="VEVENT">`+
+			`<c:prop-filter name="SUMMARY"/></c:comp-filter></c:comp-filter></c:filter></c:calendar-query>`, nil)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for unsupported filter, got %d", resp.StatusCode)
+	}
+}
+
+func TestHandler_PutInvalid(t *testing.T) {
+	c := newTestClient(t)
+	path := calendarPath + "event.ics"
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not a calendar", "hello"},
+		{"uid mismatch", vcalendar(vevent("other", "20240101T090000Z", "Event"))},
+		{"empty summary", vcalendar(vevent("event", "20240101T090000Z", ""))},
+		{"no events", vcalendar()},
+	}
+	for _, tt := range tests {
+		if code := c.put(path, tt.data, nil); code != http.StatusForbidden {
+			t.Errorf("%s: expected 403, got %d", tt.name, code)
+		}
+	}
+	if resp := c.do(http.MethodGet, path, "", nil); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Rejected PUT should not create the object, got %d", resp.StatusCode)
+	}
+
+	// Событие, созданное через API, доступно по своему ID
+	service := service.NewEventService(storage.NewMemoryRepository())
+	event, _ := service.CreateEvent("user1", "Created in service", time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC), nil)
+	server := httptest.NewServer(asUser(NewHandler(service, nopLogger{}), "user1"))
+	defer server.Close()
+	resp, err := http.Get(server.URL + calendarPath + event.ID + ".ics")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || !strings.Contains(string(body), "UID:"+event.ID) {
+		t.Errorf("Expected event by ID, got %d:\n%s", resp.StatusCode, body)
+	}
+}
+
+func TestHandler_OtherUser(t *testing.T) {
+	c := newTestClient(t)
+
+	resp := c.do("PROPFIND", "/caldav/user2/calendar/", propfindCollection, map[string]string{"Depth": "1"})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected 403 for another user's calendar, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,219 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Пространства имен XML, используемые в запросах и ответах
+const (
+	nsDAV          = "DAV:"
+	nsCalDAV       = "urn:ietf:params:xml:ns:caldav"
+	nsCalendarServ = "http://calendarserver.org/ns/"
+)
+
+// prefixes задает префиксы известных пространств имен в ответах
+var prefixes = map[string]string{
+	nsDAV:          "D",
+	nsCalDAV:       "C",
+	nsCalendarServ: "CS",
+}
+
+// Свойства ресурсов
+var (
+	propResourceType       = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName        = xml.Name{Space: nsDAV, Local: "displayname"}
+	propGetETag            = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType     = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propCurrentUser        = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL       = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propPrivilegeSet       = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propSupportedReportSet = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propCalendarHomeSet    = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propCalendarData       = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propSupportedComponent = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propGetCTag            = xml.Name{Space: nsCalendarServ, Local: "getctag"}
+)
+
+// property - значение свойства ресурса: готовый фрагмент XML
+type property struct {
+	name  xml.Name
+	inner string
+}
+
+func textProp(name xml.Name, value string) property {
+	return property{name: name, inner: escape(value)}
+}
+
+func hrefProp(name xml.Name, href string) property {
+	return property{name: name, inner: "<D:href>" + escape(href) + "</D:href>"}
+}
+
+// propfindRequest - тело PROPFIND: запрошенные свойства или allprop
+type propfindRequest struct {
+	XMLName  xml.Name   `xml:"DAV: propfind"`
+	AllProp  *struct{}  `xml:"DAV: allprop"`
+	PropName *struct{}  `xml:"DAV: propname"`
+	Prop     *propNames `xml:"DAV: prop"`
+}
+
+// propNames - список имен свойств из элемента prop
+type propNames struct {
+	Names []xml.Name
+}
+
+// UnmarshalXML собирает имена дочерних элементов, пропуская их содержимое
+func (p *propNames) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			p.Names = append(p.Names, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// reportRequest - тело REPORT calendar-query или calendar-multiget
+type reportRequest struct {
+	XMLName xml.Name
+	Prop    *propNames  `xml:"DAV: prop"`
+	Hrefs   []string    `xml:"DAV: href"`
+	Filter  *compFilter `xml:"urn:ietf:params:xml:ns:caldav filter>comp-filter"`
+}
+
+// compFilter - фильтр компонентов calendar-query (RFC 4791, 9.7.1)
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+	PropFilters  []struct{}   `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+}
+
+// timeRange - интервал фильтра; границы в UTC, любая может отсутствовать
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// bounds возвращает границы интервала; отсутствующие границы не ограничивают выборку
+func (r *timeRange) bounds() (time.Time, time.Time, error) {
+	start := time.Time{}
+	end := time.Date(9999, 1, 1, 0, 0, 0, 0, time.UTC)
+	var err error
+	if r.Start != "" {
+		if start, err = time.Parse("20060102T150405Z", r.Start); err != nil {
+			return start, end, fmt.Errorf("invalid time-range start %q", r.Start)
+		}
+	}
+	if r.End != "" {
+		if end, err = time.Parse("20060102T150405Z", r.End); err != nil {
+			return start, end, fmt.Errorf("invalid time-range end %q", r.End)
+		}
+	}
+	return start, end, nil
+}
+
+// decodeXML разбирает тело запроса; пустое тело оставляет v без изменений
+func decodeXML(r io.Reader, v interface{}) (bool, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return false, nil
+	}
+	return true, xml.Unmarshal(data, v)
+}
+
+// multistatus формирует ответ 207 Multi-Status (RFC 4918, 13)
+type multistatus struct {
+	b bytes.Buffer
+}
+
+func newMultistatus() *multistatus {
+	m := &multistatus{}
+	m.b.WriteString(xml.Header)
+	m.b.WriteString(`<D:multistatus xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav" xmlns:CS="http://calendarserver.org/ns/">`)
+	return m
+}
+
+// propstat добавляет ответ о ресурсе: найденные свойства со статусом 200,
+// отсутствующие - со статусом 404
+func (m *multistatus) propstat(href string, found []property, missing []xml.Name) {
+	m.b.WriteString("<D:response><D:href>" + escape(href) + "</D:href>")
+	if len(found) > 0 {
+		m.b.WriteString("<D:propstat><D:prop>")
+		for _, p := range found {
+			open, closing := elementName(p.name)
+			if p.inner == "" {
+				m.b.WriteString("<" + open + "/>")
+				continue
+			}
+			m.b.WriteString("<" + open + ">" + p.inner + "</" + closing + ">")
+		}
+		m.b.WriteString("</D:prop><D:status>HTTP/1.1 200 OK</D:status></D:propstat>")
+	}
+	if len(missing) > 0 {
+		m.b.WriteString("<D:propstat><D:prop>")
+		for _, name := range missing {
+			open, _ := elementName(name)
+			m.b.WriteString("<" + open + "/>")
+		}
+		m.b.WriteString("</D:prop><D:status>HTTP/1.1 404 Not Found</D:status></D:propstat>")
+	}
+	m.b.WriteString("</D:response>")
+}
+
+// status добавляет ответ о ресурсе без свойств, например 404 для calendar-multiget
+func (m *multistatus) status(href string, code int) {
+	m.b.WriteString("<D:response><D:href>" + escape(href) + "</D:href>")
+	m.b.WriteString(fmt.Sprintf("<D:status>HTTP/1.1 %d %s</D:status>", code, http.StatusText(code)))
+	m.b.WriteString("</D:response>")
+}
+
+func (m *multistatus) send(w http.ResponseWriter) {
+	m.b.WriteString("</D:multistatus>")
+	w.Header().Set("Content-Type", `application/xml; charset=utf-8`)
+	w.WriteHeader(http.StatusMultiStatus)
+	w.Write(m.b.Bytes())
+}
+
+// elementName возвращает имя элемента для открывающего и закрывающего тегов.
+// Свойства неизвестных пространств имен объявляют свое пространство в теге.
+func elementName(name xml.Name) (string, string) {
+	if prefix, ok := prefixes[name.Space]; ok {
+		return prefix + ":" + name.Local, prefix + ":" + name.Local
+	}
+	if name.Space == "" {
+		return name.Local, name.Local
+	}
+	return "X:" + name.Local + ` xmlns:X="` + escape(name.Space) + `"`, "X:" + name.Local
+}
+
+// sendError отправляет ошибку с нарушенным предусловием (RFC 4918, 16)
+func sendError(w http.ResponseWriter, code int, condition xml.Name) {
+	open, _ := elementName(condition)
+	w.Header().Set("Content-Type", `application/xml; charset=utf-8`)
+	w.WriteHeader(code)
+	fmt.Fprintf(w, `%s<D:error xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><%s/></D:error>`, xml.Header, open)
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAccessDenied  = errors.New("access denied")
+	ErrInvalidRole   = errors.New("invalid access role")
+	ErrShareNotFound = errors.New("share not found")
+)
+
+// Role - уровень доступа пользователя к календарю другого пользователя
+type Role string
+
+const (
+	RoleRead   Role = "read"   // просмотр событий
+	RoleWrite  Role = "write"  // просмотр, создание, изменение и удаление событий
+	RoleManage Role = "manage" // запись и управление доступом других пользователей
+)
+
+// roleRank упорядочивает роли: каждая следующая включает права предыдущих
+var roleRank = map[Role]int{
+	RoleRead:   1,
+	RoleWrite:  2,
+	RoleManage: 3,
+}
+
+// ParseRole разбирает название роли
+func ParseRole(s string) (Role, error) {
+	role := Role(s)
+	if _, ok := roleRank[role]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrInvalidRole, s)
+	}
+	return role, nil
+}
+
+// Allows проверяет, что роль включает права роли required
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRank[r]
+	return ok && rank >= roleRank[required]
+}
+
+// Share - запись списка доступа: право пользователя GranteeID
+// на календарь владельца OwnerID
+type Share struct {
+	OwnerID   string
+	GranteeID string
+	Role      Role
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate валидирует запись доступа
+func (s *Share) Validate() error {
+	if s.OwnerID == "" || s.GranteeID == "" || s.OwnerID == s.GranteeID {
+		return ErrInvalidUserID
+	}
+	if _, err := ParseRole(string(s.Role)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ShareRepository определяет интерфейс для хранения списков доступа к календарям
+type ShareRepository interface {
+	// Save создает запись или заменяет роль в существующей
+	Save(share *Share) error
+	Get(ownerID, granteeID string) (*Share, error)
+	Delete(ownerID, granteeID string) error
+	// ListByOwner возвращает пользователей, которым открыт календарь владельца
+	ListByOwner(ownerID string) ([]*Share, error)
+	// ListByGrantee возвращает календари, открытые пользователю
+	ListByGrantee(granteeID string) ([]*Share, error)
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+)
+
+// APIKey - ключ API пользователя. Хранится только хеш секретной части,
+// сам ключ показывается один раз при создании.
+type APIKey struct {
+	ID     string
+	UserID string
+	Name   string
+	// Hash - SHA-256 секретной части ключа в hex
+	Hash      string
+	CreatedAt time.Time
+}
+
+// APIKeyRepository определяет интерфейс для хранения ключей API
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	// Get возвращает ключ по ID независимо от пользователя: так ключ проверяется при входе
+	Get(id string) (*APIKey, error)
+	List(userID string) ([]*APIKey, error)
+	Delete(userID, id string) error
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrInvalidAttendee = errors.New("invalid attendee")
+	ErrInvalidPartStat = errors.New("invalid participation status")
+	ErrNotAttendee     = errors.New("user is not an attendee of the event")
+	ErrInvalidProposal = errors.New("invalid proposed time")
+)
+
+// PartStat - ответ участника на приглашение (PARTSTAT в RFC 5545)
+type PartStat string
+
+const (
+	PartStatNeedsAction PartStat = "needs-action" // участник еще не ответил
+	PartStatAccepted    PartStat = "accepted"
+	PartStatDeclined    PartStat = "declined"
+	PartStatTentative   PartStat = "tentative" // "может быть"
+)
+
+// ParsePartStat разбирает ответ участника
+func ParsePartStat(s string) (PartStat, error) {
+	switch status := PartStat(s); status {
+	case PartStatNeedsAction, PartStatAccepted, PartStatDeclined, PartStatTentative:
+		return status, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidPartStat, s)
+	}
+}
+
+// TimeProposal - время события, предложенное участником вместо текущего.
+// Нулевой End означает прежнюю длительность.
+type TimeProposal struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Attendee - участник события. Владелец события (организатор) в список не входит.
+type Attendee struct {
+	UserID string
+	Status PartStat
+	// Proposal заполнен, если участник предложил другое время
+	Proposal    *TimeProposal
+	RespondedAt *time.Time
+}
+
+// Validate валидирует участника
+func (a *Attendee) Validate() error {
+	if a.UserID == "" {
+		return ErrInvalidAttendee
+	}
+	if _, err := ParsePartStat(string(a.Status)); err != nil {
+		return err
+	}
+	if a.Proposal != nil {
+		if a.Proposal.Start.IsZero() || !a.Proposal.End.IsZero() && !a.Proposal.End.After(a.Proposal.Start) {
+			return ErrInvalidProposal
+		}
+	}
+	return nil
+}
+
+// Attendee возвращает участника события или nil, если пользователь не приглашен
+func (e *Event) Attendee(userID string) *Attendee {
+	for i := range e.Attendees {
+		if e.Attendees[i].UserID == userID {
+			return &e.Attendees[i]
+		}
+	}
+	return nil
+}
+
+// IsInvited проверяет, что пользователь приглашен и не отказался от участия
+func (e *Event) IsInvited(userID string) bool {
+	attendee := e.Attendee(userID)
+	return attendee != nil && attendee.Status != PartStatDeclined
+}
+
+// AttendeeIDs возвращает пользователей, приглашенных на событие
+func (e *Event) AttendeeIDs() []string {
+	ids := make([]string, len(e.Attendees))
+	for i, attendee := range e.Attendees {
+		ids[i] = attendee.UserID
+	}
+	return ids
+}
+
+// ClearProposals удаляет предложения участников о другом времени
+func (e *Event) ClearProposals() {
+	for i := range e.Attendees {
+		e.Attendees[i].Proposal = nil
+	}
+}
+
+// ReminderRecipients возвращает получателей напоминаний события:
+// владельца и участников, принявших приглашение
+func (e *Event) ReminderRecipients() []string {
+	recipients := []string{e.UserID}
+	for _, attendee := range e.Attendees {
+		if attendee.Status == PartStatAccepted {
+			recipients = append(recipients, attendee.UserID)
+		}
+	}
+	return recipients
+}
+
+// validateAttendees проверяет участников события: каждый указан один раз
+// и не совпадает с владельцем
+func (e *Event) validateAttendees() error {
+	seen := make(map[string]bool, len(e.Attendees))
+	for i := range e.Attendees {
+		attendee := &e.Attendees[i]
+		if err := attendee.Validate(); err != nil {
+			return err
+		}
+		if attendee.UserID == e.UserID || seen[attendee.UserID] {
+			return fmt.Errorf("%w: %q", ErrInvalidAttendee, attendee.UserID)
+		}
+		seen[attendee.UserID] = true
+	}
+	return nil
+}
+
+// CloneAttendees возвращает копию участников, не разделяющую с ними указатели
+func CloneAttendees(attendees []Attendee) []Attendee {
+	if attendees == nil {
+		return nil
+	}
+	result := make([]Attendee, len(attendees))
+	for i, attendee := range attendees {
+		if attendee.Proposal != nil {
+			proposal := *attendee.Proposal
+			attendee.Proposal = &proposal
+		}
+		if attendee.RespondedAt != nil {
+			respondedAt := *attendee.RespondedAt
+			attendee.RespondedAt = &respondedAt
+		}
+		result[i] = attendee
+	}
+	return result
+}
+
+// Invitation связывает участника с событием другого пользователя,
+// чтобы приглашения находились без просмотра всех календарей
+type Invitation struct {
+	UserID  string // участник
+	OwnerID string
+	EventID string
+}
+
+// InvitationRepository определяет интерфейс для хранения приглашений
+type InvitationRepository interface {
+	// SetAttendees заменяет приглашения события владельца приглашениями
+	// пользователей userIDs; пустой список удаляет приглашения события
+	SetAttendees(ownerID, eventID string, userIDs []string) error
+	// ListByUser возвращает приглашения участника
+	ListByUser(userID string) ([]*Invitation, error)
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrCalendarNotFound    = errors.New("calendar not found")
+	ErrInvalidCalendarName = errors.New("invalid calendar name")
+	ErrInvalidColor        = errors.New("invalid color: use #RRGGBB")
+)
+
+// DefaultCalendarID обозначает календарь по умолчанию: в нем находятся события
+// без CalendarID. Он есть у каждого пользователя и не хранится в репозитории.
+const DefaultCalendarID = "default"
+
+// maxCalendarNameLength ограничивает длину названия календаря в символах
+const maxCalendarNameLength = 100
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Calendar - именованный календарь пользователя ("Работа", "Личное").
+// Настройки календаря применяются к новым событиям, у которых они не заданы.
+type Calendar struct {
+	ID       string
+	UserID   string
+	Name     string
+	Color    string // #RRGGBB, необязательный
+	TimeZone string // IANA-пояс новых событий; пустой - пояс пользователя
+	// DefaultReminder добавляется к новым событиям без напоминаний;
+	// задается только относительно начала события
+	DefaultReminder *Reminder
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// Validate валидирует настройки календаря
+func (c *Calendar) Validate() error {
+	if c.UserID == "" {
+		return ErrInvalidUserID
+	}
+	if c.Name == "" || utf8.RuneCountInString(c.Name) > maxCalendarNameLength {
+		return ErrInvalidCalendarName
+	}
+	if c.Color != "" && !colorPattern.MatchString(c.Color) {
+		return ErrInvalidColor
+	}
+	if _, err := LoadLocation(c.TimeZone); err != nil {
+		return err
+	}
+	if c.DefaultReminder != nil {
+		if !c.DefaultReminder.IsRelative() {
+			return ErrInvalidReminder
+		}
+		if err := c.DefaultReminder.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Clone возвращает копию календаря, не разделяющую с ним указатели
+func (c *Calendar) Clone() *Calendar {
+	copied := *c
+	if c.DefaultReminder != nil {
+		reminder := *c.DefaultReminder
+		copied.DefaultReminder = &reminder
+	}
+	return &copied
+}
+
+// CalendarRepository определяет интерфейс для хранения календарей пользователей
+type CalendarRepository interface {
+	Create(calendar *Calendar) error
+	Update(calendar *Calendar) error
+	Get(userID, id string) (*Calendar, error)
+	// List возвращает календари пользователя в порядке создания
+	List(userID string) ([]*Calendar, error)
+	Delete(userID, id string) error
+}
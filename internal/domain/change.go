@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// ChangeType - вид изменения календаря пользователя
+type ChangeType string
+
+const (
+	ChangeCreated  ChangeType = "created"
+	ChangeUpdated  ChangeType = "updated"
+	ChangeDeleted  ChangeType = "deleted"
+	ChangeArchived ChangeType = "archived"
+	// ChangeReminder сообщает об отправленном напоминании
+	ChangeReminder ChangeType = "reminder"
+)
+
+// Change описывает изменение события для получателя UserID: владельца события
+// или пользователя, который его видит
+type Change struct {
+	Type    ChangeType
+	UserID  string
+	EventID string
+	// Event - событие после изменения; nil для удаленных событий
+	Event *Event
+	// Reminder - отправленное напоминание для ChangeReminder
+	Reminder *ReminderTask
+	At       time.Time
+}
+
+// ChangeNotifier получает изменения календарей. Notify не должен блокировать
+// вызывающий код: изменения сообщаются внутри операций сервиса.
+type ChangeNotifier interface {
+	Notify(change *Change)
+}
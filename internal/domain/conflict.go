@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrSchedulingConflict  = errors.New("event overlaps other events")
+	ErrInvalidConflictMode = errors.New("invalid conflict mode")
+)
+
+// ConflictMode определяет, как поступать с событием, которое пересекается
+// по времени с другими событиями пользователя
+type ConflictMode string
+
+const (
+	ConflictAllow  ConflictMode = "allow"  // сохранить без проверки
+	ConflictWarn   ConflictMode = "warn"   // сохранить и сообщить о пересечениях
+	ConflictReject ConflictMode = "reject" // не сохранять при пересечениях
+)
+
+// ParseConflictMode разбирает режим проверки пересечений; пустая строка означает режим по умолчанию
+func ParseConflictMode(s string) (ConflictMode, error) {
+	switch mode := ConflictMode(s); mode {
+	case "", ConflictAllow, ConflictWarn, ConflictReject:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidConflictMode, s)
+	}
+}
+
+// ConflictError возвращается, когда событие не сохранено из-за пересечений
+type ConflictError struct {
+	Conflicts []*Event // события и вхождения серий, с которыми пересекается событие
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %d conflicting events", ErrSchedulingConflict, len(e.Conflicts))
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrSchedulingConflict
+}
+
+// OccupiesTime проверяет, что событие занимает время в календаре. События
+// на весь день (дни рождения, праздники) и события без длительности не занимают.
+func (e *Event) OccupiesTime() bool {
+	return !e.AllDay && e.EndTime().After(e.Date)
+}
@@ -2,6 +2,8 @@ package domain
 
 import (
 	"errors"
+	"fmt"
+	"math"
 	"time"
 )
 
@@ -11,18 +13,59 @@ var (
 	ErrInvalidUserID       = errors.New("invalid user id")
 	ErrInvalidEventText    = errors.New("invalid event text")
 	ErrInvalidReminderTime = errors.New("invalid reminder time")
+	ErrInvalidEndTime      = errors.New("end time must be after start time")
+	ErrInvalidScope        = errors.New("invalid edit scope")
+	ErrInvalidRange        = errors.New("invalid range: to must be after from")
+	ErrOccurrenceNotFound  = fmt.Errorf("occurrence %w", ErrEventNotFound)
 )
 
+// EditScope определяет, какие вхождения серии затрагивает изменение
+type EditScope string
+
+const (
+	ScopeThis      EditScope = "this"      // только указанное вхождение
+	ScopeFollowing EditScope = "following" // указанное вхождение и все последующие
+	ScopeAll       EditScope = "all"       // вся серия
+)
+
+// ParseEditScope разбирает область изменения; пустая строка означает область по умолчанию
+func ParseEditScope(s string) (EditScope, error) {
+	switch scope := EditScope(s); scope {
+	case "", ScopeThis, ScopeFollowing, ScopeAll:
+		return scope, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidScope, s)
+	}
+}
+
 // Event представляет событие календаря
 type Event struct {
 	ID           string
 	UserID       string
-	Date         time.Time
+	CalendarID   string    // Календарь владельца; пустой - календарь по умолчанию
+	UID          string    // UID события во внешнем календаре (iCalendar); пустой у созданных в сервисе
+	Date         time.Time // Начало события; для события на весь день — полночь первого дня
+	End          time.Time // Окончание (не включительно); нулевое — без длительности или один день
+	AllDay       bool
+	TimeZone     string // IANA-пояс события; по нему разворачиваются повторения
 	Text         string
 	ReminderTime *time.Time // Опциональное время напоминания
+	Reminders    []Reminder // Дополнительные напоминания, абсолютные или относительно начала
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 	Archived     bool
+
+	// Recurrence задает правило повторения; Date — начало первого вхождения
+	Recurrence *RecurrenceRule
+	// SeriesID и RecurrenceID заполнены у вхождений повторяющегося события:
+	// ID серии и исходное начало вхождения в ней
+	SeriesID     string
+	RecurrenceID *time.Time
+	// ExDates содержит начала исключенных вхождений серии (EXDATE):
+	// отмененных или перенесенных в отдельные события
+	ExDates []time.Time
+	// Attendees - приглашенные пользователи и их ответы
+	Attendees []Attendee
 }
 
 // Validate валидирует данные события
@@ -36,7 +79,162 @@ func (e *Event) Validate() error {
 	if e.Date.IsZero() {
 		return ErrInvalidDate
 	}
-	return nil
+	if !e.End.IsZero() && !e.End.After(e.Date) {
+		return ErrInvalidEndTime
+	}
+	if _, err := LoadLocation(e.TimeZone); err != nil {
+		return err
+	}
+	for _, reminder := range e.Reminders {
+		if err := reminder.Validate(); err != nil {
+			return err
+		}
+	}
+	if e.Recurrence != nil {
+		if err := e.Recurrence.Validate(); err != nil {
+			return err
+		}
+	}
+	return e.validateAttendees()
+}
+
+// IsRecurring проверяет, является ли событие повторяющейся серией
+func (e *Event) IsRecurring() bool {
+	return e.Recurrence != nil
+}
+
+// Location возвращает часовой пояс события; без TimeZone используется пояс Date
+func (e *Event) Location() *time.Location {
+	if e.TimeZone != "" {
+		if loc, err := LoadLocation(e.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return e.Date.Location()
+}
+
+// EndTime возвращает фактическое окончание события: End, конец дня для события
+// на весь день без End или начало для события без длительности
+func (e *Event) EndTime() time.Time {
+	return e.EndFor(e.Date)
+}
+
+// EndFor возвращает окончание события той же длительности, начинающегося в start.
+// Для событий на весь день длительность считается в днях, чтобы переход
+// на летнее время не сдвигал границу дня.
+func (e *Event) EndFor(start time.Time) time.Time {
+	if e.End.IsZero() {
+		if e.AllDay {
+			return start.AddDate(0, 0, 1)
+		}
+		return start
+	}
+	if e.AllDay {
+		days := int(math.Round(e.End.Sub(e.Date).Hours() / 24))
+		return start.AddDate(0, 0, days)
+	}
+	return start.Add(e.End.Sub(e.Date))
+}
+
+// Overlaps проверяет, пересекается ли событие с интервалом [start, end).
+// Событие без длительности попадает в интервал по своему началу.
+func (e *Event) Overlaps(start, end time.Time) bool {
+	eventEnd := e.EndTime()
+	if !eventEnd.After(e.Date) {
+		return !e.Date.Before(start) && e.Date.Before(end)
+	}
+	return e.Date.Before(end) && eventEnd.After(start)
+}
+
+// Occurrences разворачивает серию во вхождения, пересекающиеся с интервалом [from, to).
+// Каждое вхождение — копия серии с датой вхождения, SeriesID и RecurrenceID.
+func (e *Event) Occurrences(from, to time.Time) []*Event {
+	if e.Recurrence == nil {
+		return nil
+	}
+
+	// Вхождения, начавшиеся раньше from, могут еще продолжаться.
+	// Запас в один день покрывает сдвиг длительности при переходе на летнее время.
+	// Повторения считаются по местному времени пояса события, чтобы
+	// вхождения оставались в то же время суток после перехода на летнее время.
+	lookback := e.EndTime().Sub(e.Date) + 24*time.Hour
+	starts := e.Recurrence.Between(e.Date.In(e.Location()), from.Add(-lookback), to)
+	result := make([]*Event, 0, len(starts))
+	for _, start := range starts {
+		if e.IsExcluded(start) {
+			continue
+		}
+		// Вхождение не разделяет срезы с серией: его изменение не затронет серию
+		occurrence := e.Clone()
+		occurrence.Date = start
+		if !e.End.IsZero() {
+			occurrence.End = e.EndFor(start)
+		}
+		if !occurrence.Overlaps(from, to) {
+			continue
+		}
+		occurrence.SeriesID = e.ID
+		recurrenceID := start
+		occurrence.RecurrenceID = &recurrenceID
+		if e.ReminderTime != nil {
+			// Напоминание сдвигается вместе с вхождением
+			reminder := e.ReminderTime.Add(start.Sub(e.Date))
+			occurrence.ReminderTime = &reminder
+		}
+		occurrence.Reminders = ShiftReminders(e.Reminders, start.Sub(e.Date))
+		result = append(result, occurrence)
+	}
+	return result
+}
+
+// IsOverride проверяет, является ли событие измененным вхождением серии (RECURRENCE-ID)
+func (e *Event) IsOverride() bool {
+	return e.SeriesID != "" && e.RecurrenceID != nil
+}
+
+// IsExcluded проверяет, исключено ли вхождение с началом start из серии
+func (e *Event) IsExcluded(start time.Time) bool {
+	for _, exdate := range e.ExDates {
+		if exdate.Equal(start) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasOccurrence проверяет, что серия содержит неисключенное вхождение с началом start
+func (e *Event) HasOccurrence(start time.Time) bool {
+	if e.Recurrence == nil || e.IsExcluded(start) {
+		return false
+	}
+	return len(e.Recurrence.Between(e.Date.In(e.Location()), start, start.Add(time.Nanosecond))) == 1
+}
+
+// EndsBefore проверяет, что событие (или последнее вхождение серии) было раньше t.
+// Бесконечная серия никогда не заканчивается.
+func (e *Event) EndsBefore(t time.Time) bool {
+	if e.Recurrence == nil {
+		return e.EndTime().Before(t)
+	}
+	last, finite := e.Recurrence.Last(e.Date.In(e.Location()))
+	return finite && e.EndFor(last).Before(t)
+}
+
+// ReminderTimes возвращает моменты всех напоминаний события
+func (e *Event) ReminderTimes() []time.Time {
+	var times []time.Time
+	if e.ReminderTime != nil {
+		times = append(times, *e.ReminderTime)
+	}
+	for _, reminder := range e.Reminders {
+		times = append(times, reminder.TimeFor(e.Date))
+	}
+	return times
+}
+
+// HasReminders проверяет, есть ли у события напоминания
+func (e *Event) HasReminders() bool {
+	return e.ReminderTime != nil || len(e.Reminders) > 0
 }
 
 // IsReminderDue проверяет, наступило ли время напоминания
@@ -47,6 +245,37 @@ func (e *Event) IsReminderDue(now time.Time) bool {
 	return !e.ReminderTime.After(now) && !e.Archived
 }
 
+// Clone возвращает глубокую копию события, не разделяющую с ним указатели и срезы
+func (e *Event) Clone() *Event {
+	c := *e
+	if e.ReminderTime != nil {
+		rt := *e.ReminderTime
+		c.ReminderTime = &rt
+	}
+	if e.Reminders != nil {
+		c.Reminders = make([]Reminder, len(e.Reminders))
+		for i, reminder := range e.Reminders {
+			if reminder.At != nil {
+				at := *reminder.At
+				reminder.At = &at
+			}
+			c.Reminders[i] = reminder
+		}
+	}
+	if e.Recurrence != nil {
+		c.Recurrence = e.Recurrence.Clone()
+	}
+	if e.RecurrenceID != nil {
+		recurrenceID := *e.RecurrenceID
+		c.RecurrenceID = &recurrenceID
+	}
+	if e.ExDates != nil {
+		c.ExDates = append([]time.Time(nil), e.ExDates...)
+	}
+	c.Attendees = CloneAttendees(e.Attendees)
+	return &c
+}
+
 // EventRepository определяет интерфейс для хранения событий
 type EventRepository interface {
 	Create(event *Event) error
@@ -55,5 +284,10 @@ type EventRepository interface {
 	GetByID(userID, eventID string) (*Event, error)
 	GetByDateRange(userID string, start, end time.Time) ([]*Event, error)
 	GetAllActive(userID string) ([]*Event, error)
-	ArchiveOldEvents(before time.Time) error
+	GetRecurring(userID string) ([]*Event, error)
+	GetArchived(userID string) ([]*Event, error)
+	GetWithReminders() ([]*Event, error)
+	SearchText(userID, query string) ([]TextMatch, error)
+	// ArchiveOldEvents архивирует события, закончившиеся раньше before, и возвращает их
+	ArchiveOldEvents(before time.Time) ([]*Event, error)
 }
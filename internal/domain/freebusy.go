@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidWorkingHours  = errors.New("invalid working hours")
+	ErrInvalidFreeBusyQuery = errors.New("invalid free/busy query")
+)
+
+// Interval - промежуток времени [Start, End)
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// MergeIntervals объединяет пересекающиеся и смежные интервалы.
+// Результат упорядочен по началу.
+func MergeIntervals(intervals []Interval) []Interval {
+	if len(intervals) == 0 {
+		return nil
+	}
+	sorted := append([]Interval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.Before(sorted[j].Start)
+	})
+
+	result := []Interval{sorted[0]}
+	for _, interval := range sorted[1:] {
+		last := &result[len(result)-1]
+		if interval.Start.After(last.End) {
+			result = append(result, interval)
+			continue
+		}
+		if interval.End.After(last.End) {
+			last.End = interval.End
+		}
+	}
+	return result
+}
+
+// Gaps возвращает промежутки [from, to), не покрытые интервалами
+// упорядоченного списка без пересечений (см. MergeIntervals)
+func Gaps(merged []Interval, from, to time.Time) []Interval {
+	var result []Interval
+	cursor := from
+	for _, interval := range merged {
+		if !interval.End.After(cursor) {
+			continue
+		}
+		if !interval.Start.Before(to) {
+			break
+		}
+		if interval.Start.After(cursor) {
+			result = append(result, Interval{Start: cursor, End: interval.Start})
+		}
+		cursor = interval.End
+	}
+	if cursor.Before(to) {
+		result = append(result, Interval{Start: cursor, End: to})
+	}
+	return result
+}
+
+// WorkingHours - рабочее время пользователя по местному времени его пояса
+type WorkingHours struct {
+	Start int // минуты от полуночи
+	End   int // минуты от полуночи, не раньше Start; 24:00 - конец дня
+	Days  []time.Weekday
+}
+
+// DefaultWorkingHours возвращает рабочее время пользователей, которые его не задали:
+// с 9:00 до 18:00 с понедельника по пятницу
+func DefaultWorkingHours() WorkingHours {
+	return WorkingHours{
+		Start: 9 * 60,
+		End:   18 * 60,
+		Days:  []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	}
+}
+
+// Validate валидирует рабочее время
+func (h *WorkingHours) Validate() error {
+	if h.Start < 0 || h.End > 24*60 || h.End <= h.Start {
+		return fmt.Errorf("%w: end must be after start", ErrInvalidWorkingHours)
+	}
+	if len(h.Days) == 0 {
+		return fmt.Errorf("%w: no working days", ErrInvalidWorkingHours)
+	}
+	for _, day := range h.Days {
+		if day < time.Sunday || day > time.Saturday {
+			return fmt.Errorf("%w: unknown weekday %d", ErrInvalidWorkingHours, day)
+		}
+	}
+	return nil
+}
+
+// Windows возвращает рабочие интервалы по местному времени loc, пересекающиеся
+// с [from, to), обрезанные по его границам. Время суток считается по местным
+// часам, поэтому переход на летнее время не сдвигает начало рабочего дня.
+func (h *WorkingHours) Windows(loc *time.Location, from, to time.Time) []Interval {
+	var result []Interval
+	local := from.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, loc)
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !h.isWorkday(day.Weekday()) {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), 0, h.Start, 0, 0, loc)
+		end := time.Date(day.Year(), day.Month(), day.Day(), 0, h.End, 0, 0, loc)
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			result = append(result, Interval{Start: start, End: end})
+		}
+	}
+	return result
+}
+
+func (h *WorkingHours) isWorkday(day time.Weekday) bool {
+	for _, d := range h.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClock разбирает время суток HH:MM в минуты от полуночи; 24:00 означает конец дня
+func ParseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err == nil {
+		return t.Hour()*60 + t.Minute(), nil
+	}
+	if s == "24:00" {
+		return 24 * 60, nil
+	}
+	return 0, fmt.Errorf("%w: time %q", ErrInvalidWorkingHours, s)
+}
+
+// FormatClock форматирует минуты от полуночи как HH:MM
+func FormatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// ParseWeekday разбирает день недели в формате BYDAY (MO, TU, ...) без учета регистра
+func ParseWeekday(s string) (time.Weekday, error) {
+	day, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(s))]
+	if !ok {
+		return 0, fmt.Errorf("%w: weekday %q", ErrInvalidWorkingHours, s)
+	}
+	return day, nil
+}
+
+// WeekdayCode возвращает код дня недели в формате BYDAY
+func WeekdayCode(day time.Weekday) string {
+	return strings.ToUpper(day.String()[:2])
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeIntervalsAndGaps(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, 1, 15, hour, 0, 0, 0, time.UTC) }
+	merged := MergeIntervals([]Interval{
+		{Start: at(13), End: at(14)},
+		{Start: at(9), End: at(11)},
+		{Start: at(10), End: at(12)},
+		{Start: at(12), End: at(13)},
+		{Start: at(16), End: at(17)},
+	})
+	if len(merged) != 2 || !merged[0].Start.Equal(at(9)) || !merged[0].End.Equal(at(14)) || !merged[1].Start.Equal(at(16)) {
+		t.Fatalf("Unexpected merged intervals %+v", merged)
+	}
+
+	gaps := Gaps(merged, at(8), at(18))
+	want := []Interval{{Start: at(8), End: at(9)}, {Start: at(14), End: at(16)}, {Start: at(17), End: at(18)}}
+	if len(gaps) != len(want) {
+		t.Fatalf("Expected %d gaps, got %+v", len(want), gaps)
+	}
+	for i := range want {
+		if !gaps[i].Start.Equal(want[i].Start) || !gaps[i].End.Equal(want[i].End) {
+			t.Errorf("Gap %d: expected %+v, got %+v", i, want[i], gaps[i])
+		}
+	}
+	if gaps := Gaps(merged, at(10), at(12)); len(gaps) != 0 {
+		t.Errorf("Expected no gaps inside busy interval, got %+v", gaps)
+	}
+}
+
+func TestWorkingHours_Windows(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	hours := DefaultWorkingHours()
+
+	// Пятница перед переходом на летнее время и понедельник после него
+	from := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 2, 0, 0, 0, 0, time.UTC)
+	windows := hours.Windows(loc, from, to)
+	if len(windows) != 2 {
+		t.Fatalf("Expected windows on Friday and Monday, got %+v", windows)
+	}
+	if !windows[0].Start.Equal(time.Date(2024, 3, 29, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected Friday to start at 09:00 CET, got %v", windows[0].Start)
+	}
+	if !windows[1].Start.Equal(time.Date(2024, 4, 1, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected Monday to start at 09:00 CEST, got %v", windows[1].Start)
+	}
+
+	invalid := WorkingHours{Start: 18 * 60, End: 9 * 60, Days: hours.Days}
+	if err := invalid.Validate(); err == nil {
+		t.Error("Expected error for end before start")
+	}
+	if _, err := ParseClock("25:00"); err == nil {
+		t.Error("Expected error for invalid clock")
+	}
+	if end, _ := ParseClock("24:00"); end != 24*60 {
+		t.Errorf("Expected 24:00 to be the end of day, got %d", end)
+	}
+}
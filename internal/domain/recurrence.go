@@ -0,0 +1,454 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence rule")
+
+// Frequency представляет частоту повторения (FREQ в RFC 5545)
+type Frequency string
+
+const (
+	FreqDaily   Frequency = "DAILY"
+	FreqWeekly  Frequency = "WEEKLY"
+	FreqMonthly Frequency = "MONTHLY"
+	FreqYearly  Frequency = "YEARLY"
+)
+
+// maxExpandPeriods ограничивает разворачивание бесконечных правил
+const maxExpandPeriods = 100000
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum представляет значение BYDAY: день недели с необязательным
+// порядковым номером (1MO — первый понедельник, -1FR — последняя пятница)
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// RecurrenceRule представляет правило повторения события (RRULE)
+type RecurrenceRule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	Count      int
+	Until      *time.Time
+}
+
+// ParseRecurrenceRule разбирает строку RRULE, например "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10"
+func ParseRecurrenceRule(s string) (*RecurrenceRule, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "RRULE:"), "rrule:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRecurrence)
+	}
+
+	rule := &RecurrenceRule{Interval: 1}
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: INTERVAL %q", ErrInvalidRecurrence, value)
+			}
+			rule.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: COUNT %q", ErrInvalidRecurrence, value)
+			}
+			rule.Count = n
+		case "UNTIL":
+			until, err := parseRRuleTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL %q", ErrInvalidRecurrence, value)
+			}
+			rule.Until = &until
+		case "BYDAY":
+			for _, v := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(v)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, v := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					return nil, fmt.Errorf("%w: BYMONTHDAY %q", ErrInvalidRecurrence, v)
+				}
+				rule.ByMonthDay = append(rule.ByMonthDay, n)
+			}
+		case "WKST":
+			// Поддерживается только неделя, начинающаяся с понедельника
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %s", ErrInvalidRecurrence, name)
+		}
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// Validate проверяет корректность правила
+func (r *RecurrenceRule) Validate() error {
+	switch r.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+	default:
+		return fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, r.Freq)
+	}
+	if r.Interval < 1 {
+		return fmt.Errorf("%w: INTERVAL must be positive", ErrInvalidRecurrence)
+	}
+	if r.Count < 0 {
+		return fmt.Errorf("%w: COUNT must be positive", ErrInvalidRecurrence)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRecurrence)
+	}
+	for _, d := range r.ByMonthDay {
+		if d == 0 || d < -31 || d > 31 {
+			return fmt.Errorf("%w: BYMONTHDAY %d", ErrInvalidRecurrence, d)
+		}
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != FreqMonthly && r.Freq != FreqYearly {
+			return fmt.Errorf("%w: BYDAY ordinals require MONTHLY or YEARLY", ErrInvalidRecurrence)
+		}
+		if wd.N < -53 || wd.N > 53 {
+			return fmt.Errorf("%w: BYDAY ordinal %d", ErrInvalidRecurrence, wd.N)
+		}
+	}
+	return nil
+}
+
+// Clone возвращает независимую копию правила
+func (r *RecurrenceRule) Clone() *RecurrenceRule {
+	c := *r
+	c.ByDay = append([]WeekdayNum(nil), r.ByDay...)
+	c.ByMonthDay = append([]int(nil), r.ByMonthDay...)
+	if r.Until != nil {
+		until := *r.Until
+		c.Until = &until
+	}
+	return &c
+}
+
+// String возвращает правило в формате RRULE без префикса
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, d := range r.ByMonthDay {
+			days[i] = strconv.Itoa(d)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// String возвращает значение BYDAY, например "MO" или "-1FR"
+func (wd WeekdayNum) String() string {
+	code := strings.ToUpper(wd.Weekday.String()[:2])
+	if wd.N == 0 {
+		return code
+	}
+	return strconv.Itoa(wd.N) + code
+}
+
+// Between возвращает начала вхождений серии, начавшейся в dtstart,
+// попадающие в интервал [from, to)
+func (r *RecurrenceRule) Between(dtstart, from, to time.Time) []time.Time {
+	var result []time.Time
+	r.iterate(dtstart, to, func(t time.Time) bool {
+		if !t.Before(to) {
+			return false
+		}
+		if !t.Before(from) {
+			result = append(result, t)
+		}
+		return true
+	})
+	return result
+}
+
+// Last возвращает начало последнего вхождения серии.
+// Для бесконечных правил (без COUNT и UNTIL) возвращает false.
+func (r *RecurrenceRule) Last(dtstart time.Time) (time.Time, bool) {
+	if r.Count == 0 && r.Until == nil {
+		return time.Time{}, false
+	}
+	var limit time.Time
+	if r.Until != nil {
+		limit = r.Until.Add(time.Nanosecond)
+	}
+	last := dtstart
+	r.iterate(dtstart, limit, func(t time.Time) bool {
+		last = t
+		return true
+	})
+	return last, true
+}
+
+// iterate перебирает вхождения по порядку, пока fn возвращает true,
+// пока не исчерпаны COUNT/UNTIL или пока периоды не начинаются после limit
+// (нулевой limit означает отсутствие ограничения)
+func (r *RecurrenceRule) iterate(dtstart, limit time.Time, fn func(time.Time) bool) {
+	emitted := 0
+	for period := 0; period < maxExpandPeriods; period++ {
+		if !limit.IsZero() && !r.periodStart(dtstart, period).Before(limit) {
+			return
+		}
+		candidates := r.periodCandidates(dtstart, period)
+		for _, t := range candidates {
+			if t.Before(dtstart) {
+				continue
+			}
+			if r.Until != nil && t.After(*r.Until) {
+				return
+			}
+			if !fn(t) {
+				return
+			}
+			emitted++
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+		}
+	}
+}
+
+// periodStart возвращает момент, раньше которого в n-м периоде нет вхождений
+func (r *RecurrenceRule) periodStart(dtstart time.Time, n int) time.Time {
+	step := n * r.Interval
+	loc := dtstart.Location()
+	switch r.Freq {
+	case FreqDaily:
+		day := dtstart.AddDate(0, 0, step)
+		return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, loc)
+	case FreqWeekly:
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := dtstart.AddDate(0, 0, step*7-offset)
+		return time.Date(monday.Year(), monday.Month(), monday.Day(), 0, 0, 0, 0, loc)
+	case FreqMonthly:
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(dtstart.Year()+step, time.January, 1, 0, 0, 0, 0, loc)
+	}
+}
+
+// periodCandidates возвращает отсортированные кандидаты n-го периода правила
+func (r *RecurrenceRule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	step := n * r.Interval
+	loc := dtstart.Location()
+	hour, min, sec := dtstart.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, dtstart.Nanosecond(), loc)
+	}
+
+	var days []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		day := dtstart.AddDate(0, 0, step)
+		day = at(day.Year(), day.Month(), day.Day())
+		if r.matchesWeekday(day) && r.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+
+	case FreqWeekly:
+		// Неделя начинается с понедельника (WKST=MO)
+		offset := (int(dtstart.Weekday()) + 6) % 7
+		monday := dtstart.AddDate(0, 0, step*7-offset)
+		for i := 0; i < 7; i++ {
+			day := at(monday.Year(), monday.Month(), monday.Day()+i)
+			if len(r.ByDay) == 0 {
+				if day.Weekday() == dtstart.Weekday() {
+					days = append(days, day)
+				}
+				continue
+			}
+			if r.matchesWeekday(day) {
+				days = append(days, day)
+			}
+		}
+
+	case FreqMonthly:
+		first := time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, loc)
+		days = r.expandInRange(first, first.AddDate(0, 1, 0), dtstart.Day(), at)
+
+	case FreqYearly:
+		year := dtstart.Year() + step
+		if len(r.ByDay) == 0 {
+			// Без BYDAY повторение идет в месяце начала серии
+			first := time.Date(year, dtstart.Month(), 1, 0, 0, 0, 0, loc)
+			days = r.expandInRange(first, first.AddDate(0, 1, 0), dtstart.Day(), at)
+		} else {
+			first := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+			days = r.expandInRange(first, first.AddDate(1, 0, 0), dtstart.Day(), at)
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// expandInRange разворачивает BYDAY/BYMONTHDAY внутри месяца или года [first, next).
+// Без обоих правил используется день месяца начала серии; несуществующие даты
+// (например, 31 февраля) пропускаются, как требует RFC 5545.
+func (r *RecurrenceRule) expandInRange(first, next time.Time, startDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	var days []time.Time
+	inRange := func(y int, m time.Month, d int) bool {
+		t := time.Date(y, m, d, 0, 0, 0, 0, first.Location())
+		return t.Month() == m && !t.Before(first) && t.Before(next)
+	}
+
+	if len(r.ByDay) == 0 {
+		monthDays := r.ByMonthDay
+		if len(monthDays) == 0 {
+			monthDays = []int{startDay}
+		}
+		lastDay := time.Date(first.Year(), first.Month()+1, 0, 0, 0, 0, 0, first.Location()).Day()
+		for _, d := range monthDays {
+			if d < 0 {
+				d = lastDay + d + 1
+			}
+			if d >= 1 && inRange(first.Year(), first.Month(), d) {
+				days = append(days, at(first.Year(), first.Month(), d))
+			}
+		}
+		return days
+	}
+
+	for _, wd := range r.ByDay {
+		var matches []time.Time
+		for d := first; d.Before(next); d = d.AddDate(0, 0, 1) {
+			if d.Weekday() == wd.Weekday {
+				matches = append(matches, d)
+			}
+		}
+
+		selected := matches
+		if wd.N > 0 {
+			selected = nil
+			if wd.N <= len(matches) {
+				selected = []time.Time{matches[wd.N-1]}
+			}
+		} else if wd.N < 0 {
+			selected = nil
+			if -wd.N <= len(matches) {
+				selected = []time.Time{matches[len(matches)+wd.N]}
+			}
+		}
+
+		for _, d := range selected {
+			day := at(d.Year(), d.Month(), d.Day())
+			if r.matchesMonthDay(day) {
+				days = append(days, day)
+			}
+		}
+	}
+	return days
+}
+
+func (r *RecurrenceRule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, d := range r.ByMonthDay {
+		if d == t.Day() || (d < 0 && lastDay+d+1 == t.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekdayNum(s string) (WeekdayNum, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if len(s) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrence, s)
+	}
+	weekday, ok := weekdayCodes[s[len(s)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrence, s)
+	}
+	wd := WeekdayNum{Weekday: weekday}
+	if prefix := s[:len(s)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 {
+			return WeekdayNum{}, fmt.Errorf("%w: BYDAY %q", ErrInvalidRecurrence, s)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+func parseRRuleTime(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "20060102" {
+				// UNTIL в виде даты включает весь указанный день
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
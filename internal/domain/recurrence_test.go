@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	rule, err := ParseRecurrenceRule("RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR;UNTIL=20241231T000000Z")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if rule.Freq != FreqMonthly || rule.Interval != 2 {
+		t.Errorf("Unexpected rule %+v", rule)
+	}
+	if len(rule.ByDay) != 1 || rule.ByDay[0].Weekday != time.Friday || rule.ByDay[0].N != -1 {
+		t.Errorf("Unexpected BYDAY %+v", rule.ByDay)
+	}
+
+	want := "FREQ=MONTHLY;INTERVAL=2;BYDAY=-1FR;UNTIL=20241231T000000Z"
+	if rule.String() != want {
+		t.Errorf("Expected %s, got %s", want, rule.String())
+	}
+}
+
+func TestParseRecurrenceRule_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20240101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;BYSETPOS=1",
+	}
+
+	for _, s := range tests {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseRecurrenceRule(s)
+			if !errors.Is(err, ErrInvalidRecurrence) {
+				t.Errorf("Expected error %v, got %v", ErrInvalidRecurrence, err)
+			}
+		})
+	}
+}
+
+func TestRecurrenceRule_Between(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		dtstart time.Time
+		from    time.Time
+		to      time.Time
+		want    []string
+	}{
+		{
+			name:    "monthly on 31st skips short months",
+			rule:    "FREQ=MONTHLY;COUNT=3",
+			dtstart: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+			from:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2024-01-31", "2024-03-31", "2024-05-31"},
+		},
+		{
+			name:    "last friday of month",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR",
+			dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2024-01-26", "2024-02-23", "2024-03-29"},
+		},
+		{
+			name:    "monthly by negative month day",
+			rule:    "FREQ=MONTHLY;BYMONTHDAY=1,-1",
+			dtstart: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2024-02-01", "2024-02-29", "2024-03-01"},
+		},
+		{
+			name:    "biweekly with until",
+			rule:    "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH;UNTIL=20240125",
+			dtstart: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2024-01-02", "2024-01-04", "2024-01-16", "2024-01-18"},
+		},
+		{
+			name:    "yearly on leap day",
+			rule:    "FREQ=YEARLY",
+			dtstart: time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+			from:    time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			to:      time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC),
+			want:    []string{"2024-02-29", "2028-02-29"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRecurrenceRule(tt.rule)
+			if err != nil {
+				t.Fatalf("Failed to parse rule: %v", err)
+			}
+
+			got := rule.Between(tt.dtstart, tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i, d := range got {
+				if d.Format("2006-01-02") != tt.want[i] {
+					t.Errorf("Expected %s at %d, got %s", tt.want[i], i, d.Format("2006-01-02"))
+				}
+			}
+		})
+	}
+}
+
+func TestEvent_Occurrences_DoNotShareSeriesSlices(t *testing.T) {
+	rule, err := ParseRecurrenceRule("FREQ=DAILY")
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+	series := &Event{
+		ID:         "s1",
+		Date:       time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		Recurrence: rule,
+		ExDates:    []time.Time{time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)},
+		Attendees:  []Attendee{{UserID: "user2", Status: PartStatNeedsAction}},
+	}
+
+	occurrences := series.Occurrences(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC))
+	if len(occurrences) != 2 {
+		t.Fatalf("Expected 2 occurrences, got %d", len(occurrences))
+	}
+	occurrences[0].Attendees[0].Status = PartStatAccepted
+	occurrences[0].ExDates[0] = occurrences[0].Date
+
+	if series.Attendees[0].Status != PartStatNeedsAction {
+		t.Errorf("Expected series attendee to stay %s, got %s", PartStatNeedsAction, series.Attendees[0].Status)
+	}
+	if occurrences[1].Attendees[0].Status != PartStatNeedsAction {
+		t.Errorf("Expected other occurrence to stay %s, got %s", PartStatNeedsAction, occurrences[1].Attendees[0].Status)
+	}
+	if !series.ExDates[0].Equal(time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected series exdates to stay unchanged, got %v", series.ExDates)
+	}
+}
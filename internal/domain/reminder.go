@@ -1,16 +1,124 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidReminder    = errors.New("invalid reminder")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+)
+
+// Reminder описывает напоминание о событии: в абсолютный момент At
+// или, если At не задан, за Offset до начала события
+type Reminder struct {
+	At     *time.Time
+	Offset time.Duration
+}
+
+// Validate валидирует напоминание
+func (r Reminder) Validate() error {
+	if r.At == nil && r.Offset < 0 {
+		return ErrInvalidReminder
+	}
+	return nil
+}
+
+// IsRelative проверяет, задано ли напоминание относительно начала события
+func (r Reminder) IsRelative() bool {
+	return r.At == nil
+}
+
+// TimeFor возвращает момент напоминания для события, начинающегося в start
+func (r Reminder) TimeFor(start time.Time) time.Time {
+	if r.At != nil {
+		return *r.At
+	}
+	return start.Add(-r.Offset)
+}
+
+// ShiftReminders возвращает копию напоминаний со сдвинутыми абсолютными моментами.
+// Относительные напоминания следуют за началом события сами.
+func ShiftReminders(reminders []Reminder, shift time.Duration) []Reminder {
+	if reminders == nil {
+		return nil
+	}
+	result := make([]Reminder, len(reminders))
+	for i, reminder := range reminders {
+		result[i] = reminder
+		if reminder.At != nil {
+			at := reminder.At.Add(shift)
+			result[i].At = &at
+		}
+	}
+	return result
+}
 
 // ReminderTask представляет задачу напоминания для обработки
 type ReminderTask struct {
 	EventID string
-	UserID  string
+	UserID  string // получатель напоминания
+	// OwnerID - владелец события, если напоминание получает участник; пустой у напоминаний владельца
+	OwnerID string
 	Text    string
 	Time    time.Time
+	Start   time.Time // Начало события или вхождения серии
+	Index   int       // Номер напоминания среди напоминаний события
+	Attempt int       // Номер попытки доставки, начиная с 0
+	// Channels - каналы, в которые осталось доставить напоминание после неудачной
+	// попытки; пустой список означает все каналы получателя
+	Channels []ReminderChannel
+}
+
+// EventOwner возвращает владельца события, по которому найдено напоминание
+func (t *ReminderTask) EventOwner() string {
+	if t.OwnerID != "" {
+		return t.OwnerID
+	}
+	return t.UserID
 }
 
 // ReminderSender определяет интерфейс для отправки напоминаний
 type ReminderSender interface {
 	SendReminder(task *ReminderTask) error
 }
+
+// ChannelDeliveryError сообщает, в какие каналы не удалось доставить напоминание.
+// Остальные каналы напоминание получили, и повторять доставку в них не нужно.
+type ChannelDeliveryError struct {
+	Failed []ReminderChannel
+	Err    error
+}
+
+func (e *ChannelDeliveryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ChannelDeliveryError) Unwrap() error {
+	return e.Err
+}
+
+// ReminderScheduler определяет интерфейс для планирования напоминаний
+type ReminderScheduler interface {
+	Schedule(task *ReminderTask)
+	// Cancel отменяет все запланированные напоминания события
+	Cancel(eventID string)
+}
+
+// DeadLetter представляет напоминание, которое не удалось доставить за все попытки
+type DeadLetter struct {
+	ID        string
+	Task      ReminderTask
+	Attempts  int
+	LastError string
+	FailedAt  time.Time
+}
+
+// DeadLetterRepository определяет интерфейс для хранения недоставленных напоминаний
+type DeadLetterRepository interface {
+	Add(letter *DeadLetter) error
+	List() ([]*DeadLetter, error)
+	Get(id string) (*DeadLetter, error)
+	Delete(id string) error
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrInvalidArchiveFilter = errors.New("invalid archive filter")
+	ErrInvalidSort          = errors.New("invalid sort order")
+	ErrInvalidCursor        = errors.New("invalid cursor")
+	ErrInvalidQuery         = errors.New("search query must contain at least one word")
+)
+
+// ArchiveFilter определяет, какие события попадают в выборку по признаку архивации
+type ArchiveFilter string
+
+const (
+	ArchiveActive   ArchiveFilter = "active"   // только активные события
+	ArchiveArchived ArchiveFilter = "archived" // только архивные события
+	ArchiveAll      ArchiveFilter = "all"      // все события
+)
+
+// ParseArchiveFilter разбирает фильтр архивации; пустая строка означает активные события
+func ParseArchiveFilter(s string) (ArchiveFilter, error) {
+	switch filter := ArchiveFilter(s); filter {
+	case "":
+		return ArchiveActive, nil
+	case ArchiveActive, ArchiveArchived, ArchiveAll:
+		return filter, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidArchiveFilter, s)
+	}
+}
+
+// IncludesActive проверяет, попадают ли в выборку активные события
+func (f ArchiveFilter) IncludesActive() bool {
+	return f != ArchiveArchived
+}
+
+// IncludesArchived проверяет, попадают ли в выборку архивные события
+func (f ArchiveFilter) IncludesArchived() bool {
+	return f == ArchiveArchived || f == ArchiveAll
+}
+
+// SortField задает поле сортировки результатов поиска
+type SortField string
+
+const (
+	SortByStart   SortField = "start"
+	SortByCreated SortField = "created"
+	SortByUpdated SortField = "updated"
+)
+
+// SortOrder задает поле и направление сортировки
+type SortOrder struct {
+	Field SortField
+	Desc  bool
+}
+
+// ParseSortOrder разбирает порядок сортировки вида "start" или "-start" (по убыванию).
+// Пустая строка означает сортировку по началу по возрастанию.
+func ParseSortOrder(s string) (SortOrder, error) {
+	order := SortOrder{Field: SortByStart}
+	if s == "" {
+		return order, nil
+	}
+	if s[0] == '-' {
+		order.Desc = true
+		s = s[1:]
+	}
+	switch field := SortField(s); field {
+	case SortByStart, SortByCreated, SortByUpdated:
+		order.Field = field
+		return order, nil
+	default:
+		return SortOrder{}, fmt.Errorf("%w: %q", ErrInvalidSort, s)
+	}
+}
+
+// String возвращает порядок сортировки в формате ParseSortOrder
+func (o SortOrder) String() string {
+	if o.Desc {
+		return "-" + string(o.Field)
+	}
+	return string(o.Field)
+}
+
+// TextMatch - событие, найденное полнотекстовым поиском, и его релевантность
+type TextMatch struct {
+	Event *Event
+	Score float64
+}
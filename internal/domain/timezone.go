@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// locations кэширует загруженные часовые пояса: time.LoadLocation
+// читает базу tzdata при каждом вызове
+var locations sync.Map
+
+// LoadLocation загружает часовой пояс IANA по имени; пустое имя означает UTC
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimeZone, name)
+	}
+	locations.Store(name, loc)
+	return loc, nil
+}
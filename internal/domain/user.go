@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidChannel    = errors.New("invalid reminder channel")
+	ErrInvalidEmail      = errors.New("invalid email")
+	ErrInvalidWebhookURL = errors.New("invalid webhook url")
+	ErrInvalidFeedToken  = errors.New("invalid feed token")
+)
+
+// ReminderChannel - канал доставки напоминаний
+type ReminderChannel string
+
+const (
+	ChannelConsole ReminderChannel = "console"
+	ChannelEmail   ReminderChannel = "email"
+	ChannelWebhook ReminderChannel = "webhook"
+)
+
+// ParseReminderChannel разбирает название канала доставки
+func ParseReminderChannel(s string) (ReminderChannel, error) {
+	switch channel := ReminderChannel(s); channel {
+	case ChannelConsole, ChannelEmail, ChannelWebhook:
+		return channel, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrInvalidChannel, s)
+	}
+}
+
+// User представляет настройки пользователя
+type User struct {
+	ID         string
+	TimeZone   string // IANA, например "Europe/Moscow"; пустое значение — UTC
+	Email      string
+	WebhookURL string
+	// Channels задает каналы доставки напоминаний; пустой список означает
+	// все включенные на сервере каналы, для которых у пользователя есть адрес
+	Channels []ReminderChannel
+	// FeedToken - секрет в адресе подписки на календарь (.ics); пустой - подписка выключена
+	FeedToken string
+	// WorkingHours - рабочее время для подбора встреч; nil - DefaultWorkingHours
+	WorkingHours *WorkingHours
+}
+
+// Validate валидирует настройки пользователя
+func (u *User) Validate() error {
+	if u.ID == "" {
+		return ErrInvalidUserID
+	}
+	if _, err := LoadLocation(u.TimeZone); err != nil {
+		return err
+	}
+	if u.Email != "" {
+		if _, err := mail.ParseAddress(u.Email); err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidEmail, u.Email)
+		}
+	}
+	if u.WebhookURL != "" {
+		parsed, err := url.Parse(u.WebhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidWebhookURL, u.WebhookURL)
+		}
+	}
+	if u.WorkingHours != nil {
+		if err := u.WorkingHours.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, channel := range u.Channels {
+		if _, err := ParseReminderChannel(string(channel)); err != nil {
+			return err
+		}
+		if !u.CanReceive(channel) {
+			return fmt.Errorf("%w: %s requires an address", ErrInvalidChannel, channel)
+		}
+	}
+	return nil
+}
+
+// CanReceive проверяет, есть ли у пользователя адрес для канала
+func (u *User) CanReceive(channel ReminderChannel) bool {
+	switch channel {
+	case ChannelEmail:
+		return u.Email != ""
+	case ChannelWebhook:
+		return u.WebhookURL != ""
+	default:
+		return true
+	}
+}
+
+// Clone возвращает копию настроек пользователя
+func (u *User) Clone() *User {
+	copied := *u
+	copied.Channels = append([]ReminderChannel(nil), u.Channels...)
+	if u.WorkingHours != nil {
+		hours := *u.WorkingHours
+		hours.Days = append([]time.Weekday(nil), u.WorkingHours.Days...)
+		copied.WorkingHours = &hours
+	}
+	return &copied
+}
+
+// Location возвращает часовой пояс пользователя
+func (u *User) Location() *time.Location {
+	loc, err := LoadLocation(u.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Hours возвращает рабочее время пользователя или рабочее время по умолчанию
+func (u *User) Hours() WorkingHours {
+	if u.WorkingHours == nil {
+		return DefaultWorkingHours()
+	}
+	return *u.WorkingHours
+}
+
+// UserRepository определяет интерфейс для хранения настроек пользователей
+type UserRepository interface {
+	Get(userID string) (*User, error)
+	Save(user *User) error
+}
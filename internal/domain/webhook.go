@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound     = errors.New("webhook subscription not found")
+	ErrInvalidWebhookEvent = errors.New("invalid webhook event type")
+)
+
+// WebhookEventTypes - изменения, о которых можно подписаться получать webhook
+var WebhookEventTypes = []ChangeType{ChangeCreated, ChangeUpdated, ChangeDeleted, ChangeArchived}
+
+// WebhookSubscription описывает подписку внешней системы на изменения событий пользователя
+type WebhookSubscription struct {
+	ID     string
+	UserID string
+	URL    string
+	// Secret - ключ подписи запросов HMAC-SHA256
+	Secret string
+	// Types - типы изменений; пустой список означает все типы из WebhookEventTypes
+	Types     []ChangeType
+	CreatedAt time.Time
+}
+
+// Validate валидирует подписку
+func (s *WebhookSubscription) Validate() error {
+	if s.UserID == "" {
+		return ErrInvalidUserID
+	}
+	parsed, err := url.Parse(s.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidWebhookURL, s.URL)
+	}
+	for _, t := range s.Types {
+		if !isWebhookEventType(t) {
+			return fmt.Errorf("%w: %q", ErrInvalidWebhookEvent, t)
+		}
+	}
+	return nil
+}
+
+// Accepts проверяет, подписана ли подписка на изменения типа t
+func (s *WebhookSubscription) Accepts(t ChangeType) bool {
+	if len(s.Types) == 0 {
+		return isWebhookEventType(t)
+	}
+	for _, accepted := range s.Types {
+		if accepted == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Clone возвращает копию подписки
+func (s *WebhookSubscription) Clone() *WebhookSubscription {
+	copied := *s
+	copied.Types = append([]ChangeType(nil), s.Types...)
+	return &copied
+}
+
+func isWebhookEventType(t ChangeType) bool {
+	for _, known := range WebhookEventTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookMessage - сообщение об изменении для одной подписки.
+// ID не меняется между попытками, чтобы получатель мог отбросить повторы.
+type WebhookMessage struct {
+	ID             string
+	SubscriptionID string
+	Change         *Change
+	Attempt        int // Номер попытки доставки, начиная с 0
+}
+
+// WebhookDelivery - запись истории об одной попытке доставки сообщения
+type WebhookDelivery struct {
+	MessageID      string
+	SubscriptionID string
+	Type           ChangeType
+	EventID        string
+	Attempt        int
+	StatusCode     int // 0, если ответ не получен
+	Error          string
+	Duration       time.Duration
+	At             time.Time
+}
+
+// Succeeded проверяет, доставлено ли сообщение этой попыткой
+func (d *WebhookDelivery) Succeeded() bool {
+	return d.Error == ""
+}
+
+// WebhookSender отправляет сообщение на адрес подписки и возвращает статус ответа
+type WebhookSender interface {
+	SendWebhook(sub *WebhookSubscription, msg *WebhookMessage) (int, error)
+}
+
+// WebhookRepository определяет интерфейс для хранения подписок и истории доставки
+type WebhookRepository interface {
+	Create(sub *WebhookSubscription) error
+	Get(userID, id string) (*WebhookSubscription, error)
+	List(userID string) ([]*WebhookSubscription, error)
+	// Delete удаляет подписку вместе с историей доставки
+	Delete(userID, id string) error
+	AddDelivery(delivery *WebhookDelivery) error
+	// Deliveries возвращает историю доставки подписки, начиная с последних попыток
+	Deliveries(subscriptionID string) ([]*WebhookDelivery, error)
+}
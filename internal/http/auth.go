@@ -0,0 +1,142 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/oziev02/event-calendar-service/internal/auth"
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/http/handlers"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+const (
+	// authRealm - область защиты в заголовке WWW-Authenticate
+	authRealm = "event-calendar"
+	// apiKeyHeader - заголовок с ключом API для клиентов, не использующих Authorization
+	apiKeyHeader = "X-API-Key"
+	// accessTokenParam - параметр строки запроса с учетными данными для EventSource,
+	// который не умеет передавать заголовки. Принимается только в GET-запросах
+	// к потоку изменений, чтобы ключи не попадали в адреса остальных маршрутов.
+	accessTokenParam = "access_token"
+)
+
+// AuthMiddleware аутентифицирует запросы по JWT или ключу API и передает
+// пользователя обработчикам через контекст (auth.FromContext).
+// Запросы без действительных учетных данных отклоняются с 401.
+//
+// Учетные данные принимаются из заголовков:
+//
+//	Authorization: Bearer <JWT или ключ API>
+//	Authorization: Basic <user:ключ API>  (календарные приложения по CalDAV)
+//	X-API-Key: <ключ API>
+type AuthMiddleware struct {
+	jwt    *auth.JWTVerifier // nil - JWT не принимаются
+	keys   *service.APIKeyService
+	logger logger.Logger
+}
+
+// NewAuthMiddleware создает middleware аутентификации.
+// jwt может быть nil, тогда принимаются только ключи API.
+func NewAuthMiddleware(jwt *auth.JWTVerifier, keys *service.APIKeyService, log logger.Logger) *AuthMiddleware {
+	return &AuthMiddleware{
+		jwt:    jwt,
+		keys:   keys,
+		logger: log,
+	}
+}
+
+// Handler оборачивает HTTP обработчик аутентификацией
+func (m *AuthMiddleware) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := m.authenticate(r)
+		if err != nil {
+			if !errors.Is(err, auth.ErrInvalidCredentials) && !errors.Is(err, auth.ErrMissingCredentials) {
+				m.logger.Log(logger.LevelError, "Failed to authenticate request", map[string]interface{}{
+					"error": err.Error(),
+				})
+				sendAuthError(w, r, http.StatusInternalServerError, "internal_error", "Internal server error")
+				return
+			}
+			w.Header().Add("WWW-Authenticate", `Bearer realm="`+authRealm+`"`)
+			w.Header().Add("WWW-Authenticate", `Basic realm="`+authRealm+`", charset="UTF-8"`)
+			sendAuthError(w, r, http.StatusUnauthorized, "unauthorized", "Unauthorized")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+	})
+}
+
+// authenticate находит учетные данные запроса и проверяет их
+func (m *AuthMiddleware) authenticate(r *http.Request) (*auth.Principal, error) {
+	header := r.Header.Get("Authorization")
+	switch {
+	case strings.HasPrefix(header, "Bearer "):
+		return m.verifyToken(strings.TrimSpace(strings.TrimPrefix(header, "Bearer ")))
+	case strings.HasPrefix(header, "Basic "):
+		username, password, ok := r.BasicAuth()
+		if !ok {
+			return nil, auth.ErrInvalidCredentials
+		}
+		principal, err := m.verifyToken(password)
+		if err != nil {
+			return nil, err
+		}
+		// Имя пользователя необязательно, но не может указывать на другого пользователя
+		if username != "" && username != principal.UserID {
+			return nil, auth.ErrInvalidCredentials
+		}
+		return principal, nil
+	case header != "":
+		return nil, auth.ErrInvalidCredentials
+	}
+
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return m.verifyAPIKey(key)
+	}
+	if r.Method == http.MethodGet && r.URL.Path == handlers.StreamPath {
+		if token := r.URL.Query().Get(accessTokenParam); token != "" {
+			return m.verifyToken(token)
+		}
+	}
+	return nil, auth.ErrMissingCredentials
+}
+
+// verifyToken проверяет ключ API или JWT; ключи отличаются префиксом
+func (m *AuthMiddleware) verifyToken(token string) (*auth.Principal, error) {
+	if strings.HasPrefix(token, service.APIKeyPrefix) {
+		return m.verifyAPIKey(token)
+	}
+	if m.jwt == nil {
+		return nil, auth.ErrInvalidCredentials
+	}
+	return m.jwt.Verify(token)
+}
+
+func (m *AuthMiddleware) verifyAPIKey(plain string) (*auth.Principal, error) {
+	key, err := m.keys.Authenticate(plain)
+	if errors.Is(err, domain.ErrInvalidAPIKey) {
+		return nil, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{UserID: key.UserID, Method: auth.MethodAPIKey, KeyID: key.ID}, nil
+}
+
+// sendAuthError отправляет ошибку в формате API v1 для его маршрутов
+// и в формате прежних маршрутов для остальных
+func sendAuthError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	var body interface{} = map[string]interface{}{"error": message}
+	if strings.HasPrefix(r.URL.Path, "/api/v1/") {
+		body = map[string]interface{}{
+			"error": handlers.APIError{Code: code, Message: message},
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,181 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/auth"
+	"github.com/oziev02/event-calendar-service/internal/caldav"
+	"github.com/oziev02/event-calendar-service/internal/http/handlers"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+	"github.com/oziev02/event-calendar-service/internal/stream"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Log(logger.LogLevel, string, map[string]interface{}) {}
+
+func (nopLogger) Close() error { return nil }
+
+const testJWTSecret = "test-secret"
+
+// newTestRouter собирает маршруты с хранилищами в памяти и выпускает ключ API для user1
+func newTestRouter(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	users := storage.NewMemoryUserRepository()
+	events := service.NewEventService(storage.NewMemoryRepository(), service.WithUserRepository(users))
+	userService := service.NewUserService(users)
+	keys := service.NewAPIKeyService(storage.NewMemoryAPIKeyRepository())
+	webhooks := service.NewWebhookService(storage.NewMemoryWebhookRepository())
+
+	_, plain, err := keys.Create("user1", "test")
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+
+	router := Router(
+		handlers.NewEventHandler(events, nopLogger{}),
+		handlers.NewUserHandler(userService, nopLogger{}),
+		handlers.NewAPIHandler(events, userService, webhooks, keys, nopLogger{}),
+		handlers.NewFeedHandler(events, userService, nopLogger{}),
+		caldav.NewHandler(events, nopLogger{}),
+		handlers.NewStreamHandler(stream.NewHub(0), nopLogger{}),
+		nil,
+		NewAuthMiddleware(auth.NewJWTVerifier(auth.JWTConfig{HMACSecret: []byte(testJWTSecret)}), keys, nopLogger{}),
+		nopLogger{},
+	)
+	return router, plain
+}
+
+func signTestJWT(subject string) string {
+	enc := base64.RawURLEncoding
+	header := enc.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	claims := enc.EncodeToString([]byte(`{"sub":"` + subject + `","exp":` + strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10) + `}`))
+	mac := hmac.New(sha256.New, []byte(testJWTSecret))
+	mac.Write([]byte(header + "." + claims))
+	return header + "." + claims + "." + enc.EncodeToString(mac.Sum(nil))
+}
+
+func serve(h http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthMiddleware_Credentials(t *testing.T) {
+	router, key := newTestRouter(t)
+
+	tests := []struct {
+		name   string
+		setup  func(r *http.Request)
+		target string
+		want   int
+	}{
+		{"missing", func(r *http.Request) {}, "/events_for_day?date=2024-01-15", http.StatusUnauthorized},
+		{"health check", func(r *http.Request) {}, "/healthz", http.StatusOK},
+		{"bearer api key", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+key) }, "/events_for_day?date=2024-01-15", http.StatusOK},
+		{"x-api-key", func(r *http.Request) { r.Header.Set("X-API-Key", key) }, "/events_for_day?date=2024-01-15", http.StatusOK},
+		{"basic", func(r *http.Request) { r.SetBasicAuth("user1", key) }, "/events_for_day?date=2024-01-15", http.StatusOK},
+		{"basic other user", func(r *http.Request) { r.SetBasicAuth("user2", key) }, "/events_for_day?date=2024-01-15", http.StatusUnauthorized},
+		{"bearer jwt", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+signTestJWT("user1")) }, "/events_for_day?date=2024-01-15", http.StatusOK},
+		{"revoked or forged key", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+key+"x") }, "/events_for_day?date=2024-01-15", http.StatusUnauthorized},
+		{"access token outside stream", func(r *http.Request) {}, "/events_for_day?date=2024-01-15&access_token=" + key, http.StatusUnauthorized},
+		{"matching user_id", func(r *http.Request) { r.Header.Set("X-API-Key", key) }, "/events_for_day?user_id=user1&date=2024-01-15", http.StatusOK},
+		{"mismatched user_id", func(r *http.Request) { r.Header.Set("X-API-Key", key) }, "/events_for_day?user_id=user2&date=2024-01-15", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			tt.setup(req)
+			rec := serve(router, req)
+			if rec.Code != tt.want {
+				t.Fatalf("Expected %d, got %d: %s", tt.want, rec.Code, rec.Body)
+			}
+			if tt.want == http.StatusUnauthorized && len(rec.Header().Values("WWW-Authenticate")) == 0 {
+				t.Errorf("Expected WWW-Authenticate challenge")
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_StreamAccessToken(t *testing.T) {
+	router, key := newTestRouter(t)
+
+	// Отмененный контекст сразу завершает поток после заголовков
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodGet, handlers.StreamPath+"?access_token="+key, nil).WithContext(ctx)
+	if rec := serve(router, req); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, handlers.StreamPath+"?access_token="+key+"x", nil).WithContext(ctx)
+	if rec := serve(router, req); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %d", rec.Code)
+	}
+}
+
+func TestAuthMiddleware_CreateEventForOtherUser(t *testing.T) {
+	router, key := newTestRouter(t)
+
+	body := `{"user_id":"user2","event":"Meeting","date":"2024-01-15"}`
+	req := httptest.NewRequest(http.MethodPost, "/create_event", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	if rec := serve(router, req); rec.Code != http.StatusForbidden {
+		t.Fatalf("Expected 403, got %d: %s", rec.Code, rec.Body)
+	}
+
+	// Без user_id событие создается для аутентифицированного пользователя
+	req = httptest.NewRequest(http.MethodPost, "/create_event", strings.NewReader(`{"event":"Meeting","date":"2024-01-15"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	if rec := serve(router, req); rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/events_for_day?date=2024-01-15", nil)
+	req.Header.Set("X-API-Key", key)
+	rec := serve(router, req)
+	var resp struct {
+		Result struct {
+			Events []handlers.EventDTO `json:"events"`
+		} `json:"result"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if events := resp.Result.Events; len(events) != 1 || events[0].UserID != "user1" {
+		t.Errorf("Expected event of user1, got %+v", events)
+	}
+}
+
+func TestAuthMiddleware_APIErrors(t *testing.T) {
+	router, key := newTestRouter(t)
+
+	rec := serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/users/user1/events", nil))
+	var resp struct {
+		Error handlers.APIError `json:"error"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusUnauthorized || resp.Error.Code != "unauthorized" {
+		t.Errorf("Expected 401 unauthorized, got %d %+v", rec.Code, resp.Error)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/user2/events", nil)
+	req.Header.Set("Authorization", "Bearer "+key)
+	rec = serve(router, req)
+	resp.Error = handlers.APIError{}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusForbidden || resp.Error.Code != "forbidden" {
+		t.Errorf("Expected 403 forbidden, got %d %+v", rec.Code, resp.Error)
+	}
+}
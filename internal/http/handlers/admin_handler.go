@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// AdminHandler обрабатывает административные HTTP запросы.
+// Каждый запрос должен содержать заголовок Authorization: Bearer <token>.
+type AdminHandler struct {
+	deadLetters *service.DeadLetterService
+	apiKeys     *service.APIKeyService
+	token       string
+	logger      logger.Logger
+}
+
+// NewAdminHandler создает новый административный обработчик
+func NewAdminHandler(
+	deadLetters *service.DeadLetterService,
+	apiKeys *service.APIKeyService,
+	token string,
+	log logger.Logger,
+) *AdminHandler {
+	return &AdminHandler{
+		deadLetters: deadLetters,
+		apiKeys:     apiKeys,
+		token:       token,
+		logger:      log,
+	}
+}
+
+// ListDeadLetters handles GET /admin/dead_letters
+func (h *AdminHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	letters, err := h.deadLetters.List()
+	if err != nil {
+		sendError(w, "Failed to list dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	dtos := make([]DeadLetterDTO, len(letters))
+	for i, letter := range letters {
+		dtos[i] = deadLetterToDTO(letter)
+	}
+	sendSuccess(w, map[string]interface{}{
+		"dead_letters": dtos,
+	})
+}
+
+// ReplayDeadLetters handles POST /admin/dead_letters/replay
+func (h *AdminHandler) ReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req ReplayDeadLettersRequest
+	if err := decodeRequest(r, &req); err != nil {
+		sendError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	if req.ID == "" && !req.All {
+		sendError(w, "id or all is required", http.StatusBadRequest)
+		return
+	}
+
+	replayed := 1
+	var err error
+	if req.All {
+		replayed, err = h.deadLetters.ReplayAll()
+	} else {
+		err = h.deadLetters.Replay(req.ID)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrDeadLetterNotFound) {
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendError(w, "Failed to replay dead letters", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Log(logger.LevelInfo, "Replayed dead letters", map[string]interface{}{
+		"count": replayed,
+	})
+	sendSuccess(w, map[string]interface{}{
+		"replayed": replayed,
+	})
+}
+
+// CreateAPIKey handles POST /admin/api_keys.
+// Выпускает первый ключ пользователю, у которого еще нет учетных данных.
+func (h *AdminHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !h.authorized(r) {
+		sendError(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateUserAPIKeyRequest
+	if err := decodeRequest(r, &req); err != nil {
+		sendError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	key, plain, err := h.apiKeys.Create(req.UserID, req.Name)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidUserID) {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendError(w, "Failed to create api key", http.StatusInternalServerError)
+		return
+	}
+
+	h.logger.Log(logger.LevelInfo, "Created api key", map[string]interface{}{
+		"user_id": key.UserID,
+		"key_id":  key.ID,
+	})
+	sendSuccess(w, map[string]interface{}{
+		"id":      key.ID,
+		"user_id": key.UserID,
+		"key":     plain,
+	})
+}
+
+// authorized проверяет административный токен
+func (h *AdminHandler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && h.token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// ReplayDeadLettersRequest представляет запрос на повторную отправку
+type ReplayDeadLettersRequest struct {
+	ID  string `json:"id,omitempty" form:"id"`
+	All bool   `json:"all,omitempty" form:"all"`
+}
+
+// CreateUserAPIKeyRequest представляет запрос на выпуск ключа API пользователю
+type CreateUserAPIKeyRequest struct {
+	UserID string `json:"user_id" form:"user_id"`
+	Name   string `json:"name,omitempty" form:"name"`
+}
+
+// DeadLetterDTO представляет недоставленное напоминание в ответе
+type DeadLetterDTO struct {
+	ID           string   `json:"id"`
+	EventID      string   `json:"event_id"`
+	UserID       string   `json:"user_id"`
+	Text         string   `json:"text"`
+	ReminderTime string   `json:"reminder_time"`
+	Channels     []string `json:"channels,omitempty"`
+	Attempts     int      `json:"attempts"`
+	LastError    string   `json:"last_error"`
+	FailedAt     string   `json:"failed_at"`
+}
+
+func deadLetterToDTO(letter *domain.DeadLetter) DeadLetterDTO {
+	dto := DeadLetterDTO{
+		ID:           letter.ID,
+		EventID:      letter.Task.EventID,
+		UserID:       letter.Task.UserID,
+		Text:         letter.Task.Text,
+		ReminderTime: letter.Task.Time.Format(time.RFC3339),
+		Attempts:     letter.Attempts,
+		LastError:    letter.LastError,
+		FailedAt:     letter.FailedAt.Format(time.RFC3339),
+	}
+	for _, channel := range letter.Task.Channels {
+		dto.Channels = append(dto.Channels, string(channel))
+	}
+	return dto
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// CalendarRequest задает настройки календаря. default_reminder - смещение
+// до начала события (15m, 2h, 1d); пустое значение - без напоминания.
+type CalendarRequest struct {
+	Name            string `json:"name"`
+	Color           string `json:"color"`
+	TimeZone        string `json:"time_zone"`
+	DefaultReminder string `json:"default_reminder"`
+}
+
+// CalendarDTO представляет именованный календарь; user_id - его владелец
+type CalendarDTO struct {
+	ID              string `json:"id"`
+	UserID          string `json:"user_id"`
+	Name            string `json:"name"`
+	Color           string `json:"color,omitempty"`
+	TimeZone        string `json:"time_zone,omitempty"`
+	DefaultReminder string `json:"default_reminder,omitempty"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+// serveCalendars разбирает путь /api/v1/users/{user}/calendars[/{id}]
+func (h *APIHandler) serveCalendars(w http.ResponseWriter, r *http.Request, userID string, rest []string) {
+	switch len(rest) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			h.listCalendars(w, userID)
+		case http.MethodPost:
+			h.createCalendar(w, r, userID)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	case 1:
+		switch r.Method {
+		case http.MethodGet:
+			h.getCalendar(w, userID, rest[0])
+		case http.MethodPut:
+			h.replaceCalendar(w, r, userID, rest[0])
+		case http.MethodDelete:
+			if err := h.service.DeleteCalendar(userID, rest[0]); err != nil {
+				h.sendError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodDelete)
+		}
+	default:
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+	}
+}
+
+// listCalendars handles GET /api/v1/users/{user}/calendars:
+// календари пользователя и открытые ему календари других пользователей
+func (h *APIHandler) listCalendars(w http.ResponseWriter, userID string) {
+	calendars, err := h.service.ListCalendars(userID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	dtos := make([]CalendarDTO, len(calendars))
+	for i, calendar := range calendars {
+		dtos[i] = calendarToDTO(calendar)
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"calendars": dtos,
+	})
+}
+
+// createCalendar handles POST /api/v1/users/{user}/calendars
+func (h *APIHandler) createCalendar(w http.ResponseWriter, r *http.Request, userID string) {
+	calendar, ok := h.decodeCalendar(w, r)
+	if !ok {
+		return
+	}
+	created, err := h.service.CreateCalendar(userID, calendar)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	w.Header().Set("Location", calendarURL(userID, created.ID))
+	sendJSON(w, http.StatusCreated, calendarToDTO(created))
+}
+
+// getCalendar handles GET /api/v1/users/{user}/calendars/{id}
+func (h *APIHandler) getCalendar(w http.ResponseWriter, userID, id string) {
+	calendar, err := h.service.GetCalendar(userID, id)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, calendarToDTO(calendar))
+}
+
+// replaceCalendar handles PUT /api/v1/users/{user}/calendars/{id}.
+// Настройки заменяются целиком; события календаря не меняются.
+func (h *APIHandler) replaceCalendar(w http.ResponseWriter, r *http.Request, userID, id string) {
+	calendar, ok := h.decodeCalendar(w, r)
+	if !ok {
+		return
+	}
+	calendar.ID = id
+	updated, err := h.service.UpdateCalendar(userID, calendar)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, calendarToDTO(updated))
+}
+
+// decodeCalendar разбирает CalendarRequest и отправляет ошибку, если запрос неверен
+func (h *APIHandler) decodeCalendar(w http.ResponseWriter, r *http.Request) (*domain.Calendar, bool) {
+	var req CalendarRequest
+	if !h.decode(w, r, &req) {
+		return nil, false
+	}
+	calendar := &domain.Calendar{
+		Name:     req.Name,
+		Color:    req.Color,
+		TimeZone: req.TimeZone,
+	}
+	if req.DefaultReminder != "" {
+		offset, err := parseOffset(req.DefaultReminder)
+		if err != nil || offset < 0 {
+			h.sendError(w, errInvalidReminder)
+			return nil, false
+		}
+		calendar.DefaultReminder = &domain.Reminder{Offset: offset}
+	}
+	return calendar, true
+}
+
+func calendarToDTO(calendar *domain.Calendar) CalendarDTO {
+	dto := CalendarDTO{
+		ID:        calendar.ID,
+		UserID:    calendar.UserID,
+		Name:      calendar.Name,
+		Color:     calendar.Color,
+		TimeZone:  calendar.TimeZone,
+		CreatedAt: calendar.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: calendar.UpdatedAt.Format(time.RFC3339),
+	}
+	if calendar.DefaultReminder != nil {
+		dto.DefaultReminder = formatOffset(calendar.DefaultReminder.Offset)
+	}
+	return dto
+}
+
+func calendarURL(userID, id string) string {
+	return APIPrefix + url.PathEscape(userID) + "/calendars/" + url.PathEscape(id)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// codeSchedulingConflict - код ошибки события, отклоненного из-за пересечений
+const codeSchedulingConflict = "scheduling_conflict"
+
+// SavedEventDTO - сохраненное событие и события, с которыми оно пересекается
+// по времени (в режиме conflicts=warn)
+type SavedEventDTO struct {
+	EventDTO
+	Conflicts []EventDTO `json:"conflicts,omitempty"`
+}
+
+// parseConflictMode разбирает параметр conflicts; по умолчанию событие
+// сохраняется, а пересечения возвращаются в ответе
+func parseConflictMode(value string) (domain.ConflictMode, error) {
+	mode, err := domain.ParseConflictMode(value)
+	if err != nil {
+		return "", err
+	}
+	if mode == "" {
+		mode = domain.ConflictWarn
+	}
+	return mode, nil
+}
+
+// sendSavedEvent отправляет сохраненное событие, а в режиме warn - и его пересечения.
+// Событие уже сохранено, поэтому ошибка поиска пересечений только записывается в лог.
+func (h *APIHandler) sendSavedEvent(w http.ResponseWriter, status int, event *domain.Event, mode domain.ConflictMode) {
+	dto := SavedEventDTO{EventDTO: eventToDTO(event)}
+	if mode == domain.ConflictWarn {
+		conflicts, err := h.service.Conflicts(event)
+		if err != nil {
+			h.logger.Log(logger.LevelError, "Failed to find conflicting events", map[string]interface{}{
+				"event_id": event.ID,
+				"error":    err.Error(),
+			})
+		}
+		if len(conflicts) > 0 {
+			dto.Conflicts = eventsToDTO(conflicts)
+		}
+	}
+	sendJSON(w, status, dto)
+}
+
+// sendConflictError отправляет 409 со списком событий, с которыми пересекается отклоненное событие
+func sendConflictError(w http.ResponseWriter, err *domain.ConflictError) {
+	sendJSON(w, http.StatusConflict, map[string]interface{}{
+		"error":     APIError{Code: codeSchedulingConflict, Message: err.Error()},
+		"conflicts": eventsToDTO(err.Conflicts),
+	})
+}
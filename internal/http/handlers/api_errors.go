@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/ical"
+)
+
+// Коды ошибок API v1
+const (
+	codeNotFound           = "not_found"
+	codeMethodNotAllowed   = "method_not_allowed"
+	codeMalformedRequest   = "malformed_request"
+	codeInternalError      = "internal_error"
+	codeEventNotFound      = "event_not_found"
+	codeOccurrenceNotFound = "occurrence_not_found"
+	codeRequestTooLarge    = "request_too_large"
+	codeWebhookNotFound    = "webhook_not_found"
+	codeUnauthorized       = "unauthorized"
+)
+
+// APIError описывает ошибку API v1: машиночитаемый код и сообщение для человека
+type APIError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// apiErrorCodes сопоставляет ошибки сервиса и разбора запроса с кодами API.
+// ErrOccurrenceNotFound проверяется раньше ErrEventNotFound, так как оборачивает ее.
+var apiErrorCodes = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{domain.ErrOccurrenceNotFound, http.StatusNotFound, codeOccurrenceNotFound},
+	{domain.ErrEventNotFound, http.StatusNotFound, codeEventNotFound},
+	{errUnauthenticated, http.StatusUnauthorized, codeUnauthorized},
+	{errUserMismatch, http.StatusForbidden, "forbidden"},
+	{domain.ErrWebhookNotFound, http.StatusNotFound, codeWebhookNotFound},
+	{domain.ErrAPIKeyNotFound, http.StatusNotFound, "api_key_not_found"},
+	{domain.ErrShareNotFound, http.StatusNotFound, "share_not_found"},
+	{domain.ErrAccessDenied, http.StatusForbidden, "access_denied"},
+	{domain.ErrNotAttendee, http.StatusForbidden, "not_attendee"},
+	{domain.ErrCalendarNotFound, http.StatusNotFound, "calendar_not_found"},
+	{domain.ErrSchedulingConflict, http.StatusConflict, codeSchedulingConflict},
+	{domain.ErrInvalidUserID, http.StatusUnprocessableEntity, "invalid_user_id"},
+	{domain.ErrInvalidEventText, http.StatusUnprocessableEntity, "invalid_text"},
+	{domain.ErrInvalidDate, http.StatusUnprocessableEntity, "invalid_date"},
+	{domain.ErrInvalidEndTime, http.StatusUnprocessableEntity, "invalid_end_time"},
+	{domain.ErrInvalidTimeZone, http.StatusUnprocessableEntity, "invalid_time_zone"},
+	{domain.ErrInvalidReminder, http.StatusUnprocessableEntity, "invalid_reminder"},
+	{domain.ErrInvalidRecurrence, http.StatusUnprocessableEntity, "invalid_recurrence"},
+	{domain.ErrInvalidScope, http.StatusUnprocessableEntity, "invalid_scope"},
+	{domain.ErrInvalidRange, http.StatusUnprocessableEntity, "invalid_range"},
+	{domain.ErrInvalidArchiveFilter, http.StatusUnprocessableEntity, "invalid_status"},
+	{domain.ErrInvalidSort, http.StatusUnprocessableEntity, "invalid_sort"},
+	{domain.ErrInvalidCursor, http.StatusUnprocessableEntity, "invalid_cursor"},
+	{domain.ErrInvalidQuery, http.StatusUnprocessableEntity, "invalid_query"},
+	{domain.ErrInvalidWebhookURL, http.StatusUnprocessableEntity, "invalid_webhook_url"},
+	{domain.ErrInvalidWebhookEvent, http.StatusUnprocessableEntity, "invalid_webhook_event"},
+	{domain.ErrInvalidRole, http.StatusUnprocessableEntity, "invalid_role"},
+	{domain.ErrInvalidCalendarName, http.StatusUnprocessableEntity, "invalid_calendar_name"},
+	{domain.ErrInvalidColor, http.StatusUnprocessableEntity, "invalid_color"},
+	{domain.ErrInvalidAttendee, http.StatusUnprocessableEntity, "invalid_attendee"},
+	{domain.ErrInvalidPartStat, http.StatusUnprocessableEntity, "invalid_rsvp_status"},
+	{domain.ErrInvalidProposal, http.StatusUnprocessableEntity, "invalid_proposal"},
+	{domain.ErrInvalidConflictMode, http.StatusUnprocessableEntity, "invalid_conflict_mode"},
+	{domain.ErrInvalidFreeBusyQuery, http.StatusUnprocessableEntity, "invalid_freebusy_query"},
+	{ical.ErrInvalidCalendar, http.StatusUnprocessableEntity, "invalid_calendar"},
+	{ical.ErrMissingUID, http.StatusUnprocessableEntity, "missing_uid"},
+	{errDateRequired, http.StatusUnprocessableEntity, "start_required"},
+	{errInvalidDateFormat, http.StatusUnprocessableEntity, "invalid_date"},
+	{errInvalidStartFormat, http.StatusUnprocessableEntity, "invalid_start"},
+	{errInvalidEndFormat, http.StatusUnprocessableEntity, "invalid_end_time"},
+	{errInvalidDuration, http.StatusUnprocessableEntity, "invalid_duration"},
+	{errEndAndDuration, http.StatusUnprocessableEntity, "invalid_end_time"},
+	{errInvalidReminderFormat, http.StatusUnprocessableEntity, "invalid_reminder"},
+	{errInvalidReminder, http.StatusUnprocessableEntity, "invalid_reminder"},
+	{errInvalidOccurrence, http.StatusUnprocessableEntity, "invalid_occurrence"},
+	{errInvalidRangeFormat, http.StatusUnprocessableEntity, "invalid_range"},
+	{errInvalidLimit, http.StatusUnprocessableEntity, "invalid_limit"},
+}
+
+// apiErrorCode возвращает статус и код API для ошибки сервиса или разбора запроса
+func apiErrorCode(err error) (int, string, bool) {
+	for _, c := range apiErrorCodes {
+		if errors.Is(err, c.err) {
+			return c.status, c.code, true
+		}
+	}
+	return 0, "", false
+}
+
+func sendAPIErrorCode(w http.ResponseWriter, status int, code, message string) {
+	sendJSON(w, status, map[string]interface{}{
+		"error": APIError{Code: code, Message: message},
+	})
+}
+
+func sendJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// defaultSlotLimit - число слотов, предлагаемых без параметра limit
+const defaultSlotLimit = 5
+
+// IntervalDTO представляет промежуток времени [start, end)
+type IntervalDTO struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// FreeBusyDTO содержит интервалы занятости одного пользователя
+type FreeBusyDTO struct {
+	UserID string        `json:"user_id"`
+	Busy   []IntervalDTO `json:"busy"`
+}
+
+// freeBusy handles GET /api/v1/users/{user}/freebusy?users=...&from=...&to=...:
+// интервалы занятости пользователей users (по умолчанию - самого пользователя)
+func (h *APIHandler) freeBusy(w http.ResponseWriter, r *http.Request, userID string) {
+	query := r.URL.Query()
+	loc, err := h.resolveLocation(userID, query.Get("tz"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	from, to, err := parseRange(query.Get("from"), query.Get("to"), loc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	users := uniqueIDs(parseIDList(query["users"]))
+	if len(users) == 0 {
+		users = []string{userID}
+	}
+
+	busy, err := h.service.FreeBusy(users, from, to)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	dtos := make([]FreeBusyDTO, len(users))
+	for i, user := range users {
+		dtos[i] = FreeBusyDTO{UserID: user, Busy: intervalsToDTO(busy[user], loc)}
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"from":  from.In(loc).Format(time.RFC3339),
+		"to":    to.In(loc).Format(time.RFC3339),
+		"users": dtos,
+	})
+}
+
+// findSlots handles GET /api/v1/users/{user}/slots?users=...&from=...&to=...&duration=...:
+// первые limit слотов, когда свободны пользователь и все пользователи users
+func (h *APIHandler) findSlots(w http.ResponseWriter, r *http.Request, userID string) {
+	query := r.URL.Query()
+	loc, err := h.resolveLocation(userID, query.Get("tz"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	from, to, err := parseRange(query.Get("from"), query.Get("to"), loc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	duration, err := time.ParseDuration(query.Get("duration"))
+	if err != nil || duration <= 0 {
+		h.sendError(w, errInvalidDuration)
+		return
+	}
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if limit == 0 {
+		limit = defaultSlotLimit
+	}
+
+	users := uniqueIDs(append([]string{userID}, parseIDList(query["users"])...))
+	slots, err := h.service.FindSlots(users, from, to, duration, limit)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"slots": intervalsToDTO(slots, loc),
+	})
+}
+
+// uniqueIDs удаляет повторы, сохраняя порядок
+func uniqueIDs(ids []string) []string {
+	var result []string
+	for _, id := range ids {
+		if !slices.Contains(result, id) {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func intervalsToDTO(intervals []domain.Interval, loc *time.Location) []IntervalDTO {
+	dtos := make([]IntervalDTO, len(intervals))
+	for i, interval := range intervals {
+		dtos[i] = IntervalDTO{
+			Start: interval.Start.In(loc).Format(time.RFC3339),
+			End:   interval.End.In(loc).Format(time.RFC3339),
+		}
+	}
+	return dtos
+}
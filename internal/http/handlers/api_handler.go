@@ -0,0 +1,719 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// APIPrefix - префикс ресурсов пользователей в API v1
+const APIPrefix = "/api/v1/users/"
+
+// APIHandler обслуживает ресурсное API v1:
+//
+//	GET, POST                /api/v1/users/{user}/events
+//	GET, PUT, PATCH, DELETE  /api/v1/users/{user}/events/{id}
+//	POST                     /api/v1/users/{user}/events/{id}/rsvp
+//	POST                     /api/v1/users/{user}/events/{id}/propose
+//	GET                      /api/v1/users/{user}/invitations
+//	GET                      /api/v1/users/{user}/freebusy
+//	GET                      /api/v1/users/{user}/slots
+//	GET                      /api/v1/users/{user}/search
+//	GET, POST, DELETE        /api/v1/users/{user}/feed
+//	POST                     /api/v1/users/{user}/import
+//	GET, POST                /api/v1/users/{user}/webhooks
+//	GET, DELETE              /api/v1/users/{user}/webhooks/{id}
+//	GET                      /api/v1/users/{user}/webhooks/{id}/deliveries
+//	GET, POST                /api/v1/users/{user}/api_keys
+//	DELETE                   /api/v1/users/{user}/api_keys/{id}
+//	GET                      /api/v1/users/{user}/shares
+//	PUT, DELETE              /api/v1/users/{user}/shares/{grantee}
+//	GET                      /api/v1/users/{user}/shared
+//	GET, POST                /api/v1/users/{user}/calendars
+//	GET, PUT, DELETE         /api/v1/users/{user}/calendars/{id}
+//
+// Пользователь из пути должен совпадать с аутентифицированным.
+// Ошибки возвращаются в виде {"error": {"code": "...", "message": "..."}}.
+type APIHandler struct {
+	service  *service.EventService
+	users    *service.UserService
+	webhooks *service.WebhookService
+	apiKeys  *service.APIKeyService
+	logger   logger.Logger
+}
+
+// NewAPIHandler создает новый обработчик API v1
+func NewAPIHandler(
+	service *service.EventService,
+	users *service.UserService,
+	webhooks *service.WebhookService,
+	apiKeys *service.APIKeyService,
+	log logger.Logger,
+) *APIHandler {
+	return &APIHandler{
+		service:  service,
+		users:    users,
+		webhooks: webhooks,
+		apiKeys:  apiKeys,
+		logger:   log,
+	}
+}
+
+// PatchEventRequest содержит изменяемые поля события. Отсутствующие поля
+// сохраняют текущие значения; пустые end, reminder_time и rrule удаляют их.
+type PatchEventRequest struct {
+	Event        *string   `json:"event"`
+	Date         *string   `json:"date"`
+	Start        *string   `json:"start"`
+	End          *string   `json:"end"`
+	Duration     *string   `json:"duration"`
+	AllDay       *bool     `json:"all_day"`
+	TimeZone     *string   `json:"time_zone"`
+	ReminderTime *string   `json:"reminder_time"`
+	Reminders    *[]string `json:"reminders"`
+	RRule        *string   `json:"rrule"`
+	// CalendarID переносит событие в другой календарь; пустое значение - в календарь по умолчанию
+	CalendarID *string `json:"calendar_id"`
+	// Attendees заменяет участников; пустой список удаляет их
+	Attendees *[]string `json:"attendees"`
+}
+
+// ServeHTTP разбирает путь /api/v1/users/{user}/{resource}[/...] и передает
+// запрос обработчику ресурса
+func (h *APIHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments, ok := splitAPIPath(r.URL.EscapedPath())
+	if !ok {
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+		return
+	}
+	if _, err := requestUser(r, segments[0]); err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if len(segments) < 2 {
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+		return
+	}
+
+	userID, rest := segments[0], segments[2:]
+	switch segments[1] {
+	case "events":
+		h.serveEvents(w, r, userID, rest)
+	case "calendars":
+		h.serveCalendars(w, r, userID, rest)
+	case "shares":
+		h.serveShares(w, r, userID, rest)
+	case "shared":
+		if collectionRequest(w, r, rest, http.MethodGet) {
+			h.listSharedCalendars(w, userID)
+		}
+	case "webhooks":
+		h.serveWebhooks(w, r, userID, rest)
+	case "api_keys":
+		h.serveAPIKeys(w, r, userID, rest)
+	case "invitations":
+		if collectionRequest(w, r, rest, http.MethodGet) {
+			h.listInvitations(w, userID)
+		}
+	case "freebusy":
+		if collectionRequest(w, r, rest, http.MethodGet) {
+			h.freeBusy(w, r, userID)
+		}
+	case "slots":
+		if collectionRequest(w, r, rest, http.MethodGet) {
+			h.findSlots(w, r, userID)
+		}
+	case "search":
+		if collectionRequest(w, r, rest, http.MethodGet) {
+			h.searchText(w, r, userID)
+		}
+	case "feed":
+		if len(rest) != 0 {
+			sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+			return
+		}
+		h.serveFeed(w, r, userID)
+	case "import":
+		if collectionRequest(w, r, rest, http.MethodPost) {
+			h.importCalendar(w, r, userID)
+		}
+	default:
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+	}
+}
+
+// collectionRequest проверяет, что запрос обращается к ресурсу без вложенного
+// пути и методом method; иначе отвечает ошибкой и возвращает false
+func collectionRequest(w http.ResponseWriter, r *http.Request, rest []string, method string) bool {
+	if len(rest) != 0 {
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+		return false
+	}
+	if r.Method != method {
+		methodNotAllowed(w, method)
+		return false
+	}
+	return true
+}
+
+// serveEvents обслуживает /api/v1/users/{user}/events[/{id}[/{action}]]
+func (h *APIHandler) serveEvents(w http.ResponseWriter, r *http.Request, userID string, rest []string) {
+	switch len(rest) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			h.listEvents(w, r, userID)
+		case http.MethodPost:
+			h.createEvent(w, r, userID)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	case 1:
+		eventID := rest[0]
+		switch r.Method {
+		case http.MethodGet:
+			h.getEvent(w, userID, eventID)
+		case http.MethodPut:
+			h.replaceEvent(w, r, userID, eventID)
+		case http.MethodPatch:
+			h.patchEvent(w, r, userID, eventID)
+		case http.MethodDelete:
+			h.deleteEvent(w, r, userID, eventID)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+		}
+	case 2:
+		h.serveInvitationResponse(w, r, userID, rest[0], rest[1])
+	default:
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+	}
+}
+
+// listEvents handles GET /api/v1/users/{user}/events?from=...&to=...
+// с необязательными q, status, sort, limit, cursor и calendar_id
+func (h *APIHandler) listEvents(w http.ResponseWriter, r *http.Request, userID string) {
+	query := r.URL.Query()
+	loc, err := h.resolveLocation(userID, query.Get("tz"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	search, err := parseSearch(query, loc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	page, err := h.service.SearchEvents(userID, search)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	resp := map[string]interface{}{
+		"events": eventsToDTO(page.Events),
+	}
+	if page.NextCursor != "" {
+		resp["next_cursor"] = page.NextCursor
+	}
+	sendJSON(w, http.StatusOK, resp)
+}
+
+// searchText handles GET /api/v1/users/{user}/search?q=...
+// с необязательными status (по умолчанию all) и limit
+func (h *APIHandler) searchText(w http.ResponseWriter, r *http.Request, userID string) {
+	query := r.URL.Query()
+	archive := domain.ArchiveAll
+	if status := query.Get("status"); status != "" {
+		var err error
+		if archive, err = domain.ParseArchiveFilter(status); err != nil {
+			h.sendError(w, err)
+			return
+		}
+	}
+	limit, err := parseLimit(query.Get("limit"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	matches, err := h.service.SearchText(userID, query.Get("q"), archive, limit)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	results := make([]SearchResultDTO, len(matches))
+	for i, match := range matches {
+		results[i] = SearchResultDTO{Score: match.Score, Event: eventToDTO(match.Event)}
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"results": results,
+	})
+}
+
+// SearchResultDTO представляет найденное событие и его релевантность
+type SearchResultDTO struct {
+	Score float64  `json:"score"`
+	Event EventDTO `json:"event"`
+}
+
+// serveFeed управляет адресом подписки на календарь:
+// GET возвращает адрес (включая подписку при первом обращении),
+// POST заменяет секрет, DELETE выключает подписку
+func (h *APIHandler) serveFeed(w http.ResponseWriter, r *http.Request, userID string) {
+	var token string
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		token, err = h.users.EnableFeed(userID)
+	case http.MethodPost:
+		token, err = h.users.RotateFeedToken(userID)
+	case http.MethodDelete:
+		if err := h.users.DisableFeed(userID); err != nil {
+			h.sendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		methodNotAllowed(w, http.MethodGet, http.MethodPost, http.MethodDelete)
+		return
+	}
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"url": feedURL(r, userID, token),
+	})
+}
+
+// parseSearch разбирает параметры поиска событий
+func parseSearch(query url.Values, loc *time.Location) (service.EventSearch, error) {
+	var search service.EventSearch
+	var err error
+
+	search.From, search.To, err = parseRange(query.Get("from"), query.Get("to"), loc)
+	if err != nil {
+		return search, err
+	}
+	if search.Archive, err = domain.ParseArchiveFilter(query.Get("status")); err != nil {
+		return search, err
+	}
+	if search.Sort, err = domain.ParseSortOrder(query.Get("sort")); err != nil {
+		return search, err
+	}
+	if search.Limit, err = parseLimit(query.Get("limit")); err != nil {
+		return search, err
+	}
+	search.Text = query.Get("q")
+	search.Cursor = query.Get("cursor")
+	search.Calendars = parseIDList(query["calendar_id"])
+	return search, nil
+}
+
+// createEvent handles POST /api/v1/users/{user}/events.
+// Проверка пересечений с другими событиями задается параметром запроса conflicts.
+func (h *APIHandler) createEvent(w http.ResponseWriter, r *http.Request, userID string) {
+	var req CreateEventRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	mode, err := parseConflictMode(r.URL.Query().Get("conflicts"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	loc, err := h.resolveLocation(userID, req.TimeZone)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	input, err := parseEventFields(req.fields(), loc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	// Пользователь определяется путем, user_id из тела не используется
+	if req.Owner != "" {
+		input.opts = append(input.opts, service.WithOwner(req.Owner))
+	}
+	if req.CalendarID != "" {
+		input.opts = append(input.opts, service.WithCalendar(req.CalendarID))
+	}
+	input.opts = append(input.opts, service.WithConflictMode(mode))
+	event, err := h.service.CreateEvent(userID, req.Event, input.start, input.reminderTime, input.opts...)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", eventURL(userID, event.ID))
+	h.sendSavedEvent(w, http.StatusCreated, event, mode)
+}
+
+// getEvent handles GET /api/v1/users/{user}/events/{id}
+func (h *APIHandler) getEvent(w http.ResponseWriter, userID, eventID string) {
+	event, err := h.service.GetEvent(userID, eventID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, eventToDTO(event))
+}
+
+// replaceEvent handles PUT /api/v1/users/{user}/events/{id}.
+// Событие заменяется целиком: отсутствующие окончание, напоминания,
+// участники и правило повторения удаляются, а без calendar_id событие остается
+// в своем календаре. Область изменения серии задается параметрами
+// запроса scope и occurrence_date.
+func (h *APIHandler) replaceEvent(w http.ResponseWriter, r *http.Request, userID, eventID string) {
+	var req CreateEventRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	mode, err := parseConflictMode(r.URL.Query().Get("conflicts"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	loc, err := h.resolveLocation(userID, req.TimeZone)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	input, err := parseEventFields(req.fields(), loc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	scopeOpt, _, err := parseScope(h.service, userID, eventID, r.URL.Query().Get("scope"), r.URL.Query().Get("occurrence_date"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	// Сброс выполняется до разобранных опций, которые переопределяют его
+	opts := []service.EventOption{
+		service.WithEnd(time.Time{}),
+		service.WithReminders(nil),
+		service.WithAttendees(nil),
+	}
+	if req.TimeZone == "" {
+		opts = append(opts, service.WithTimeZone(zoneName(loc)))
+	}
+	if scopeOpt == nil {
+		// Для вхождения или части серии правило наследуется от серии
+		opts = append(opts, service.WithRecurrence(nil))
+	} else {
+		opts = append(opts, scopeOpt)
+	}
+	opts = append(opts, input.opts...)
+	if req.CalendarID != "" {
+		opts = append(opts, service.WithCalendar(req.CalendarID))
+	}
+	opts = append(opts, service.WithConflictMode(mode))
+
+	event, err := h.service.UpdateEvent(userID, eventID, req.Event, input.start, input.reminderTime, opts...)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendSavedEvent(w, http.StatusOK, event, mode)
+}
+
+// patchEvent handles PATCH /api/v1/users/{user}/events/{id}.
+// Изменяются только переданные поля. Для вхождения серии без нового начала
+// за основу берутся начало и напоминание этого вхождения.
+func (h *APIHandler) patchEvent(w http.ResponseWriter, r *http.Request, userID, eventID string) {
+	var req PatchEventRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	scopeStr, occurrenceStr := r.URL.Query().Get("scope"), r.URL.Query().Get("occurrence_date")
+	scopeOpt, occurrence, err := parseScope(h.service, userID, eventID, scopeStr, occurrenceStr)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	mode, err := parseConflictMode(r.URL.Query().Get("conflicts"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	event, err := h.service.GetEvent(userID, eventID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	loc := event.Location()
+	if req.TimeZone != nil && *req.TimeZone != "" {
+		if loc, err = domain.LoadLocation(*req.TimeZone); err != nil {
+			h.sendError(w, err)
+			return
+		}
+	}
+
+	text, start, reminderTime, opts, err := patchEventInput(&req, event, occurrence, loc)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if scopeOpt != nil {
+		opts = append(opts, scopeOpt)
+	}
+	opts = append(opts, service.WithConflictMode(mode))
+
+	updated, err := h.service.UpdateEvent(userID, eventID, text, start, reminderTime, opts...)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	h.sendSavedEvent(w, http.StatusOK, updated, mode)
+}
+
+// patchEventInput объединяет переданные поля с текущим событием
+func patchEventInput(
+	req *PatchEventRequest,
+	event *domain.Event,
+	occurrence time.Time,
+	loc *time.Location,
+) (string, time.Time, *time.Time, []service.EventOption, error) {
+	var opts []service.EventOption
+
+	text := event.Text
+	if req.Event != nil {
+		text = *req.Event
+	}
+
+	// Без нового начала сохраняется текущее начало события или вхождения
+	start, reminderTime := event.Date, event.ReminderTime
+	if event.IsRecurring() && !occurrence.IsZero() {
+		start = occurrence
+		if reminderTime != nil {
+			rt := reminderTime.Add(occurrence.Sub(event.Date))
+			reminderTime = &rt
+		}
+	}
+
+	allDay := event.AllDay
+	if req.AllDay != nil {
+		allDay = *req.AllDay
+	}
+	if req.Start != nil || req.Date != nil {
+		var err error
+		start, allDay, err = parseStart(deref(req.Start), deref(req.Date), allDay, loc)
+		if err != nil {
+			return "", time.Time{}, nil, nil, err
+		}
+	} else if allDay {
+		start = startOfDay(start, loc)
+	}
+	if req.AllDay != nil || req.Date != nil {
+		opts = append(opts, service.WithAllDay(allDay))
+	}
+	if req.TimeZone != nil {
+		opts = append(opts, service.WithTimeZone(*req.TimeZone))
+	}
+
+	if req.End != nil && *req.End == "" {
+		opts = append(opts, service.WithEnd(time.Time{}))
+	} else {
+		endOpt, err := parseEnd(deref(req.End), deref(req.Duration), start, allDay, loc)
+		if err != nil {
+			return "", time.Time{}, nil, nil, err
+		}
+		if endOpt != nil {
+			opts = append(opts, endOpt)
+		}
+	}
+
+	if req.ReminderTime != nil {
+		reminderTime = nil
+		if *req.ReminderTime != "" {
+			rt, err := time.Parse(time.RFC3339, *req.ReminderTime)
+			if err != nil {
+				return "", time.Time{}, nil, nil, errInvalidReminderFormat
+			}
+			reminderTime = &rt
+		}
+	}
+	if req.Reminders != nil {
+		reminders, err := parseReminders(*req.Reminders)
+		if err != nil {
+			return "", time.Time{}, nil, nil, err
+		}
+		opts = append(opts, service.WithReminders(reminders))
+	}
+
+	if req.RRule != nil {
+		var rule *domain.RecurrenceRule
+		if *req.RRule != "" {
+			var err error
+			if rule, err = domain.ParseRecurrenceRule(*req.RRule); err != nil {
+				return "", time.Time{}, nil, nil, err
+			}
+		}
+		opts = append(opts, service.WithRecurrence(rule))
+	}
+
+	if req.CalendarID != nil {
+		opts = append(opts, service.WithCalendar(*req.CalendarID))
+	}
+	if req.Attendees != nil {
+		opts = append(opts, service.WithAttendees(*req.Attendees))
+	}
+
+	return text, start, reminderTime, opts, nil
+}
+
+// deleteEvent handles DELETE /api/v1/users/{user}/events/{id}
+func (h *APIHandler) deleteEvent(w http.ResponseWriter, r *http.Request, userID, eventID string) {
+	var opts []service.EventOption
+	scopeOpt, _, err := parseScope(h.service, userID, eventID, r.URL.Query().Get("scope"), r.URL.Query().Get("occurrence_date"))
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	if scopeOpt != nil {
+		opts = append(opts, scopeOpt)
+	}
+
+	if err := h.service.DeleteEvent(userID, eventID, opts...); err != nil {
+		h.sendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// resolveLocation возвращает пояс tz, если он указан, иначе пояс пользователя
+func (h *APIHandler) resolveLocation(userID, tz string) (*time.Location, error) {
+	if tz != "" {
+		return domain.LoadLocation(tz)
+	}
+	return h.service.UserLocation(userID)
+}
+
+// decode разбирает JSON тело запроса и при ошибке отправляет 400
+func (h *APIHandler) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		sendAPIErrorCode(w, http.StatusBadRequest, codeMalformedRequest, "Request body must be a JSON object")
+		return false
+	}
+	return true
+}
+
+// sendError отправляет ошибку с кодом API; неизвестные ошибки логируются и возвращаются как 500.
+// Для события, отклоненного из-за пересечений, возвращаются и события, с которыми оно пересекается.
+func (h *APIHandler) sendError(w http.ResponseWriter, err error) {
+	var conflict *domain.ConflictError
+	if errors.As(err, &conflict) {
+		sendConflictError(w, conflict)
+		return
+	}
+	if status, code, ok := apiErrorCode(err); ok {
+		sendAPIErrorCode(w, status, code, err.Error())
+		return
+	}
+	h.logger.Log(logger.LevelError, "API request failed", map[string]interface{}{
+		"error": err.Error(),
+	})
+	sendAPIErrorCode(w, http.StatusInternalServerError, codeInternalError, "Internal server error")
+}
+
+// parseRange разбирает границы выборки [from, to). Даты без времени
+// интерпретируются в поясе loc, а to в формате YYYY-MM-DD включает этот день.
+func parseRange(fromStr, toStr string, loc *time.Location) (time.Time, time.Time, error) {
+	if fromStr == "" || toStr == "" {
+		return time.Time{}, time.Time{}, errInvalidRangeFormat
+	}
+	from, err := time.Parse(time.RFC3339, fromStr)
+	if err != nil {
+		if from, err = time.ParseInLocation("2006-01-02", fromStr, loc); err != nil {
+			return time.Time{}, time.Time{}, errInvalidRangeFormat
+		}
+	}
+	to, err := time.Parse(time.RFC3339, toStr)
+	if err != nil {
+		if to, err = time.ParseInLocation("2006-01-02", toStr, loc); err != nil {
+			return time.Time{}, time.Time{}, errInvalidRangeFormat
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, nil
+}
+
+// parseLimit разбирает размер страницы; пустая строка означает размер по умолчанию
+func parseLimit(limitStr string) (int, error) {
+	if limitStr == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return 0, errInvalidLimit
+	}
+	return limit, nil
+}
+
+// splitAPIPath возвращает раскодированные сегменты пути после APIPrefix
+func splitAPIPath(path string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(path, APIPrefix)
+	if !ok {
+		return nil, false
+	}
+	segments := strings.Split(rest, "/")
+	for i, segment := range segments {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil || decoded == "" {
+			return nil, false
+		}
+		segments[i] = decoded
+	}
+	return segments, true
+}
+
+func eventURL(userID, eventID string) string {
+	return APIPrefix + url.PathEscape(userID) + "/events/" + url.PathEscape(eventID)
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed ...string) {
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	sendAPIErrorCode(w, http.StatusMethodNotAllowed, codeMethodNotAllowed, "Method not allowed")
+}
+
+// zoneName возвращает имя пояса для хранения в событии (пустое для UTC)
+func zoneName(loc *time.Location) string {
+	if loc == time.UTC {
+		return ""
+	}
+	return loc.String()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
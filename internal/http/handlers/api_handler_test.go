@@ -0,0 +1,662 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/auth"
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Log(logger.LogLevel, string, map[string]interface{}) {}
+
+func (nopLogger) Close() error { return nil }
+
+func newTestAPIHandler() *APIHandler {
+	users := storage.NewMemoryUserRepository()
+	events := service.NewEventService(storage.NewMemoryRepository(), service.WithUserRepository(users),
+		service.WithShareRepository(storage.NewMemoryShareRepository()),
+		service.WithCalendarRepository(storage.NewMemoryCalendarRepository()),
+		service.WithInvitationRepository(storage.NewMemoryInvitationRepository()))
+	return NewAPIHandler(events, service.NewUserService(users), service.NewWebhookService(storage.NewMemoryWebhookRepository()),
+		service.NewAPIKeyService(storage.NewMemoryAPIKeyRepository()), nopLogger{})
+}
+
+// serveAPI выполняет запрос от имени пользователя из пути /api/v1/users/{user}/,
+// как если бы его уже аутентифицировал AuthMiddleware
+func serveAPI(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if rest, ok := strings.CutPrefix(req.URL.Path, APIPrefix); ok {
+		req = asUser(req, strings.SplitN(rest, "/", 2)[0])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// asUser добавляет в запрос аутентифицированного пользователя
+func asUser(req *http.Request, userID string) *http.Request {
+	return req.WithContext(auth.WithPrincipal(req.Context(), &auth.Principal{UserID: userID, Method: auth.MethodAPIKey}))
+}
+
+func decodeAPIError(t *testing.T, rec *httptest.ResponseRecorder) APIError {
+	t.Helper()
+	var resp struct {
+		Error APIError `json:"error"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode error response: %v", err)
+	}
+	return resp.Error
+}
+
+func TestAPIHandler_EventLifecycle(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Meeting","start":"2024-01-15T10:00:00Z","duration":"1h"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created EventDTO
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode event: %v", err)
+	}
+	location := rec.Header().Get("Location")
+	if location != "/api/v1/users/user1/events/"+created.ID {
+		t.Errorf("Unexpected Location %q", location)
+	}
+
+	rec = serveAPI(h, http.MethodGet, location, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodPatch, location, `{"event":"Renamed"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var patched EventDTO
+	json.NewDecoder(rec.Body).Decode(&patched)
+	if patched.Text != "Renamed" || patched.Start != created.Start || patched.End != created.End {
+		t.Errorf("PATCH should change only the text, got %+v", patched)
+	}
+
+	rec = serveAPI(h, http.MethodPut, location, `{"event":"Replaced","start":"2024-01-16T09:00:00Z"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var replaced EventDTO
+	json.NewDecoder(rec.Body).Decode(&replaced)
+	if replaced.End != replaced.Start {
+		t.Errorf("PUT without end should drop the duration, got %s - %s", replaced.Start, replaced.End)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/events?from=2024-01-16&to=2024-01-16", "")
+	var list struct {
+		Events []EventDTO `json:"events"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list.Events) != 1 {
+		t.Fatalf("Expected 1 event in range, got %d (%d)", len(list.Events), rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodDelete, location, "")
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodGet, location, "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %d", rec.Code)
+	}
+	if apiErr := decodeAPIError(t, rec); apiErr.Code != codeEventNotFound {
+		t.Errorf("Expected code %s, got %s", codeEventNotFound, apiErr.Code)
+	}
+}
+
+func TestAPIHandler_Errors(t *testing.T) {
+	h := newTestAPIHandler()
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCode   string
+	}{
+		{"empty text", http.MethodPost, "/api/v1/users/user1/events", `{"start":"2024-01-15T10:00:00Z"}`, http.StatusUnprocessableEntity, "invalid_text"},
+		{"missing start", http.MethodPost, "/api/v1/users/user1/events", `{"event":"Test"}`, http.StatusUnprocessableEntity, "start_required"},
+		{"bad rrule", http.MethodPost, "/api/v1/users/user1/events", `{"event":"Test","date":"2024-01-15","rrule":"FREQ=HOURLY"}`, http.StatusUnprocessableEntity, "invalid_recurrence"},
+		{"malformed body", http.MethodPost, "/api/v1/users/user1/events", `{`, http.StatusBadRequest, codeMalformedRequest},
+		{"missing range", http.MethodGet, "/api/v1/users/user1/events", "", http.StatusUnprocessableEntity, "invalid_range"},
+		{"empty range", http.MethodGet, "/api/v1/users/user1/events?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z", "", http.StatusUnprocessableEntity, "invalid_range"},
+		{"unknown event", http.MethodDelete, "/api/v1/users/user1/events/missing", "", http.StatusNotFound, codeEventNotFound},
+		{"unknown path", http.MethodGet, "/api/v1/users/user1/tasks", "", http.StatusNotFound, codeNotFound},
+		{"wrong method", http.MethodDelete, "/api/v1/users/user1/events", "", http.StatusMethodNotAllowed, codeMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveAPI(h, tt.method, tt.target, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body)
+			}
+			if apiErr := decodeAPIError(t, rec); apiErr.Code != tt.wantCode {
+				t.Errorf("Expected code %s, got %s", tt.wantCode, apiErr.Code)
+			}
+		})
+	}
+}
+
+func TestAPIHandler_SearchText(t *testing.T) {
+	h := newTestAPIHandler()
+	for _, body := range []string{
+		`{"event":"Dentist appointment","date":"2024-01-10"}`,
+		`{"event":"Запись к стоматологу","date":"2024-03-05"}`,
+		`{"event":"Team meeting","date":"2024-02-01"}`,
+	} {
+		if rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/events", body); rec.Code != http.StatusCreated {
+			t.Fatalf("Failed to create event: %d %s", rec.Code, rec.Body)
+		}
+	}
+
+	rec := serveAPI(h, http.MethodGet, "/api/v1/users/user1/search?q=стоматолог", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Results []SearchResultDTO `json:"results"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if len(resp.Results) != 1 || resp.Results[0].Event.Text != "Запись к стоматологу" || resp.Results[0].Score <= 0 {
+		t.Errorf("Unexpected search results %+v", resp.Results)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/search?q=+", "")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422 for empty query, got %d", rec.Code)
+	}
+	if apiErr := decodeAPIError(t, rec); apiErr.Code != "invalid_query" {
+		t.Errorf("Expected code invalid_query, got %s", apiErr.Code)
+	}
+
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/search", "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
+
+func TestAPIHandler_Import(t *testing.T) {
+	h := newTestAPIHandler()
+	calendar := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:a@ext\r\nDTSTART:20240115T100000Z\r\nDTEND:20240115T110000Z\r\nSUMMARY:Imported\r\n" +
+		"BEGIN:VALARM\r\nTRIGGER:-PT15M\r\nEND:VALARM\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:b@ext\r\nDTSTART:20240116T100000Z\r\nSUMMARY:Bad\r\nRRULE:FREQ=HOURLY\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	type importResponse struct {
+		Created int               `json:"created"`
+		Updated int               `json:"updated"`
+		Failed  int               `json:"failed"`
+		Results []ImportResultDTO `json:"results"`
+	}
+
+	req := asUser(httptest.NewRequest(http.MethodPost, "/api/v1/users/user1/import", strings.NewReader(calendar)), "user1")
+	req.Header.Set("Content-Type", "text/calendar")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp importResponse
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if resp.Created != 1 || resp.Failed != 1 || len(resp.Results) != 2 {
+		t.Fatalf("Unexpected import response %+v", resp)
+	}
+	if r := resp.Results[0]; r.UID != "a@ext" || r.Status != "created" || r.Event == nil || len(r.Event.Reminders) != 1 {
+		t.Errorf("Unexpected result %+v", r)
+	}
+	if r := resp.Results[1]; r.Status != "failed" || r.Error == nil || r.Error.Code != "invalid_recurrence" {
+		t.Errorf("Unexpected result %+v", r)
+	}
+
+	// Повторная загрузка файлом формы обновляет событие по UID
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "calendar.ics")
+	part.Write([]byte(calendar))
+	form.Close()
+	req = asUser(httptest.NewRequest(http.MethodPost, "/api/v1/users/user1/import", &body), "user1")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	resp = importResponse{}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	if rec.Code != http.StatusOK || resp.Created != 0 || resp.Updated != 1 {
+		t.Fatalf("Expected update on re-import, got %d %+v", rec.Code, resp)
+	}
+
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/import", "BEGIN:VCALENDAR\r\n")
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected 422 for malformed calendar, got %d", rec.Code)
+	}
+	if apiErr := decodeAPIError(t, rec); apiErr.Code != "invalid_calendar" {
+		t.Errorf("Expected code invalid_calendar, got %s", apiErr.Code)
+	}
+}
+
+func TestAPIHandler_Webhooks(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/webhooks",
+		`{"url":"https://example.com/hook","events":["created","deleted"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var created WebhookDTO
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.Secret == "" || len(created.Events) != 2 {
+		t.Fatalf("Expected generated secret and 2 events, got %+v", created)
+	}
+	if loc := rec.Header().Get("Location"); loc != "/api/v1/users/user1/webhooks/"+created.ID {
+		t.Errorf("Unexpected Location %q", loc)
+	}
+
+	// Секрет не возвращается после создания
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/webhooks/"+created.ID, "")
+	var fetched WebhookDTO
+	json.NewDecoder(rec.Body).Decode(&fetched)
+	if rec.Code != http.StatusOK || fetched.Secret != "" || fetched.URL != "https://example.com/hook" {
+		t.Errorf("Unexpected webhook %d %+v", rec.Code, fetched)
+	}
+
+	// Подписки другого пользователя недоступны
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user2/webhooks/"+created.ID+"/deliveries", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404, got %d", rec.Code)
+	}
+	if apiErr := decodeAPIError(t, rec); apiErr.Code != "webhook_not_found" {
+		t.Errorf("Expected code webhook_not_found, got %s", apiErr.Code)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/webhooks/"+created.ID+"/deliveries", "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for delivery history, got %d", rec.Code)
+	}
+
+	for body, code := range map[string]string{
+		`{"url":"ftp://example.com"}`:                         "invalid_webhook_url",
+		`{"url":"https://example.com","events":["reminder"]}`: "invalid_webhook_event",
+	} {
+		rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/webhooks", body)
+		if rec.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("Expected 422 for %s, got %d", body, rec.Code)
+		}
+		if apiErr := decodeAPIError(t, rec); apiErr.Code != code {
+			t.Errorf("Expected code %s, got %s", code, apiErr.Code)
+		}
+	}
+
+	if rec = serveAPI(h, http.MethodDelete, "/api/v1/users/user1/webhooks/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/webhooks", "")
+	var list struct {
+		Webhooks []WebhookDTO `json:"webhooks"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Webhooks) != 0 {
+		t.Errorf("Expected no webhooks after delete, got %+v", list.Webhooks)
+	}
+}
+
+func TestAPIHandler_Shares(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/alice/events", `{"event":"Project sync","date":"2024-01-15"}`)
+	var created EventDTO
+	json.NewDecoder(rec.Body).Decode(&created)
+
+	// Без доступа событие чужого календаря не находится
+	if rec := serveAPI(h, http.MethodGet, "/api/v1/users/bob/events/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("Expected 404 before sharing, got %d", rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodPut, "/api/v1/users/alice/shares/bob", `{"role":"read"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var share ShareDTO
+	json.NewDecoder(rec.Body).Decode(&share)
+	if share.OwnerID != "alice" || share.UserID != "bob" || share.Role != "read" {
+		t.Errorf("Unexpected share %+v", share)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/bob/events?from=2024-01-15&to=2024-01-15", "")
+	var list struct {
+		Events []EventDTO `json:"events"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Events) != 1 || list.Events[0].ID != created.ID || list.Events[0].UserID != "alice" {
+		t.Fatalf("Expected shared event tagged with its owner, got %+v", list.Events)
+	}
+
+	rec = serveAPI(h, http.MethodPatch, "/api/v1/users/bob/events/"+created.ID, `{"event":"Renamed"}`)
+	if rec.Code != http.StatusForbidden || decodeAPIError(t, rec).Code != "access_denied" {
+		t.Errorf("Expected 403 access_denied for read role, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/bob/events", `{"event":"Mine","date":"2024-01-15","owner":"alice"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 when creating in a read-only calendar, got %d", rec.Code)
+	}
+	if rec := serveAPI(h, http.MethodGet, "/api/v1/users/bob/shares?owner=alice", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for access list without manage role, got %d", rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodPut, "/api/v1/users/alice/shares/bob", `{"role":"write"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/bob/events", `{"event":"Planning","date":"2024-01-16","owner":"alice"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 in a writable calendar, got %d: %s", rec.Code, rec.Body)
+	}
+	var planning EventDTO
+	json.NewDecoder(rec.Body).Decode(&planning)
+	if planning.UserID != "alice" {
+		t.Errorf("Expected event in alice's calendar, got %s", planning.UserID)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/bob/shared", "")
+	var shared struct {
+		Shares []ShareDTO `json:"shares"`
+	}
+	json.NewDecoder(rec.Body).Decode(&shared)
+	if len(shared.Shares) != 1 || shared.Shares[0].OwnerID != "alice" || shared.Shares[0].Role != "write" {
+		t.Errorf("Unexpected shared calendars %+v", shared.Shares)
+	}
+
+	if rec := serveAPI(h, http.MethodPut, "/api/v1/users/alice/shares/bob", `{"role":"owner"}`); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for unknown role, got %d", rec.Code)
+	}
+	if rec := serveAPI(h, http.MethodDelete, "/api/v1/users/alice/shares/bob", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d", rec.Code)
+	}
+	if rec := serveAPI(h, http.MethodDelete, "/api/v1/users/alice/shares/bob", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing share, got %d", rec.Code)
+	}
+}
+
+func TestAPIHandler_Calendars(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/calendars",
+		`{"name":"Work","color":"#3366ff","time_zone":"Europe/Moscow","default_reminder":"15m"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var work CalendarDTO
+	json.NewDecoder(rec.Body).Decode(&work)
+	location := rec.Header().Get("Location")
+	if location != "/api/v1/users/user1/calendars/"+work.ID || work.DefaultReminder != "15m" {
+		t.Fatalf("Unexpected calendar %+v at %q", work, location)
+	}
+
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Standup","start":"2024-01-15T10:00:00+03:00","calendar_id":"`+work.ID+`"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var standup EventDTO
+	json.NewDecoder(rec.Body).Decode(&standup)
+	if standup.CalendarID != work.ID || standup.TimeZone != "Europe/Moscow" || len(standup.Reminders) != 1 {
+		t.Errorf("Expected calendar defaults to apply, got %+v", standup)
+	}
+	serveAPI(h, http.MethodPost, "/api/v1/users/user1/events", `{"event":"Gym","start":"2024-01-15T18:00:00Z"}`)
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/events?from=2024-01-15&to=2024-01-15&calendar_id="+work.ID, "")
+	var list struct {
+		Events []EventDTO `json:"events"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list.Events) != 1 || list.Events[0].ID != standup.ID {
+		t.Fatalf("Expected only the work event, got %+v (%d)", list.Events, rec.Code)
+	}
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/events?from=2024-01-15&to=2024-01-15&calendar_id=default,"+work.ID, "")
+	json.NewDecoder(rec.Body).Decode(&list)
+	if len(list.Events) != 2 {
+		t.Errorf("Expected events of both calendars, got %d", len(list.Events))
+	}
+
+	rec = serveAPI(h, http.MethodPut, location, `{"name":"Office","color":"red"}`)
+	if rec.Code != http.StatusUnprocessableEntity || decodeAPIError(t, rec).Code != "invalid_color" {
+		t.Errorf("Expected invalid_color, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodPut, location, `{"name":"Office"}`)
+	var renamed CalendarDTO
+	json.NewDecoder(rec.Body).Decode(&renamed)
+	if rec.Code != http.StatusOK || renamed.Name != "Office" || renamed.DefaultReminder != "" {
+		t.Errorf("Expected calendar to be replaced, got %+v (%d)", renamed, rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/calendars", "")
+	var calendars struct {
+		Calendars []CalendarDTO `json:"calendars"`
+	}
+	json.NewDecoder(rec.Body).Decode(&calendars)
+	if len(calendars.Calendars) != 1 {
+		t.Errorf("Expected 1 calendar, got %+v", calendars.Calendars)
+	}
+
+	if rec = serveAPI(h, http.MethodDelete, location, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/events/"+standup.ID, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected calendar events to be deleted, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodGet, location, "")
+	if rec.Code != http.StatusNotFound || decodeAPIError(t, rec).Code != "calendar_not_found" {
+		t.Errorf("Expected calendar_not_found, got %d", rec.Code)
+	}
+}
+
+func TestAPIHandler_Invitations(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Planning","start":"2024-01-15T10:00:00Z","end":"2024-01-15T11:00:00Z","attendees":["user2"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	var meeting EventDTO
+	json.NewDecoder(rec.Body).Decode(&meeting)
+	if len(meeting.Attendees) != 1 || meeting.Attendees[0].Status != "needs-action" {
+		t.Fatalf("Expected attendee awaiting response, got %+v", meeting.Attendees)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user2/invitations", "")
+	var list struct {
+		Events []EventDTO `json:"events"`
+	}
+	json.NewDecoder(rec.Body).Decode(&list)
+	if rec.Code != http.StatusOK || len(list.Events) != 1 || list.Events[0].ID != meeting.ID {
+		t.Fatalf("Expected the invitation, got %+v (%d)", list.Events, rec.Code)
+	}
+
+	rsvp := "/api/v1/users/user2/events/" + meeting.ID + "/rsvp"
+	rec = serveAPI(h, http.MethodPost, rsvp, `{"status":"maybe"}`)
+	if rec.Code != http.StatusUnprocessableEntity || decodeAPIError(t, rec).Code != "invalid_rsvp_status" {
+		t.Errorf("Expected invalid_rsvp_status, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodPost, rsvp, `{"status":"accepted"}`)
+	var accepted EventDTO
+	json.NewDecoder(rec.Body).Decode(&accepted)
+	if rec.Code != http.StatusOK || accepted.Attendees[0].Status != "accepted" || accepted.Attendees[0].RespondedAt == nil {
+		t.Errorf("Expected accepted invitation, got %+v (%d)", accepted.Attendees, rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user2/events/"+meeting.ID+"/propose",
+		`{"start":"2024-01-15T14:00:00Z","duration":"30m"}`)
+	var proposed EventDTO
+	json.NewDecoder(rec.Body).Decode(&proposed)
+	if rec.Code != http.StatusOK || proposed.Attendees[0].ProposedEnd == nil || *proposed.Attendees[0].ProposedEnd != "2024-01-15T14:30:00Z" {
+		t.Errorf("Expected proposed time, got %+v (%d)", proposed.Attendees, rec.Code)
+	}
+
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/events/"+meeting.ID+"/rsvp", `{"status":"accepted"}`)
+	if rec.Code != http.StatusForbidden || decodeAPIError(t, rec).Code != "not_attendee" {
+		t.Errorf("Expected not_attendee, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodGet, rsvp, "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Self","start":"2024-01-15T10:00:00Z","attendees":["user1"]}`)
+	if rec.Code != http.StatusUnprocessableEntity || decodeAPIError(t, rec).Code != "invalid_attendee" {
+		t.Errorf("Expected invalid_attendee, got %d", rec.Code)
+	}
+}
+
+func TestAPIHandler_Conflicts(t *testing.T) {
+	h := newTestAPIHandler()
+
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Meeting","start":"2024-01-15T10:00:00Z","end":"2024-01-15T11:00:00Z"}`)
+	var meeting SavedEventDTO
+	json.NewDecoder(rec.Body).Decode(&meeting)
+	if rec.Code != http.StatusCreated || len(meeting.Conflicts) != 0 {
+		t.Fatalf("Expected 201 without conflicts, got %d: %+v", rec.Code, meeting.Conflicts)
+	}
+
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/events?conflicts=reject",
+		`{"event":"Call","start":"2024-01-15T10:30:00Z","duration":"1h"}`)
+	var rejected struct {
+		Error     APIError   `json:"error"`
+		Conflicts []EventDTO `json:"conflicts"`
+	}
+	json.NewDecoder(rec.Body).Decode(&rejected)
+	if rec.Code != http.StatusConflict || rejected.Error.Code != "scheduling_conflict" ||
+		len(rejected.Conflicts) != 1 || rejected.Conflicts[0].ID != meeting.ID {
+		t.Fatalf("Expected 409 with the meeting, got %d: %+v", rec.Code, rejected)
+	}
+
+	// По умолчанию событие сохраняется, а пересечения возвращаются в ответе
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Call","start":"2024-01-15T10:30:00Z","duration":"1h"}`)
+	var call SavedEventDTO
+	json.NewDecoder(rec.Body).Decode(&call)
+	if rec.Code != http.StatusCreated || len(call.Conflicts) != 1 || call.Conflicts[0].ID != meeting.ID {
+		t.Fatalf("Expected 201 with the conflict, got %d: %+v", rec.Code, call.Conflicts)
+	}
+
+	rec = serveAPI(h, http.MethodPatch, "/api/v1/users/user1/events/"+call.ID+"?conflicts=allow",
+		`{"event":"Call with team"}`)
+	var patched SavedEventDTO
+	json.NewDecoder(rec.Body).Decode(&patched)
+	if rec.Code != http.StatusOK || patched.Text != "Call with team" || len(patched.Conflicts) != 0 {
+		t.Errorf("Expected conflicts not to be reported in allow mode, got %d: %+v", rec.Code, patched)
+	}
+	rec = serveAPI(h, http.MethodPut, "/api/v1/users/user1/events/"+call.ID+"?conflicts=reject",
+		`{"event":"Call","start":"2024-01-15T11:00:00Z","duration":"1h"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected moved event to be saved, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = serveAPI(h, http.MethodPut, "/api/v1/users/user1/events/"+call.ID+"?conflicts=ignore",
+		`{"event":"Call","start":"2024-01-15T11:00:00Z"}`)
+	if rec.Code != http.StatusUnprocessableEntity || decodeAPIError(t, rec).Code != "invalid_conflict_mode" {
+		t.Errorf("Expected invalid_conflict_mode, got %d", rec.Code)
+	}
+}
+
+func TestAPIHandler_FreeBusyAndSlots(t *testing.T) {
+	h := newTestAPIHandler()
+	serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Standup","start":"2024-01-15T09:00:00Z","end":"2024-01-15T10:00:00Z"}`)
+	serveAPI(h, http.MethodPost, "/api/v1/users/user2/events",
+		`{"event":"Review","start":"2024-01-15T09:30:00Z","end":"2024-01-15T11:00:00Z"}`)
+
+	rec := serveAPI(h, http.MethodGet, "/api/v1/users/user1/freebusy?users=user1,user2&from=2024-01-15&to=2024-01-15", "")
+	var freeBusy struct {
+		Users []FreeBusyDTO `json:"users"`
+	}
+	json.NewDecoder(rec.Body).Decode(&freeBusy)
+	if rec.Code != http.StatusOK || len(freeBusy.Users) != 2 {
+		t.Fatalf("Expected busy intervals of 2 users, got %d: %+v", rec.Code, freeBusy)
+	}
+	if busy := freeBusy.Users[1].Busy; len(busy) != 1 || busy[0].Start != "2024-01-15T09:30:00Z" || busy[0].End != "2024-01-15T11:00:00Z" {
+		t.Errorf("Unexpected busy intervals of user2: %+v", busy)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/slots?users=user2&from=2024-01-15&to=2024-01-15&duration=30m&limit=2", "")
+	var slots struct {
+		Slots []IntervalDTO `json:"slots"`
+	}
+	json.NewDecoder(rec.Body).Decode(&slots)
+	if rec.Code != http.StatusOK || len(slots.Slots) != 2 || slots.Slots[0].Start != "2024-01-15T11:00:00Z" || slots.Slots[1].Start != "2024-01-15T11:30:00Z" {
+		t.Errorf("Expected slots after both meetings, got %d: %+v", rec.Code, slots.Slots)
+	}
+
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/slots?from=2024-01-15&to=2024-01-15", "")
+	if rec.Code != http.StatusUnprocessableEntity || decodeAPIError(t, rec).Code != "invalid_duration" {
+		t.Errorf("Expected invalid_duration, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodGet, "/api/v1/users/user1/slots?from=2024-01-15&to=2024-01-15&duration=1h&limit=1000", "")
+	if rec.Code != http.StatusUnprocessableEntity || decodeAPIError(t, rec).Code != "invalid_freebusy_query" {
+		t.Errorf("Expected invalid_freebusy_query, got %d", rec.Code)
+	}
+	rec = serveAPI(h, http.MethodPost, "/api/v1/users/user1/freebusy", "")
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
+
+func TestAPIHandler_OccurrenceDay(t *testing.T) {
+	h := newTestAPIHandler()
+	h.users.UpdateUser(&domain.User{ID: "user1", TimeZone: "Europe/Moscow"})
+
+	// 01:30 по Москве - это 22:30 UTC предыдущего дня
+	rec := serveAPI(h, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Night shift","start":"2024-01-15T01:30:00+03:00","rrule":"FREQ=DAILY;COUNT=5"}`)
+	var series EventDTO
+	json.NewDecoder(rec.Body).Decode(&series)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body)
+	}
+	location := "/api/v1/users/user1/events/" + series.ID
+
+	rec = serveAPI(h, http.MethodPatch, location+"?scope=this&occurrence_date=2024-01-17", `{"event":"Moved"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var override EventDTO
+	json.NewDecoder(rec.Body).Decode(&override)
+	start, _ := time.Parse(time.RFC3339, override.Start)
+	if want := time.Date(2024, 1, 16, 22, 30, 0, 0, time.UTC); override.SeriesID != series.ID || !start.Equal(want) {
+		t.Errorf("Expected override of occurrence %v, got %+v", want, override)
+	}
+
+	rec = serveAPI(h, http.MethodDelete, location+"?scope=this&occurrence_date=2024-01-18", "")
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("Expected 204, got %d: %s", rec.Code, rec.Body)
+	}
+	rec = serveAPI(h, http.MethodDelete, location+"?scope=this&occurrence_date=2024-01-25", "")
+	if rec.Code != http.StatusNotFound || decodeAPIError(t, rec).Code != codeOccurrenceNotFound {
+		t.Errorf("Expected occurrence_not_found, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/ical"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+const (
+	// maxImportSize ограничивает размер загружаемого календаря
+	maxImportSize = 10 << 20
+	// importFormField - поле формы multipart/form-data с файлом календаря
+	importFormField = "file"
+)
+
+// Итоги импорта одного события
+const (
+	importCreated = "created"
+	importUpdated = "updated"
+	importFailed  = "failed"
+)
+
+// ImportResultDTO представляет итог импорта одного VEVENT
+type ImportResultDTO struct {
+	UID    string    `json:"uid,omitempty"`
+	Status string    `json:"status"`
+	Event  *EventDTO `json:"event,omitempty"`
+	Error  *APIError `json:"error,omitempty"`
+}
+
+// importCalendar handles POST /api/v1/users/{user}/import.
+// Календарь передается телом запроса (text/calendar) или файлом в поле file
+// формы multipart/form-data. Ответ содержит итог по каждому VEVENT в порядке файла.
+func (h *APIHandler) importCalendar(w http.ResponseWriter, r *http.Request, userID string) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	body, err := importBody(r)
+	if err != nil {
+		if isTooLarge(err) {
+			sendAPIErrorCode(w, http.StatusRequestEntityTooLarge, codeRequestTooLarge, "Calendar file is too large")
+			return
+		}
+		sendAPIErrorCode(w, http.StatusBadRequest, codeMalformedRequest, "Form field file with the calendar is required")
+		return
+	}
+	defer body.Close()
+
+	loc, err := h.service.UserLocation(userID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	items, err := ical.Decode(body, loc)
+	if err != nil {
+		if isTooLarge(err) {
+			sendAPIErrorCode(w, http.StatusRequestEntityTooLarge, codeRequestTooLarge, "Calendar file is too large")
+			return
+		}
+		h.sendError(w, err)
+		return
+	}
+
+	results := make([]ImportResultDTO, len(items))
+	var valid []int
+	for i, item := range items {
+		results[i].UID = item.UID
+		if item.Err != nil {
+			results[i].Status = importFailed
+			results[i].Error = h.itemError(item.Err)
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	events := make([]*domain.Event, len(valid))
+	for j, i := range valid {
+		events[j] = items[i].Event
+	}
+	imported, err := h.service.ImportEvents(userID, events)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	for j, i := range valid {
+		result := imported[j]
+		if result.Err != nil {
+			results[i].Status = importFailed
+			results[i].Error = h.itemError(result.Err)
+			continue
+		}
+		results[i].Status = importUpdated
+		if result.Created {
+			results[i].Status = importCreated
+		}
+		dto := eventToDTO(result.Event)
+		results[i].Event = &dto
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"created": counts[importCreated],
+		"updated": counts[importUpdated],
+		"failed":  counts[importFailed],
+		"results": results,
+	})
+}
+
+// importBody возвращает содержимое календаря из тела запроса или файла формы
+func importBody(r *http.Request) (io.ReadCloser, error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, nil
+	}
+	file, _, err := r.FormFile(importFormField)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func isTooLarge(err error) bool {
+	var tooLarge *http.MaxBytesError
+	return errors.As(err, &tooLarge)
+}
+
+// itemError преобразует ошибку импорта события в ошибку API;
+// неизвестные ошибки логируются и скрываются
+func (h *APIHandler) itemError(err error) *APIError {
+	if _, code, ok := apiErrorCode(err); ok {
+		return &APIError{Code: code, Message: err.Error()}
+	}
+	h.logger.Log(logger.LevelError, "Failed to import event", map[string]interface{}{
+		"error": err.Error(),
+	})
+	return &APIError{Code: codeInternalError, Message: "Internal server error"}
+}
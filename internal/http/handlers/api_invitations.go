@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// AttendeeDTO представляет участника события и его ответ на приглашение
+type AttendeeDTO struct {
+	UserID        string  `json:"user_id"`
+	Status        string  `json:"status"`
+	ProposedStart *string `json:"proposed_start,omitempty"`
+	ProposedEnd   *string `json:"proposed_end,omitempty"`
+	RespondedAt   *string `json:"responded_at,omitempty"`
+}
+
+// RSVPRequest содержит ответ на приглашение: accepted, declined или tentative
+type RSVPRequest struct {
+	Status string `json:"status"`
+}
+
+// ProposeTimeRequest содержит предложенное время события. Окончание задается
+// через end или duration; без них сохраняется прежняя длительность.
+type ProposeTimeRequest struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Duration string `json:"duration"`
+}
+
+// serveInvitationResponse разбирает путь /api/v1/users/{user}/events/{id}/{rsvp|propose}
+func (h *APIHandler) serveInvitationResponse(w http.ResponseWriter, r *http.Request, userID, eventID, action string) {
+	if action != "rsvp" && action != "propose" {
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+	if action == "rsvp" {
+		h.respondToInvitation(w, r, userID, eventID)
+	} else {
+		h.proposeTime(w, r, userID, eventID)
+	}
+}
+
+// respondToInvitation handles POST /api/v1/users/{user}/events/{id}/rsvp
+func (h *APIHandler) respondToInvitation(w http.ResponseWriter, r *http.Request, userID, eventID string) {
+	var req RSVPRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	status, err := domain.ParsePartStat(req.Status)
+	if err == nil && status == domain.PartStatNeedsAction {
+		err = domain.ErrInvalidPartStat
+	}
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	event, err := h.service.RespondToInvitation(userID, eventID, status)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, eventToDTO(event))
+}
+
+// proposeTime handles POST /api/v1/users/{user}/events/{id}/propose
+func (h *APIHandler) proposeTime(w http.ResponseWriter, r *http.Request, userID, eventID string) {
+	var req ProposeTimeRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	start, end, err := parseProposal(&req)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	event, err := h.service.ProposeTime(userID, eventID, start, end)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, eventToDTO(event))
+}
+
+// parseProposal разбирает начало и окончание предложенного времени
+func parseProposal(req *ProposeTimeRequest) (time.Time, time.Time, error) {
+	start, err := time.Parse(time.RFC3339, req.Start)
+	if err != nil {
+		return time.Time{}, time.Time{}, errInvalidStartFormat
+	}
+	if req.End != "" && req.Duration != "" {
+		return time.Time{}, time.Time{}, errEndAndDuration
+	}
+	if req.End != "" {
+		end, err := time.Parse(time.RFC3339, req.End)
+		if err != nil {
+			return time.Time{}, time.Time{}, errInvalidEndFormat
+		}
+		return start, end, nil
+	}
+	if req.Duration != "" {
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil || d <= 0 {
+			return time.Time{}, time.Time{}, errInvalidDuration
+		}
+		return start, start.Add(d), nil
+	}
+	return start, time.Time{}, nil
+}
+
+// listInvitations handles GET /api/v1/users/{user}/invitations:
+// события других пользователей, на которые приглашен пользователь
+func (h *APIHandler) listInvitations(w http.ResponseWriter, userID string) {
+	events, err := h.service.Invitations(userID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"events": eventsToDTO(events),
+	})
+}
+
+func attendeesToDTO(attendees []domain.Attendee, loc *time.Location) []AttendeeDTO {
+	if len(attendees) == 0 {
+		return nil
+	}
+	dtos := make([]AttendeeDTO, len(attendees))
+	for i, attendee := range attendees {
+		dtos[i] = AttendeeDTO{UserID: attendee.UserID, Status: string(attendee.Status)}
+		if attendee.Proposal != nil {
+			start := attendee.Proposal.Start.In(loc).Format(time.RFC3339)
+			dtos[i].ProposedStart = &start
+			if !attendee.Proposal.End.IsZero() {
+				end := attendee.Proposal.End.In(loc).Format(time.RFC3339)
+				dtos[i].ProposedEnd = &end
+			}
+		}
+		if attendee.RespondedAt != nil {
+			respondedAt := attendee.RespondedAt.Format(time.RFC3339)
+			dtos[i].RespondedAt = &respondedAt
+		}
+	}
+	return dtos
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// CreateAPIKeyRequest описывает новый ключ API
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+}
+
+// APIKeyDTO представляет ключ API. Сам ключ возвращается только при создании.
+type APIKeyDTO struct {
+	ID        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	Key       string `json:"key,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// serveAPIKeys разбирает путь /api/v1/users/{user}/api_keys[/{id}]
+func (h *APIHandler) serveAPIKeys(w http.ResponseWriter, r *http.Request, userID string, rest []string) {
+	switch len(rest) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			h.listAPIKeys(w, userID)
+		case http.MethodPost:
+			h.createAPIKey(w, r, userID)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	case 1:
+		if r.Method != http.MethodDelete {
+			methodNotAllowed(w, http.MethodDelete)
+			return
+		}
+		if err := h.apiKeys.Revoke(userID, rest[0]); err != nil {
+			h.sendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+	}
+}
+
+// createAPIKey handles POST /api/v1/users/{user}/api_keys
+func (h *APIHandler) createAPIKey(w http.ResponseWriter, r *http.Request, userID string) {
+	var req CreateAPIKeyRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	key, plain, err := h.apiKeys.Create(userID, req.Name)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	dto := apiKeyToDTO(key)
+	dto.Key = plain
+	sendJSON(w, http.StatusCreated, dto)
+}
+
+// listAPIKeys handles GET /api/v1/users/{user}/api_keys
+func (h *APIHandler) listAPIKeys(w http.ResponseWriter, userID string) {
+	keys, err := h.apiKeys.List(userID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	dtos := make([]APIKeyDTO, len(keys))
+	for i, key := range keys {
+		dtos[i] = apiKeyToDTO(key)
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"api_keys": dtos,
+	})
+}
+
+func apiKeyToDTO(key *domain.APIKey) APIKeyDTO {
+	return APIKeyDTO{
+		ID:        key.ID,
+		Name:      key.Name,
+		CreatedAt: key.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// ShareCalendarRequest задает роль пользователя в календаре
+type ShareCalendarRequest struct {
+	Role string `json:"role"`
+}
+
+// ShareDTO представляет запись списка доступа к календарю
+type ShareDTO struct {
+	OwnerID   string `json:"owner_id"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// serveShares разбирает путь /api/v1/users/{user}/shares[/{grantee}].
+// Параметр owner выбирает календарь другого пользователя, в котором у {user}
+// есть роль manage; по умолчанию используется календарь самого пользователя.
+func (h *APIHandler) serveShares(w http.ResponseWriter, r *http.Request, userID string, rest []string) {
+	ownerID := r.URL.Query().Get("owner")
+	if ownerID == "" {
+		ownerID = userID
+	}
+
+	switch len(rest) {
+	case 0:
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.listShares(w, userID, ownerID)
+	case 1:
+		switch r.Method {
+		case http.MethodPut:
+			h.shareCalendar(w, r, userID, ownerID, rest[0])
+		case http.MethodDelete:
+			if err := h.service.UnshareCalendar(userID, ownerID, rest[0]); err != nil {
+				h.sendError(w, err)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			methodNotAllowed(w, http.MethodPut, http.MethodDelete)
+		}
+	default:
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+	}
+}
+
+// listShares handles GET /api/v1/users/{user}/shares
+func (h *APIHandler) listShares(w http.ResponseWriter, userID, ownerID string) {
+	shares, err := h.service.CalendarShares(userID, ownerID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"shares": sharesToDTO(shares),
+	})
+}
+
+// shareCalendar handles PUT /api/v1/users/{user}/shares/{grantee}
+func (h *APIHandler) shareCalendar(w http.ResponseWriter, r *http.Request, userID, ownerID, granteeID string) {
+	var req ShareCalendarRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+	role, err := domain.ParseRole(req.Role)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	share, err := h.service.ShareCalendar(userID, ownerID, granteeID, role)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, shareToDTO(share))
+}
+
+// listSharedCalendars handles GET /api/v1/users/{user}/shared:
+// календари других пользователей, открытые пользователю
+func (h *APIHandler) listSharedCalendars(w http.ResponseWriter, userID string) {
+	shares, err := h.service.SharedCalendars(userID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"shares": sharesToDTO(shares),
+	})
+}
+
+func sharesToDTO(shares []*domain.Share) []ShareDTO {
+	dtos := make([]ShareDTO, len(shares))
+	for i, share := range shares {
+		dtos[i] = shareToDTO(share)
+	}
+	return dtos
+}
+
+func shareToDTO(share *domain.Share) ShareDTO {
+	return ShareDTO{
+		OwnerID:   share.OwnerID,
+		UserID:    share.GranteeID,
+		Role:      string(share.Role),
+		CreatedAt: share.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: share.UpdatedAt.Format(time.RFC3339),
+	}
+}
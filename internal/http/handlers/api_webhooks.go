@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// CreateWebhookRequest описывает новую подписку на изменения событий.
+// Пустой events означает все изменения, пустой secret - создать секрет на сервере.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+// WebhookDTO представляет подписку на webhook. Секрет возвращается
+// только при создании подписки.
+type WebhookDTO struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Secret    string   `json:"secret,omitempty"`
+	CreatedAt string   `json:"created_at"`
+}
+
+// WebhookDeliveryDTO представляет одну попытку доставки сообщения
+type WebhookDeliveryDTO struct {
+	MessageID  string `json:"message_id"`
+	Type       string `json:"type"`
+	EventID    string `json:"event_id"`
+	Attempt    int    `json:"attempt"`
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+	At         string `json:"at"`
+}
+
+// serveWebhooks разбирает путь /api/v1/users/{user}/webhooks[/{id}[/deliveries]]
+func (h *APIHandler) serveWebhooks(w http.ResponseWriter, r *http.Request, userID string, rest []string) {
+	switch {
+	case len(rest) == 0:
+		switch r.Method {
+		case http.MethodGet:
+			h.listWebhooks(w, userID)
+		case http.MethodPost:
+			h.createWebhook(w, r, userID)
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodPost)
+		}
+	case len(rest) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			h.getWebhook(w, userID, rest[0])
+		case http.MethodDelete:
+			h.deleteWebhook(w, userID, rest[0])
+		default:
+			methodNotAllowed(w, http.MethodGet, http.MethodDelete)
+		}
+	case len(rest) == 2 && rest[1] == "deliveries":
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, http.MethodGet)
+			return
+		}
+		h.listWebhookDeliveries(w, userID, rest[0])
+	default:
+		sendAPIErrorCode(w, http.StatusNotFound, codeNotFound, "Resource not found")
+	}
+}
+
+// createWebhook handles POST /api/v1/users/{user}/webhooks
+func (h *APIHandler) createWebhook(w http.ResponseWriter, r *http.Request, userID string) {
+	var req CreateWebhookRequest
+	if !h.decode(w, r, &req) {
+		return
+	}
+
+	types := make([]domain.ChangeType, len(req.Events))
+	for i, event := range req.Events {
+		types[i] = domain.ChangeType(event)
+	}
+	sub, err := h.webhooks.Subscribe(userID, req.URL, req.Secret, types)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+
+	dto := webhookToDTO(sub)
+	dto.Secret = sub.Secret
+	w.Header().Set("Location", webhookURL(userID, sub.ID))
+	sendJSON(w, http.StatusCreated, dto)
+}
+
+// listWebhooks handles GET /api/v1/users/{user}/webhooks
+func (h *APIHandler) listWebhooks(w http.ResponseWriter, userID string) {
+	subs, err := h.webhooks.List(userID)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	dtos := make([]WebhookDTO, len(subs))
+	for i, sub := range subs {
+		dtos[i] = webhookToDTO(sub)
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"webhooks": dtos,
+	})
+}
+
+// getWebhook handles GET /api/v1/users/{user}/webhooks/{id}
+func (h *APIHandler) getWebhook(w http.ResponseWriter, userID, id string) {
+	sub, err := h.webhooks.Get(userID, id)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	sendJSON(w, http.StatusOK, webhookToDTO(sub))
+}
+
+// deleteWebhook handles DELETE /api/v1/users/{user}/webhooks/{id}
+func (h *APIHandler) deleteWebhook(w http.ResponseWriter, userID, id string) {
+	if err := h.webhooks.Unsubscribe(userID, id); err != nil {
+		h.sendError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listWebhookDeliveries handles GET /api/v1/users/{user}/webhooks/{id}/deliveries.
+// Попытки возвращаются начиная с последних.
+func (h *APIHandler) listWebhookDeliveries(w http.ResponseWriter, userID, id string) {
+	deliveries, err := h.webhooks.Deliveries(userID, id)
+	if err != nil {
+		h.sendError(w, err)
+		return
+	}
+	dtos := make([]WebhookDeliveryDTO, len(deliveries))
+	for i, d := range deliveries {
+		dtos[i] = WebhookDeliveryDTO{
+			MessageID:  d.MessageID,
+			Type:       string(d.Type),
+			EventID:    d.EventID,
+			Attempt:    d.Attempt,
+			Success:    d.Succeeded(),
+			StatusCode: d.StatusCode,
+			Error:      d.Error,
+			DurationMs: d.Duration.Milliseconds(),
+			At:         d.At.Format(time.RFC3339),
+		}
+	}
+	sendJSON(w, http.StatusOK, map[string]interface{}{
+		"deliveries": dtos,
+	})
+}
+
+func webhookToDTO(sub *domain.WebhookSubscription) WebhookDTO {
+	types := sub.Types
+	if len(types) == 0 {
+		types = domain.WebhookEventTypes
+	}
+	events := make([]string, len(types))
+	for i, t := range types {
+		events[i] = string(t)
+	}
+	return WebhookDTO{
+		ID:        sub.ID,
+		URL:       sub.URL,
+		Events:    events,
+		CreatedAt: sub.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func webhookURL(userID, id string) string {
+	return APIPrefix + url.PathEscape(userID) + "/webhooks/" + url.PathEscape(id)
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/oziev02/event-calendar-service/internal/auth"
+)
+
+var (
+	errUnauthenticated = errors.New("authentication required")
+	errUserMismatch    = errors.New("user_id does not match the authenticated user")
+)
+
+// requestUser возвращает пользователя запроса из учетных данных.
+// claimed - user_id из тела, строки запроса или пути; пустое значение означает
+// аутентифицированного пользователя, а чужое значение отклоняется.
+func requestUser(r *http.Request, claimed string) (string, error) {
+	principal, ok := auth.FromContext(r.Context())
+	if !ok {
+		return "", errUnauthenticated
+	}
+	if claimed != "" && claimed != principal.UserID {
+		return "", errUserMismatch
+	}
+	return principal.UserID, nil
+}
+
+// sendUserError отправляет ошибку requestUser в формате прежних маршрутов
+func sendUserError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errUserMismatch) {
+		sendError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	sendError(w, "Unauthorized", http.StatusUnauthorized)
+}
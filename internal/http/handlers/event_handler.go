@@ -1,9 +1,9 @@
 package handlers
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/oziev02/event-calendar-service/internal/domain"
@@ -13,21 +13,18 @@ import (
 
 // EventHandler обрабатывает HTTP запросы для событий
 type EventHandler struct {
-	service    *service.EventService
-	logger     logger.Logger
-	reminderChan chan *domain.ReminderTask
+	service *service.EventService
+	logger  logger.Logger
 }
 
 // NewEventHandler создает новый обработчик событий
 func NewEventHandler(
 	service *service.EventService,
 	log logger.Logger,
-	reminderChan chan *domain.ReminderTask,
 ) *EventHandler {
 	return &EventHandler{
-		service:      service,
-		logger:       log,
-		reminderChan: reminderChan,
+		service: service,
+		logger:  log,
 	}
 }
 
@@ -39,53 +36,50 @@ func (h *EventHandler) CreateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req CreateEventRequest
-	if err := h.decodeRequest(r, &req); err != nil {
-		h.sendError(w, "Invalid request format", http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		sendError(w, "Invalid request format", http.StatusBadRequest)
 		return
 	}
-
-	date, err := time.Parse("2006-01-02", req.Date)
+	userID, err := requestUser(r, req.UserID)
 	if err != nil {
-		h.sendError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		sendUserError(w, err)
 		return
 	}
 
-	var reminderTime *time.Time
-	if req.ReminderTime != "" {
-		rt, err := time.Parse(time.RFC3339, req.ReminderTime)
-		if err != nil {
-			h.sendError(w, "Invalid reminder time format. Use RFC3339", http.StatusBadRequest)
-			return
-		}
-		reminderTime = &rt
+	loc, err := h.resolveLocation(userID, req.TimeZone)
+	if err != nil {
+		h.sendLocationError(w, err)
+		return
 	}
 
-	event, err := h.service.CreateEvent(req.UserID, req.Event, date, reminderTime)
+	input, err := parseEventFields(req.fields(), loc)
 	if err != nil {
-		if errors.Is(err, domain.ErrInvalidDate) || errors.Is(err, domain.ErrInvalidUserID) || errors.Is(err, domain.ErrInvalidEventText) {
-			h.sendError(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-		h.sendError(w, "Failed to create event", http.StatusInternalServerError)
+		sendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Запланировать напоминание, если указано
-	if reminderTime != nil {
-		task := &domain.ReminderTask{
-			EventID: event.ID,
-			UserID:  event.UserID,
-			Text:    event.Text,
-			Time:    *reminderTime,
+	if req.Owner != "" {
+		input.opts = append(input.opts, service.WithOwner(req.Owner))
+	}
+	if req.CalendarID != "" {
+		input.opts = append(input.opts, service.WithCalendar(req.CalendarID))
+	}
+
+	event, err := h.service.CreateEvent(userID, req.Event, input.start, input.reminderTime, input.opts...)
+	if err != nil {
+		if errors.Is(err, domain.ErrAccessDenied) {
+			sendError(w, err.Error(), http.StatusForbidden)
+			return
 		}
-		select {
-		case h.reminderChan <- task:
-		default:
-			h.logger.Log(logger.LevelError, "Reminder channel full", nil)
+		if isValidationError(err) {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		sendError(w, "Failed to create event", http.StatusInternalServerError)
+		return
 	}
 
-	h.sendSuccess(w, map[string]interface{}{
+	sendSuccess(w, map[string]interface{}{
 		"event_id": event.ID,
 		"message":  "Event created successfully",
 	})
@@ -99,57 +93,60 @@ func (h *EventHandler) UpdateEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req UpdateEventRequest
-	if err := h.decodeRequest(r, &req); err != nil {
-		h.sendError(w, "Invalid request format", http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		sendError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	userID, err := requestUser(r, req.UserID)
+	if err != nil {
+		sendUserError(w, err)
 		return
 	}
 
-	date, err := time.Parse("2006-01-02", req.Date)
+	loc, err := h.resolveLocation(userID, req.TimeZone)
 	if err != nil {
-		h.sendError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		h.sendLocationError(w, err)
 		return
 	}
 
-	var reminderTime *time.Time
-	if req.ReminderTime != "" {
-		rt, err := time.Parse(time.RFC3339, req.ReminderTime)
-		if err != nil {
-			h.sendError(w, "Invalid reminder time format. Use RFC3339", http.StatusBadRequest)
-			return
-		}
-		reminderTime = &rt
+	input, err := parseEventFields(req.fields(), loc)
+	if err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opts := input.opts
+	if req.CalendarID != "" {
+		opts = append(opts, service.WithCalendar(req.CalendarID))
 	}
 
-	event, err := h.service.UpdateEvent(req.UserID, req.EventID, req.Event, date, reminderTime)
+	scopeOpt, _, err := parseScope(h.service, userID, req.EventID, req.Scope, req.OccurrenceDate)
+	if err != nil {
+		sendScopeError(w, err)
+		return
+	}
+	if scopeOpt != nil {
+		opts = append(opts, scopeOpt)
+	}
+
+	_, err = h.service.UpdateEvent(userID, req.EventID, req.Event, input.start, input.reminderTime, opts...)
 	if err != nil {
 		if errors.Is(err, domain.ErrEventNotFound) {
-			h.sendError(w, err.Error(), http.StatusServiceUnavailable)
+			sendError(w, err.Error(), http.StatusNotFound)
 			return
 		}
-		if errors.Is(err, domain.ErrInvalidDate) || errors.Is(err, domain.ErrInvalidUserID) || errors.Is(err, domain.ErrInvalidEventText) {
-			h.sendError(w, err.Error(), http.StatusBadRequest)
+		if errors.Is(err, domain.ErrAccessDenied) {
+			sendError(w, err.Error(), http.StatusForbidden)
 			return
 		}
-		h.sendError(w, "Failed to update event", http.StatusInternalServerError)
-		return
-	}
-
-	// Переназначить напоминание, если указано
-	if reminderTime != nil {
-		task := &domain.ReminderTask{
-			EventID: event.ID,
-			UserID:  event.UserID,
-			Text:    event.Text,
-			Time:    *reminderTime,
-		}
-		select {
-		case h.reminderChan <- task:
-		default:
-			h.logger.Log(logger.LevelError, "Reminder channel full", nil)
+		if isValidationError(err) {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
 		}
+		sendError(w, "Failed to update event", http.StatusInternalServerError)
+		return
 	}
 
-	h.sendSuccess(w, map[string]interface{}{
+	sendSuccess(w, map[string]interface{}{
 		"message": "Event updated successfully",
 	})
 }
@@ -162,187 +159,467 @@ func (h *EventHandler) DeleteEvent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req DeleteEventRequest
-	if err := h.decodeRequest(r, &req); err != nil {
-		h.sendError(w, "Invalid request format", http.StatusBadRequest)
+	if err := decodeRequest(r, &req); err != nil {
+		sendError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+	userID, err := requestUser(r, req.UserID)
+	if err != nil {
+		sendUserError(w, err)
 		return
 	}
 
-	err := h.service.DeleteEvent(req.UserID, req.EventID)
+	var opts []service.EventOption
+	scopeOpt, _, err := parseScope(h.service, userID, req.EventID, req.Scope, req.OccurrenceDate)
+	if err != nil {
+		sendScopeError(w, err)
+		return
+	}
+	if scopeOpt != nil {
+		opts = append(opts, scopeOpt)
+	}
+
+	err = h.service.DeleteEvent(userID, req.EventID, opts...)
 	if err != nil {
 		if errors.Is(err, domain.ErrEventNotFound) {
-			h.sendError(w, err.Error(), http.StatusServiceUnavailable)
+			sendError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrAccessDenied) {
+			sendError(w, err.Error(), http.StatusForbidden)
 			return
 		}
-		h.sendError(w, "Failed to delete event", http.StatusInternalServerError)
+		sendError(w, "Failed to delete event", http.StatusInternalServerError)
 		return
 	}
 
-	h.sendSuccess(w, map[string]interface{}{
+	sendSuccess(w, map[string]interface{}{
 		"message": "Event deleted successfully",
 	})
 }
 
 // GetEventsForDay handles GET /events_for_day
 func (h *EventHandler) GetEventsForDay(w http.ResponseWriter, r *http.Request) {
+	h.getEventsForPeriod(w, r, h.service.GetEventsForDay)
+}
+
+// GetEventsForWeek handles GET /events_for_week
+func (h *EventHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
+	h.getEventsForPeriod(w, r, h.service.GetEventsForWeek)
+}
+
+// GetEventsForMonth handles GET /events_for_month
+func (h *EventHandler) GetEventsForMonth(w http.ResponseWriter, r *http.Request) {
+	h.getEventsForPeriod(w, r, h.service.GetEventsForMonth)
+}
+
+// getEventsForPeriod разбирает date и tz и возвращает события пользователя за период.
+// Дата интерпретируется в поясе tz, а без него - в поясе пользователя.
+// Необязательный user_id должен совпадать с аутентифицированным пользователем,
+// а calendar_id ограничивает выборку одним или несколькими календарями.
+func (h *EventHandler) getEventsForPeriod(
+	w http.ResponseWriter,
+	r *http.Request,
+	period func(userID string, date time.Time, calendarIDs ...string) ([]*domain.Event, error),
+) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	userID := r.URL.Query().Get("user_id")
+	userID, err := requestUser(r, r.URL.Query().Get("user_id"))
+	if err != nil {
+		sendUserError(w, err)
+		return
+	}
 	dateStr := r.URL.Query().Get("date")
+	if dateStr == "" {
+		sendError(w, "date is required", http.StatusBadRequest)
+		return
+	}
 
-	if userID == "" || dateStr == "" {
-		h.sendError(w, "user_id and date are required", http.StatusBadRequest)
+	loc, err := h.resolveLocation(userID, r.URL.Query().Get("tz"))
+	if err != nil {
+		h.sendLocationError(w, err)
 		return
 	}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	date, err := time.ParseInLocation("2006-01-02", dateStr, loc)
 	if err != nil {
-		h.sendError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		sendError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
 		return
 	}
 
-	events, err := h.service.GetEventsForDay(userID, date)
+	events, err := period(userID, date, parseIDList(r.URL.Query()["calendar_id"])...)
 	if err != nil {
-		h.sendError(w, "Failed to get events", http.StatusInternalServerError)
+		sendError(w, "Failed to get events", http.StatusInternalServerError)
 		return
 	}
 
-	h.sendSuccess(w, map[string]interface{}{
+	sendSuccess(w, map[string]interface{}{
 		"events": eventsToDTO(events),
 	})
 }
 
-// GetEventsForWeek handles GET /events_for_week
-func (h *EventHandler) GetEventsForWeek(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+// resolveLocation возвращает пояс tz, если он указан, иначе пояс пользователя
+func (h *EventHandler) resolveLocation(userID, tz string) (*time.Location, error) {
+	if tz != "" {
+		return domain.LoadLocation(tz)
 	}
+	return h.service.UserLocation(userID)
+}
 
-	userID := r.URL.Query().Get("user_id")
-	dateStr := r.URL.Query().Get("date")
-
-	if userID == "" || dateStr == "" {
-		h.sendError(w, "user_id and date are required", http.StatusBadRequest)
+func (h *EventHandler) sendLocationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrInvalidTimeZone) {
+		sendError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	sendError(w, "Failed to resolve time zone", http.StatusInternalServerError)
+}
 
-	date, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		h.sendError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
-		return
+// Request/Response types
+type CreateEventRequest struct {
+	UserID       string   `json:"user_id" form:"user_id"`
+	Date         string   `json:"date,omitempty" form:"date"`   // событие на весь день
+	Start        string   `json:"start,omitempty" form:"start"` // событие со временем, RFC3339
+	End          string   `json:"end,omitempty" form:"end"`
+	Duration     string   `json:"duration,omitempty" form:"duration"`
+	AllDay       bool     `json:"all_day,omitempty" form:"all_day"`
+	TimeZone     string   `json:"time_zone,omitempty" form:"time_zone"` // по умолчанию пояс пользователя
+	Event        string   `json:"event" form:"event"`
+	ReminderTime string   `json:"reminder_time,omitempty" form:"reminder_time"`
+	Reminders    []string `json:"reminders,omitempty" form:"reminders"` // 15m, 1d или RFC3339
+	RRule        string   `json:"rrule,omitempty" form:"rrule"`
+	// Owner - владелец открытого на запись календаря, в котором создается событие;
+	// по умолчанию событие создается в календаре пользователя
+	Owner string `json:"owner,omitempty" form:"owner"`
+	// CalendarID - именованный календарь владельца; по умолчанию календарь по умолчанию
+	CalendarID string `json:"calendar_id,omitempty" form:"calendar_id"`
+	// Attendees - пользователи, приглашенные на событие
+	Attendees []string `json:"attendees,omitempty" form:"attendees"`
+}
+
+type UpdateEventRequest struct {
+	UserID       string   `json:"user_id" form:"user_id"`
+	EventID      string   `json:"event_id" form:"event_id"`
+	Date         string   `json:"date,omitempty" form:"date"`
+	Start        string   `json:"start,omitempty" form:"start"`
+	End          string   `json:"end,omitempty" form:"end"` // пустые end и duration сохраняют длительность
+	Duration     string   `json:"duration,omitempty" form:"duration"`
+	AllDay       bool     `json:"all_day,omitempty" form:"all_day"`
+	TimeZone     string   `json:"time_zone,omitempty" form:"time_zone"` // пустое значение сохраняет текущий пояс
+	Event        string   `json:"event" form:"event"`
+	ReminderTime string   `json:"reminder_time,omitempty" form:"reminder_time"`
+	Reminders    []string `json:"reminders,omitempty" form:"reminders"` // 15m, 1d или RFC3339
+	RRule        string   `json:"rrule,omitempty" form:"rrule"`         // пустое значение сохраняет текущее правило
+	// Scope и OccurrenceDate задают область изменения повторяющегося события
+	Scope          string `json:"scope,omitempty" form:"scope"`
+	OccurrenceDate string `json:"occurrence_date,omitempty" form:"occurrence_date"`
+	// CalendarID переносит событие в другой календарь владельца; пустое значение
+	// сохраняет текущий, "default" - календарь по умолчанию
+	CalendarID string `json:"calendar_id,omitempty" form:"calendar_id"`
+	// Attendees заменяет участников; пустой список удаляет их, отсутствующий - сохраняет
+	Attendees []string `json:"attendees,omitempty" form:"attendees"`
+}
+
+type DeleteEventRequest struct {
+	UserID         string `json:"user_id" form:"user_id"`
+	EventID        string `json:"event_id" form:"event_id"`
+	Scope          string `json:"scope,omitempty" form:"scope"`
+	OccurrenceDate string `json:"occurrence_date,omitempty" form:"occurrence_date"`
+}
+
+type EventDTO struct {
+	ID             string        `json:"id"`
+	UserID         string        `json:"user_id"`
+	CalendarID     string        `json:"calendar_id,omitempty"`
+	Date           string        `json:"date"`
+	Start          string        `json:"start"`
+	End            string        `json:"end"`
+	AllDay         bool          `json:"all_day"`
+	TimeZone       string        `json:"time_zone,omitempty"`
+	Text           string        `json:"text"`
+	ReminderTime   *string       `json:"reminder_time,omitempty"`
+	Reminders      []ReminderDTO `json:"reminders,omitempty"`
+	CreatedAt      string        `json:"created_at"`
+	UpdatedAt      string        `json:"updated_at"`
+	RRule          string        `json:"rrule,omitempty"`
+	SeriesID       string        `json:"series_id,omitempty"`
+	OccurrenceDate *string       `json:"occurrence_date,omitempty"`
+	Attendees      []AttendeeDTO `json:"attendees,omitempty"`
+}
+
+func eventsToDTO(events []*domain.Event) []EventDTO {
+	dtos := make([]EventDTO, len(events))
+	for i, e := range events {
+		dtos[i] = eventToDTO(e)
 	}
+	return dtos
+}
 
-	events, err := h.service.GetEventsForWeek(userID, date)
-	if err != nil {
-		h.sendError(w, "Failed to get events", http.StatusInternalServerError)
-		return
+func eventToDTO(e *domain.Event) EventDTO {
+	// Время показывается в поясе события
+	loc := e.Location()
+	dto := EventDTO{
+		ID:         e.ID,
+		UserID:     e.UserID,
+		CalendarID: e.CalendarID,
+		Date:       e.Date.In(loc).Format("2006-01-02"),
+		Start:      e.Date.In(loc).Format(time.RFC3339),
+		End:        e.EndTime().In(loc).Format(time.RFC3339),
+		AllDay:     e.AllDay,
+		TimeZone:   e.TimeZone,
+		Text:       e.Text,
+		CreatedAt:  e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  e.UpdatedAt.Format(time.RFC3339),
+	}
+	if e.ReminderTime != nil {
+		rt := e.ReminderTime.Format(time.RFC3339)
+		dto.ReminderTime = &rt
+	}
+	dto.Reminders = remindersToDTO(e, loc)
+	dto.Attendees = attendeesToDTO(e.Attendees, loc)
+	if e.Recurrence != nil {
+		dto.RRule = e.Recurrence.String()
 	}
+	if e.RecurrenceID != nil {
+		dto.SeriesID = e.SeriesID
+		od := e.RecurrenceID.Format(time.RFC3339)
+		dto.OccurrenceDate = &od
+	}
+	return dto
+}
 
-	h.sendSuccess(w, map[string]interface{}{
-		"events": eventsToDTO(events),
-	})
+var (
+	errDateRequired          = errors.New("date or start is required")
+	errInvalidDateFormat     = errors.New("Invalid date format. Use YYYY-MM-DD")
+	errInvalidStartFormat    = errors.New("Invalid start format. Use RFC3339")
+	errInvalidEndFormat      = errors.New("Invalid end format. Use RFC3339 or YYYY-MM-DD for all-day events")
+	errInvalidDuration       = errors.New("Invalid duration. Use a positive value like 1h30m")
+	errEndAndDuration        = errors.New("end and duration are mutually exclusive")
+	errInvalidReminderFormat = errors.New("Invalid reminder time format. Use RFC3339")
+	errInvalidOccurrence     = errors.New("Invalid occurrence date format. Use RFC3339 or YYYY-MM-DD")
+	errInvalidRangeFormat    = errors.New("from and to are required. Use RFC3339 or YYYY-MM-DD")
+	errInvalidLimit          = errors.New("limit must be a positive number")
+)
+
+// eventFields содержит общие поля запросов создания и обновления события
+type eventFields struct {
+	Date, Start, End, Duration string
+	AllDay                     bool
+	TimeZone                   string
+	ReminderTime, RRule        string
+	Reminders                  []string
+	Attendees                  []string
 }
 
-// GetEventsForMonth handles GET /events_for_month
-func (h *EventHandler) GetEventsForMonth(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
-	}
+func (r *CreateEventRequest) fields() eventFields {
+	return eventFields{r.Date, r.Start, r.End, r.Duration, r.AllDay, r.TimeZone, r.ReminderTime, r.RRule, r.Reminders, r.Attendees}
+}
 
-	userID := r.URL.Query().Get("user_id")
-	dateStr := r.URL.Query().Get("date")
+func (r *UpdateEventRequest) fields() eventFields {
+	return eventFields{r.Date, r.Start, r.End, r.Duration, r.AllDay, r.TimeZone, r.ReminderTime, r.RRule, r.Reminders, r.Attendees}
+}
 
-	if userID == "" || dateStr == "" {
-		h.sendError(w, "user_id and date are required", http.StatusBadRequest)
-		return
-	}
+// eventInput содержит разобранные поля события для передачи в сервис
+type eventInput struct {
+	start        time.Time
+	reminderTime *time.Time
+	opts         []service.EventOption
+}
+
+// parseEventFields разбирает время события, напоминание и правило повторения.
+// Событие задается либо датой date (на весь день), либо временем начала start.
+// Окончание задается через end или duration; для событий на весь день end
+// в формате YYYY-MM-DD означает последний день включительно.
+// Даты без времени интерпретируются в поясе loc.
+func parseEventFields(f eventFields, loc *time.Location) (*eventInput, error) {
+	input := &eventInput{}
 
-	date, err := time.Parse("2006-01-02", dateStr)
+	start, allDay, err := parseStart(f.Start, f.Date, f.AllDay, loc)
 	if err != nil {
-		h.sendError(w, "Invalid date format. Use YYYY-MM-DD", http.StatusBadRequest)
-		return
+		return nil, err
+	}
+	input.start = start
+	input.opts = append(input.opts, service.WithAllDay(allDay))
+	if f.TimeZone != "" {
+		input.opts = append(input.opts, service.WithTimeZone(f.TimeZone))
 	}
 
-	events, err := h.service.GetEventsForMonth(userID, date)
+	endOpt, err := parseEnd(f.End, f.Duration, start, allDay, loc)
 	if err != nil {
-		h.sendError(w, "Failed to get events", http.StatusInternalServerError)
-		return
+		return nil, err
+	}
+	if endOpt != nil {
+		input.opts = append(input.opts, endOpt)
 	}
 
-	h.sendSuccess(w, map[string]interface{}{
-		"events": eventsToDTO(events),
-	})
+	if f.ReminderTime != "" {
+		rt, err := time.Parse(time.RFC3339, f.ReminderTime)
+		if err != nil {
+			return nil, errInvalidReminderFormat
+		}
+		input.reminderTime = &rt
+	}
+	if f.Reminders != nil {
+		// Пустой список удаляет напоминания, отсутствующий - сохраняет текущие
+		reminders, err := parseReminders(f.Reminders)
+		if err != nil {
+			return nil, err
+		}
+		input.opts = append(input.opts, service.WithReminders(reminders))
+	}
+
+	if f.Attendees != nil {
+		input.opts = append(input.opts, service.WithAttendees(f.Attendees))
+	}
+
+	if f.RRule != "" {
+		rule, err := domain.ParseRecurrenceRule(f.RRule)
+		if err != nil {
+			return nil, err
+		}
+		input.opts = append(input.opts, service.WithRecurrence(rule))
+	}
+
+	return input, nil
 }
 
-// Request/Response types
-type CreateEventRequest struct {
-	UserID       string `json:"user_id" form:"user_id"`
-	Date         string `json:"date" form:"date"`
-	Event        string `json:"event" form:"event"`
-	ReminderTime string `json:"reminder_time,omitempty" form:"reminder_time"`
+// parseStart разбирает начало события. Дата date делает событие событием
+// на весь день, а начало такого события приводится к полуночи в поясе loc.
+func parseStart(startStr, dateStr string, allDay bool, loc *time.Location) (time.Time, bool, error) {
+	var start time.Time
+	switch {
+	case startStr != "":
+		parsed, err := time.Parse(time.RFC3339, startStr)
+		if err != nil {
+			return time.Time{}, false, errInvalidStartFormat
+		}
+		start = parsed
+	case dateStr != "":
+		date, err := time.ParseInLocation("2006-01-02", dateStr, loc)
+		if err != nil {
+			return time.Time{}, false, errInvalidDateFormat
+		}
+		start = date
+		allDay = true
+	default:
+		return time.Time{}, false, errDateRequired
+	}
+
+	if allDay {
+		start = startOfDay(start, loc)
+	}
+	return start, allDay, nil
 }
 
-type UpdateEventRequest struct {
-	UserID       string `json:"user_id" form:"user_id"`
-	EventID      string `json:"event_id" form:"event_id"`
-	Date         string `json:"date" form:"date"`
-	Event        string `json:"event" form:"event"`
-	ReminderTime string `json:"reminder_time,omitempty" form:"reminder_time"`
+// parseEnd разбирает окончание события из end или duration.
+// Если ни одно не указано, возвращает nil.
+func parseEnd(endStr, durationStr string, start time.Time, allDay bool, loc *time.Location) (service.EventOption, error) {
+	if endStr != "" && durationStr != "" {
+		return nil, errEndAndDuration
+	}
+	if endStr != "" {
+		end, err := time.Parse(time.RFC3339, endStr)
+		if err != nil && allDay {
+			var lastDay time.Time
+			if lastDay, err = time.ParseInLocation("2006-01-02", endStr, loc); err == nil {
+				end = lastDay.AddDate(0, 0, 1)
+			}
+		}
+		if err != nil {
+			return nil, errInvalidEndFormat
+		}
+		return service.WithEnd(end), nil
+	}
+	if durationStr != "" {
+		d, err := time.ParseDuration(durationStr)
+		if err != nil || d <= 0 {
+			return nil, errInvalidDuration
+		}
+		return service.WithEnd(start.Add(d)), nil
+	}
+	return nil, nil
 }
 
-type DeleteEventRequest struct {
-	UserID  string `json:"user_id" form:"user_id"`
-	EventID string `json:"event_id" form:"event_id"`
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
 }
 
-type EventDTO struct {
-	ID           string     `json:"id"`
-	UserID       string     `json:"user_id"`
-	Date         string     `json:"date"`
-	Text         string     `json:"text"`
-	ReminderTime *string    `json:"reminder_time,omitempty"`
-	CreatedAt    string     `json:"created_at"`
-	UpdatedAt    string     `json:"updated_at"`
+// parseScope разбирает область изменения повторяющегося события eventID
+// и возвращает ее вместе с началом вхождения (нулевым, если оно не указано).
+// occurrence_date принимается в формате RFC3339 (как в ответе) или YYYY-MM-DD.
+func parseScope(svc *service.EventService, userID, eventID, scopeStr, occurrenceStr string) (service.EventOption, time.Time, error) {
+	scope, err := domain.ParseEditScope(scopeStr)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if scope == "" && occurrenceStr == "" {
+		return nil, time.Time{}, nil
+	}
+
+	occurrence, err := parseOccurrence(svc, userID, eventID, occurrenceStr)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return service.WithScope(scope, occurrence), occurrence, nil
 }
 
-// decodeRequest is implemented in form_decoder.go
+// sendScopeError отправляет ошибку разбора области изменения
+func sendScopeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, domain.ErrEventNotFound) {
+		sendError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	sendError(w, err.Error(), http.StatusBadRequest)
+}
 
-func (h *EventHandler) sendSuccess(w http.ResponseWriter, data map[string]interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"result": data,
-	})
+// parseOccurrence разбирает начало вхождения серии (пустая строка - нулевое время).
+// Дата без времени - это день в поясе пользователя, вхождение ищется по ней.
+func parseOccurrence(svc *service.EventService, userID, eventID, occurrenceStr string) (time.Time, error) {
+	if occurrenceStr == "" {
+		return time.Time{}, nil
+	}
+	if occurrence, err := time.Parse(time.RFC3339, occurrenceStr); err == nil {
+		return occurrence, nil
+	}
+
+	loc, err := svc.UserLocation(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	day, err := time.ParseInLocation("2006-01-02", occurrenceStr, loc)
+	if err != nil {
+		return time.Time{}, errInvalidOccurrence
+	}
+	return svc.OccurrenceOnDay(userID, eventID, day)
 }
 
-func (h *EventHandler) sendError(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"error": message,
-	})
+// isValidationError проверяет, является ли ошибка ошибкой валидации события
+func isValidationError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidDate) ||
+		errors.Is(err, domain.ErrInvalidUserID) ||
+		errors.Is(err, domain.ErrInvalidEventText) ||
+		errors.Is(err, domain.ErrInvalidEndTime) ||
+		errors.Is(err, domain.ErrInvalidTimeZone) ||
+		errors.Is(err, domain.ErrInvalidReminder) ||
+		errors.Is(err, domain.ErrInvalidRecurrence) ||
+		errors.Is(err, domain.ErrInvalidScope) ||
+		errors.Is(err, domain.ErrCalendarNotFound) ||
+		errors.Is(err, domain.ErrInvalidAttendee)
 }
 
-func eventsToDTO(events []*domain.Event) []EventDTO {
-	dtos := make([]EventDTO, len(events))
-	for i, e := range events {
-		dtos[i] = EventDTO{
-			ID:        e.ID,
-			UserID:    e.UserID,
-			Date:      e.Date.Format("2006-01-02"),
-			Text:      e.Text,
-			CreatedAt: e.CreatedAt.Format(time.RFC3339),
-			UpdatedAt: e.UpdatedAt.Format(time.RFC3339),
-		}
-		if e.ReminderTime != nil {
-			rt := e.ReminderTime.Format(time.RFC3339)
-			dtos[i].ReminderTime = &rt
+// parseIDList собирает идентификаторы из повторяющегося или перечисленного
+// через запятую параметра запроса (calendar_id, users)
+func parseIDList(values []string) []string {
+	var ids []string
+	for _, value := range values {
+		for _, id := range strings.Split(value, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
 		}
 	}
-	return dtos
+	return ids
 }
-
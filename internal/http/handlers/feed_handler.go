@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/ical"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// FeedPrefix - префикс адресов подписки на календари
+const FeedPrefix = "/feeds/"
+
+// FeedHandler отдает календарь пользователя в формате iCalendar по адресу
+// подписки /feeds/{user}/{token}.ics. Секрет в адресе заменяет авторизацию,
+// так как календарные клиенты не умеют передавать заголовки.
+type FeedHandler struct {
+	events *service.EventService
+	users  *service.UserService
+	logger logger.Logger
+}
+
+// NewFeedHandler создает новый обработчик подписки на календарь
+func NewFeedHandler(events *service.EventService, users *service.UserService, log logger.Logger) *FeedHandler {
+	return &FeedHandler{
+		events: events,
+		users:  users,
+		logger: log,
+	}
+}
+
+// ServeHTTP handles GET /feeds/{user}/{token}.ics.
+// Ответ содержит ETag; при совпадении с If-None-Match возвращается 304.
+func (h *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, token, ok := parseFeedPath(r.URL.EscapedPath())
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	// Неверный секрет неотличим от несуществующего адреса
+	if err := h.users.CheckFeedToken(userID, token); err != nil {
+		if !errors.Is(err, domain.ErrInvalidFeedToken) && !errors.Is(err, domain.ErrInvalidUserID) {
+			h.logger.Log(logger.LevelError, "Failed to check feed token", map[string]interface{}{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		http.NotFound(w, r)
+		return
+	}
+
+	events, err := h.events.ExportEvents(userID)
+	if err != nil {
+		h.logger.Log(logger.LevelError, "Failed to export events", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	body := ical.Encode(userID, events)
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		w.Write(body)
+	}
+}
+
+// parseFeedPath разбирает путь /feeds/{user}/{token}.ics
+func parseFeedPath(path string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(path, FeedPrefix)
+	if !ok {
+		return "", "", false
+	}
+	escapedUser, file, ok := strings.Cut(rest, "/")
+	if !ok {
+		return "", "", false
+	}
+	token, ok := strings.CutSuffix(file, ".ics")
+	if !ok || token == "" || strings.Contains(token, "/") {
+		return "", "", false
+	}
+	userID, err := url.PathUnescape(escapedUser)
+	if err != nil || userID == "" {
+		return "", "", false
+	}
+	return userID, token, true
+}
+
+// etagMatches проверяет заголовок If-None-Match: список тегов через запятую
+// или "*". Слабые теги (W/) сравниваются без префикса, как требует RFC 9110.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// feedURL возвращает абсолютный адрес подписки для запроса r.
+// Схема берется из X-Forwarded-Proto, если сервер стоит за прокси.
+func feedURL(r *http.Request, userID, token string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host + FeedPrefix + url.PathEscape(userID) + "/" + token + ".ics"
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func TestFeedHandler_Subscription(t *testing.T) {
+	users := storage.NewMemoryUserRepository()
+	events := service.NewEventService(storage.NewMemoryRepository(), service.WithUserRepository(users))
+	userService := service.NewUserService(users)
+	api := NewAPIHandler(events, userService, service.NewWebhookService(storage.NewMemoryWebhookRepository()),
+		service.NewAPIKeyService(storage.NewMemoryAPIKeyRepository()), nopLogger{})
+	feed := NewFeedHandler(events, userService, nopLogger{})
+
+	rec := serveAPI(api, http.MethodPost, "/api/v1/users/user1/events",
+		`{"event":"Meeting","start":"2024-01-15T10:00:00Z","duration":"1h","reminders":["15m"]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Failed to create event: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = serveAPI(api, http.MethodGet, "/api/v1/users/user1/feed", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		URL string `json:"url"`
+	}
+	json.NewDecoder(rec.Body).Decode(&resp)
+	feedURL, err := url.Parse(resp.URL)
+	if err != nil || feedURL.Scheme != "http" || !strings.HasPrefix(feedURL.Path, "/feeds/user1/") {
+		t.Fatalf("Unexpected feed url %q", resp.URL)
+	}
+
+	rec = serveAPI(feed, http.MethodGet, feedURL.Path, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/calendar") {
+		t.Errorf("Unexpected Content-Type %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "SUMMARY:Meeting") || !strings.Contains(body, "TRIGGER:-PT15M") {
+		t.Errorf("Unexpected feed body:\n%s", body)
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("Expected ETag header")
+	}
+
+	// Неизмененный календарь не загружается повторно
+	req := httptest.NewRequest(http.MethodGet, feedURL.Path, nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	feed.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("Expected 304 without body, got %d", rec.Code)
+	}
+
+	serveAPI(api, http.MethodPost, "/api/v1/users/user1/events", `{"event":"Lunch","date":"2024-01-16"}`)
+	rec = httptest.NewRecorder()
+	feed.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("Expected new ETag after change, got %d", rec.Code)
+	}
+
+	// Замена секрета отключает прежний адрес
+	rec = serveAPI(api, http.MethodPost, "/api/v1/users/user1/feed", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if rec = serveAPI(feed, http.MethodGet, feedURL.Path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for rotated token, got %d", rec.Code)
+	}
+	json.NewDecoder(serveAPI(api, http.MethodGet, "/api/v1/users/user1/feed", "").Body).Decode(&resp)
+	rotated, _ := url.Parse(resp.URL)
+	if rec = serveAPI(feed, http.MethodGet, rotated.Path, ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for new token, got %d", rec.Code)
+	}
+
+	if rec = serveAPI(api, http.MethodDelete, "/api/v1/users/user1/feed", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected 204, got %d", rec.Code)
+	}
+	if rec = serveAPI(feed, http.MethodGet, rotated.Path, ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for disabled feed, got %d", rec.Code)
+	}
+}
+
+func TestFeedHandler_InvalidPath(t *testing.T) {
+	users := storage.NewMemoryUserRepository()
+	events := service.NewEventService(storage.NewMemoryRepository(), service.WithUserRepository(users))
+	feed := NewFeedHandler(events, service.NewUserService(users), nopLogger{})
+
+	for _, path := range []string{
+		"/feeds/user1/secret.ics",
+		"/feeds/user1/.ics",
+		"/feeds/user1",
+		"/feeds//secret.ics",
+	} {
+		if rec := serveAPI(feed, http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, rec.Code)
+		}
+	}
+	if rec := serveAPI(feed, http.MethodPost, "/feeds/user1/secret.ics", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405, got %d", rec.Code)
+	}
+}
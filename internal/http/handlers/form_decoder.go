@@ -4,26 +4,27 @@ import (
 	"encoding/json"
 	"net/http"
 	"reflect"
+	"strconv"
 )
 
 // decodeRequest декодирует тело запроса из JSON или form data
-func (h *EventHandler) decodeRequest(r *http.Request, v interface{}) error {
+func decodeRequest(r *http.Request, v interface{}) error {
 	contentType := r.Header.Get("Content-Type")
-	
+
 	// Проверить, является ли content type JSON (может включать charset)
 	if len(contentType) >= 16 && contentType[:16] == "application/json" {
 		return json.NewDecoder(r.Body).Decode(v)
 	}
-	
+
 	// Обработать form data
 	if err := r.ParseForm(); err != nil {
 		return err
 	}
-	
+
 	// Использовать рефлексию для заполнения структуры из значений формы
 	rv := reflect.ValueOf(v).Elem()
 	rt := rv.Type()
-	
+
 	for i := 0; i < rt.NumField(); i++ {
 		field := rt.Field(i)
 		formTag := field.Tag.Get("form")
@@ -33,16 +34,42 @@ func (h *EventHandler) decodeRequest(r *http.Request, v interface{}) error {
 		if formTag == "" || formTag == "-" {
 			continue
 		}
-		
+
+		if fieldValue := rv.Field(i); fieldValue.Kind() == reflect.Slice && fieldValue.CanSet() {
+			// Повторяющиеся поля формы собираются в список строк
+			if values := r.Form[formTag]; len(values) > 0 && field.Type.Elem().Kind() == reflect.String {
+				fieldValue.Set(reflect.ValueOf(append([]string(nil), values...)))
+			}
+			continue
+		}
+
+		if fieldValue := rv.Field(i); fieldValue.Kind() == reflect.Ptr && fieldValue.CanSet() {
+			// Указатель на строку отличает пустое значение от отсутствующего поля
+			if values, ok := r.Form[formTag]; ok && field.Type.Elem().Kind() == reflect.String {
+				value := values[0]
+				fieldValue.Set(reflect.ValueOf(&value))
+			}
+			continue
+		}
+
 		value := r.FormValue(formTag)
 		if value != "" {
 			fieldValue := rv.Field(i)
-			if fieldValue.CanSet() && fieldValue.Kind() == reflect.String {
+			if !fieldValue.CanSet() {
+				continue
+			}
+			switch fieldValue.Kind() {
+			case reflect.String:
 				fieldValue.SetString(value)
+			case reflect.Bool:
+				b, err := strconv.ParseBool(value)
+				if err != nil {
+					return err
+				}
+				fieldValue.SetBool(b)
 			}
 		}
 	}
-	
+
 	return nil
 }
-
@@ -0,0 +1,19 @@
+package handlers
+
+import "net/http"
+
+// HealthPath - адрес проверки работоспособности, доступный без учетных данных
+const HealthPath = "/healthz"
+
+// Health handles GET /healthz. Отвечает 200, пока сервер принимает запросы;
+// используется проверкой состояния контейнера.
+func Health(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	sendSuccess(w, map[string]interface{}{
+		"status": "ok",
+	})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+var errInvalidReminder = errors.New("Invalid reminder. Use an offset before start (15m, 2h, 1d) or RFC3339 time")
+
+// ReminderDTO представляет напоминание события в ответе.
+// Before заполнено у напоминаний относительно начала, Time - момент напоминания.
+type ReminderDTO struct {
+	Before string `json:"before,omitempty"`
+	Time   string `json:"time"`
+}
+
+// parseReminders разбирает список напоминаний: каждое значение - либо смещение
+// до начала события (15m, 2h, 1d), либо абсолютное время в RFC3339
+func parseReminders(values []string) ([]domain.Reminder, error) {
+	reminders := make([]domain.Reminder, 0, len(values))
+	for _, value := range values {
+		if at, err := time.Parse(time.RFC3339, value); err == nil {
+			reminders = append(reminders, domain.Reminder{At: &at})
+			continue
+		}
+		offset, err := parseOffset(value)
+		if err != nil || offset < 0 {
+			return nil, errInvalidReminder
+		}
+		reminders = append(reminders, domain.Reminder{Offset: offset})
+	}
+	return reminders, nil
+}
+
+// parseOffset разбирает длительность в формате time.ParseDuration
+// с дополнительным суффиксом d для дней
+func parseOffset(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// formatOffset форматирует смещение в виде, принимаемом parseOffset
+func formatOffset(d time.Duration) string {
+	if d != 0 && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	}
+	// 2h0m0s -> 2h0m -> 2h
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+func remindersToDTO(e *domain.Event, loc *time.Location) []ReminderDTO {
+	if len(e.Reminders) == 0 {
+		return nil
+	}
+	dtos := make([]ReminderDTO, len(e.Reminders))
+	for i, reminder := range e.Reminders {
+		dtos[i].Time = reminder.TimeFor(e.Date).In(loc).Format(time.RFC3339)
+		if reminder.IsRelative() {
+			dtos[i].Before = formatOffset(reminder.Offset)
+		}
+	}
+	return dtos
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/stream"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// StreamPath - адрес потока изменений календаря
+const StreamPath = "/api/v1/stream"
+
+// streamHeartbeat - период комментариев, не дающих прокси закрыть простаивающее соединение
+const streamHeartbeat = 25 * time.Second
+
+// StreamHandler отдает изменения календаря пользователя в формате
+// Server-Sent Events, чтобы клиентам не приходилось опрашивать сервер
+type StreamHandler struct {
+	hub    *stream.Hub
+	logger logger.Logger
+}
+
+// NewStreamHandler создает новый обработчик потока изменений
+func NewStreamHandler(hub *stream.Hub, log logger.Logger) *StreamHandler {
+	return &StreamHandler{
+		hub:    hub,
+		logger: log,
+	}
+}
+
+// ChangeDTO - изменение календаря в потоке. Тип изменения дублируется
+// в поле event сообщения, чтобы клиент мог подписаться на отдельные типы.
+type ChangeDTO struct {
+	Type     string          `json:"type"`
+	UserID   string          `json:"user_id"`
+	EventID  string          `json:"event_id"`
+	At       string          `json:"at"`
+	Event    *EventDTO       `json:"event,omitempty"`
+	Reminder *StreamReminder `json:"reminder,omitempty"`
+}
+
+// StreamReminder - отправленное напоминание в потоке изменений
+type StreamReminder struct {
+	Text  string `json:"text"`
+	Time  string `json:"time"`
+	Start string `json:"start"`
+}
+
+// ServeHTTP handles GET /api/v1/stream. Поток относится к аутентифицированному
+// пользователю; необязательный user_id должен с ним совпадать.
+// Соединение остается открытым, пока клиент не отключится. Если клиент
+// не успевает читать изменения, поток закрывается и клиент должен
+// переподключиться, заново загрузив календарь.
+func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := requestUser(r, r.URL.Query().Get("user_id"))
+	if err != nil {
+		sendUserError(w, err)
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	// Таймаут записи сервера рассчитан на обычные ответы, а не на поток
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		h.logger.Log(logger.LevelError, "Failed to disable write deadline", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+	}
+
+	sub := h.hub.Subscribe(userID)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		h.logger.Log(logger.LevelError, "Streaming is not supported", map[string]interface{}{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case change, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(changeToDTO(change))
+			if err != nil {
+				h.logger.Log(logger.LevelError, "Failed to encode change", map[string]interface{}{
+					"user_id": userID,
+					"error":   err.Error(),
+				})
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", change.Type, data); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func changeToDTO(change *domain.Change) ChangeDTO {
+	dto := ChangeDTO{
+		Type:    string(change.Type),
+		UserID:  change.UserID,
+		EventID: change.EventID,
+		At:      change.At.Format(time.RFC3339),
+	}
+	if change.Event != nil {
+		event := eventToDTO(change.Event)
+		dto.Event = &event
+	}
+	if change.Reminder != nil {
+		dto.Reminder = &StreamReminder{
+			Text:  change.Reminder.Text,
+			Time:  change.Reminder.Time.Format(time.RFC3339),
+			Start: change.Reminder.Start.Format(time.RFC3339),
+		}
+	}
+	return dto
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+	"github.com/oziev02/event-calendar-service/internal/stream"
+)
+
+func TestStreamHandler_Changes(t *testing.T) {
+	hub := stream.NewHub(stream.DefaultBuffer)
+	users := storage.NewMemoryUserRepository()
+	events := service.NewEventService(storage.NewMemoryRepository(),
+		service.WithUserRepository(users), service.WithChangeNotifier(hub))
+	api := NewAPIHandler(events, service.NewUserService(users), service.NewWebhookService(storage.NewMemoryWebhookRepository()),
+		service.NewAPIKeyService(storage.NewMemoryAPIKeyRepository()), nopLogger{})
+
+	stream := NewStreamHandler(hub, nopLogger{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream.ServeHTTP(w, asUser(r, "user1"))
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + StreamPath)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Unexpected Content-Type %q", ct)
+	}
+
+	// Изменения другого пользователя в поток не попадают
+	serveAPI(api, http.MethodPost, "/api/v1/users/user2/events", `{"event":"Other","date":"2024-01-15"}`)
+	rec := serveAPI(api, http.MethodPost, "/api/v1/users/user1/events", `{"event":"Meeting","date":"2024-01-15"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Failed to create event: %d %s", rec.Code, rec.Body)
+	}
+	var created EventDTO
+	json.NewDecoder(rec.Body).Decode(&created)
+	serveAPI(api, http.MethodDelete, "/api/v1/users/user1/events/"+created.ID, "")
+
+	reader := bufio.NewReader(resp.Body)
+	for _, want := range []string{"created", "deleted"} {
+		kind, data := readStreamMessage(t, reader)
+		if kind != want {
+			t.Fatalf("Expected %s message, got %s", want, kind)
+		}
+		var change ChangeDTO
+		if err := json.Unmarshal([]byte(data), &change); err != nil {
+			t.Fatalf("Failed to decode change: %v", err)
+		}
+		if change.EventID != created.ID || change.UserID != "user1" {
+			t.Errorf("Unexpected change %+v", change)
+		}
+		if want == "created" && (change.Event == nil || change.Event.Text != "Meeting") {
+			t.Errorf("Expected event in created change, got %+v", change.Event)
+		}
+		if want == "deleted" && change.Event != nil {
+			t.Errorf("Expected no event in deleted change, got %+v", change.Event)
+		}
+	}
+}
+
+func TestStreamHandler_RequiresUser(t *testing.T) {
+	h := NewStreamHandler(stream.NewHub(0), nopLogger{})
+	if rec := serveAPI(h, http.MethodGet, StreamPath, ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without authentication, got %d", rec.Code)
+	}
+
+	req := asUser(httptest.NewRequest(http.MethodGet, StreamPath+"?user_id=user2", nil), "user1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for another user's stream, got %d", rec.Code)
+	}
+
+	req = asUser(httptest.NewRequest(http.MethodPost, StreamPath, nil), "user1")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for POST, got %d", rec.Code)
+	}
+}
+
+// readStreamMessage читает одно сообщение SSE, пропуская комментарии
+func readStreamMessage(t *testing.T, reader *bufio.Reader) (string, string) {
+	t.Helper()
+	var kind, data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && kind != "":
+			return kind, data
+		case strings.HasPrefix(line, "event: "):
+			kind = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
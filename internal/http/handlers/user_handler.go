@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/service"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// UserHandler обрабатывает HTTP запросы для настроек пользователей
+type UserHandler struct {
+	service *service.UserService
+	logger  logger.Logger
+}
+
+// NewUserHandler создает новый обработчик настроек пользователей
+func NewUserHandler(service *service.UserService, log logger.Logger) *UserHandler {
+	return &UserHandler{
+		service: service,
+		logger:  log,
+	}
+}
+
+// GetUser handles GET /user
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, err := requestUser(r, r.URL.Query().Get("user_id"))
+	if err != nil {
+		sendUserError(w, err)
+		return
+	}
+
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		sendError(w, "Failed to get user", http.StatusInternalServerError)
+		return
+	}
+
+	sendSuccess(w, map[string]interface{}{
+		"user": userToDTO(user),
+	})
+}
+
+// UpdateUser handles POST /update_user
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req UpdateUserRequest
+	if err := decodeRequest(r, &req); err != nil {
+		sendError(w, "Invalid request format", http.StatusBadRequest)
+		return
+	}
+
+	userID, err := requestUser(r, req.UserID)
+	if err != nil {
+		sendUserError(w, err)
+		return
+	}
+
+	user, err := h.service.GetUser(userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidUserID) {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendError(w, "Failed to get user", http.StatusInternalServerError)
+		return
+	}
+	if err := req.apply(user); err != nil {
+		sendError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err = h.service.UpdateUser(user)
+	if err != nil {
+		if isUserValidationError(err) {
+			sendError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sendError(w, "Failed to update user", http.StatusInternalServerError)
+		return
+	}
+
+	sendSuccess(w, map[string]interface{}{
+		"user": userToDTO(user),
+	})
+}
+
+// UpdateUserRequest представляет запрос на изменение настроек пользователя.
+// Поля, отсутствующие в запросе, сохраняют прежние значения.
+type UpdateUserRequest struct {
+	UserID           string   `json:"user_id" form:"user_id"`
+	TimeZone         *string  `json:"time_zone" form:"time_zone"`
+	Email            *string  `json:"email" form:"email"`
+	WebhookURL       *string  `json:"webhook_url" form:"webhook_url"`
+	ReminderChannels []string `json:"reminder_channels" form:"reminder_channels"`
+	// Рабочее время: начало и окончание HH:MM и дни недели (MO, TU, ...);
+	// пустые work_start и work_end возвращают рабочее время по умолчанию
+	WorkStart *string  `json:"work_start" form:"work_start"`
+	WorkEnd   *string  `json:"work_end" form:"work_end"`
+	WorkDays  []string `json:"work_days" form:"work_days"`
+}
+
+// apply переносит переданные поля запроса в настройки пользователя
+func (r *UpdateUserRequest) apply(user *domain.User) error {
+	if r.TimeZone != nil {
+		user.TimeZone = *r.TimeZone
+	}
+	if r.Email != nil {
+		user.Email = *r.Email
+	}
+	if r.WebhookURL != nil {
+		user.WebhookURL = *r.WebhookURL
+	}
+	if r.ReminderChannels != nil {
+		user.Channels = make([]domain.ReminderChannel, 0, len(r.ReminderChannels))
+		for _, name := range r.ReminderChannels {
+			channel, err := domain.ParseReminderChannel(name)
+			if err != nil {
+				return err
+			}
+			user.Channels = append(user.Channels, channel)
+		}
+	}
+	return r.applyWorkingHours(user)
+}
+
+// applyWorkingHours переносит переданные поля рабочего времени в настройки пользователя
+func (r *UpdateUserRequest) applyWorkingHours(user *domain.User) error {
+	if r.WorkStart == nil && r.WorkEnd == nil && r.WorkDays == nil {
+		return nil
+	}
+	if r.WorkStart != nil && *r.WorkStart == "" && r.WorkEnd != nil && *r.WorkEnd == "" {
+		user.WorkingHours = nil
+		return nil
+	}
+
+	hours := user.Hours()
+	var err error
+	if r.WorkStart != nil {
+		if hours.Start, err = domain.ParseClock(*r.WorkStart); err != nil {
+			return err
+		}
+	}
+	if r.WorkEnd != nil {
+		if hours.End, err = domain.ParseClock(*r.WorkEnd); err != nil {
+			return err
+		}
+	}
+	if r.WorkDays != nil {
+		hours.Days = make([]time.Weekday, 0, len(r.WorkDays))
+		for _, code := range r.WorkDays {
+			day, err := domain.ParseWeekday(code)
+			if err != nil {
+				return err
+			}
+			hours.Days = append(hours.Days, day)
+		}
+	}
+	user.WorkingHours = &hours
+	return nil
+}
+
+// UserDTO представляет настройки пользователя в ответе
+type UserDTO struct {
+	ID               string   `json:"id"`
+	TimeZone         string   `json:"time_zone"`
+	Email            string   `json:"email,omitempty"`
+	WebhookURL       string   `json:"webhook_url,omitempty"`
+	ReminderChannels []string `json:"reminder_channels,omitempty"`
+	WorkStart        string   `json:"work_start"`
+	WorkEnd          string   `json:"work_end"`
+	WorkDays         []string `json:"work_days"`
+}
+
+func userToDTO(u *domain.User) UserDTO {
+	tz := u.TimeZone
+	if tz == "" {
+		tz = "UTC"
+	}
+	dto := UserDTO{
+		ID:         u.ID,
+		TimeZone:   tz,
+		Email:      u.Email,
+		WebhookURL: u.WebhookURL,
+	}
+	for _, channel := range u.Channels {
+		dto.ReminderChannels = append(dto.ReminderChannels, string(channel))
+	}
+	hours := u.Hours()
+	dto.WorkStart = domain.FormatClock(hours.Start)
+	dto.WorkEnd = domain.FormatClock(hours.End)
+	for _, day := range hours.Days {
+		dto.WorkDays = append(dto.WorkDays, domain.WeekdayCode(day))
+	}
+	return dto
+}
+
+// isUserValidationError проверяет, является ли ошибка ошибкой валидации настроек
+func isUserValidationError(err error) bool {
+	return errors.Is(err, domain.ErrInvalidUserID) ||
+		errors.Is(err, domain.ErrInvalidTimeZone) ||
+		errors.Is(err, domain.ErrInvalidEmail) ||
+		errors.Is(err, domain.ErrInvalidWebhookURL) ||
+		errors.Is(err, domain.ErrInvalidChannel) ||
+		errors.Is(err, domain.ErrInvalidWorkingHours)
+}
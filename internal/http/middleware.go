@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"net/url"
 	"time"
 
 	"github.com/oziev02/event-calendar-service/pkg/logger"
@@ -31,13 +32,25 @@ func (m *LoggingMiddleware) Handler(next http.Handler) http.Handler {
 
 		m.logger.Log(logger.LevelInfo, "HTTP Request", map[string]interface{}{
 			"method":      r.Method,
-			"url":         r.URL.String(),
+			"url":         redactURL(r.URL),
 			"status_code": rw.statusCode,
 			"duration_ms": duration.Milliseconds(),
 		})
 	})
 }
 
+// redactURL скрывает учетные данные в строке запроса, чтобы они не попали в лог
+func redactURL(u *url.URL) string {
+	query := u.Query()
+	if !query.Has(accessTokenParam) {
+		return u.String()
+	}
+	query.Set(accessTokenParam, "REDACTED")
+	redacted := *u
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
 // responseWriter оборачивает http.ResponseWriter для захвата статус кода
 type responseWriter struct {
 	http.ResponseWriter
@@ -48,3 +61,9 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap возвращает исходный http.ResponseWriter для http.ResponseController,
+// чтобы потоковые обработчики могли сбрасывать буфер и менять таймауты
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -3,23 +3,59 @@ package http
 import (
 	"net/http"
 
+	"github.com/oziev02/event-calendar-service/internal/caldav"
 	"github.com/oziev02/event-calendar-service/internal/http/handlers"
 	"github.com/oziev02/event-calendar-service/pkg/logger"
 )
 
 // Router настраивает маршруты HTTP сервера
-func Router(eventHandler *handlers.EventHandler, log logger.Logger) http.Handler {
+// adminHandler может быть nil, тогда административные маршруты не регистрируются.
+// Все маршруты, кроме подписки на календарь, проверки работоспособности
+// и административных, проходят authMiddleware.
+func Router(
+	eventHandler *handlers.EventHandler,
+	userHandler *handlers.UserHandler,
+	apiHandler *handlers.APIHandler,
+	feedHandler *handlers.FeedHandler,
+	caldavHandler *caldav.Handler,
+	streamHandler *handlers.StreamHandler,
+	adminHandler *handlers.AdminHandler,
+	authMiddleware *AuthMiddleware,
+	log logger.Logger,
+) http.Handler {
+	// Маршруты пользователей доступны только с учетными данными
 	mux := http.NewServeMux()
-	
+
 	mux.HandleFunc("/create_event", eventHandler.CreateEvent)
 	mux.HandleFunc("/update_event", eventHandler.UpdateEvent)
 	mux.HandleFunc("/delete_event", eventHandler.DeleteEvent)
 	mux.HandleFunc("/events_for_day", eventHandler.GetEventsForDay)
 	mux.HandleFunc("/events_for_week", eventHandler.GetEventsForWeek)
 	mux.HandleFunc("/events_for_month", eventHandler.GetEventsForMonth)
+	mux.HandleFunc("/user", userHandler.GetUser)
+	mux.HandleFunc("/update_user", userHandler.UpdateUser)
+
+	// Ресурсное API v1
+	mux.Handle(handlers.APIPrefix, apiHandler)
+	// Синхронизация с календарными приложениями по CalDAV
+	mux.Handle(caldav.Prefix, caldavHandler)
+	// Поток изменений календаря для веб-клиентов
+	mux.Handle(handlers.StreamPath, streamHandler)
+
+	root := http.NewServeMux()
+	// Подписка на календарь в формате iCalendar: секрет в адресе заменяет учетные данные
+	root.Handle(handlers.FeedPrefix, feedHandler)
+	// Проверка работоспособности для оркестратора контейнеров
+	root.HandleFunc(handlers.HealthPath, handlers.Health)
+	// Административные маршруты проверяют собственный токен
+	if adminHandler != nil {
+		root.HandleFunc("/admin/dead_letters", adminHandler.ListDeadLetters)
+		root.HandleFunc("/admin/dead_letters/replay", adminHandler.ReplayDeadLetters)
+		root.HandleFunc("/admin/api_keys", adminHandler.CreateAPIKey)
+	}
+	root.Handle("/", authMiddleware.Handler(mux))
 
 	// Применить middleware
 	loggingMiddleware := NewLoggingMiddleware(log)
-	return loggingMiddleware.Handler(mux)
+	return loggingMiddleware.Handler(root)
 }
-
@@ -0,0 +1,462 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+var (
+	ErrInvalidCalendar = errors.New("invalid calendar")
+	ErrMissingUID      = errors.New("missing UID")
+)
+
+// maxLineLength ограничивает длину развернутой строки содержимого
+const maxLineLength = 1 << 20
+
+// Item - событие VEVENT из календаря или ошибка его разбора
+type Item struct {
+	UID   string
+	Event *domain.Event
+	Err   error
+}
+
+// Decode разбирает календарь VCALENDAR и преобразует каждый VEVENT в событие.
+// Время без пояса (floating) и даты событий на весь день интерпретируются в loc.
+// Ошибка возвращается, только если нарушена структура календаря; ошибки
+// отдельных событий возвращаются в Item.Err, чтобы импорт остальных продолжился.
+func Decode(r io.Reader, loc *time.Location) ([]Item, error) {
+	root, err := parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(root.children) == 0 {
+		return nil, fmt.Errorf("%w: no VCALENDAR", ErrInvalidCalendar)
+	}
+
+	var items []Item
+	for _, cal := range root.children {
+		if cal.name != "VCALENDAR" {
+			return nil, fmt.Errorf("%w: unexpected component %s", ErrInvalidCalendar, cal.name)
+		}
+		zones := calendarZones(cal)
+		for _, child := range cal.children {
+			if child.name != "VEVENT" {
+				continue
+			}
+			event, err := decodeEvent(child, zones, loc)
+			item := Item{Event: event, Err: err}
+			if uid := child.get("UID"); uid != nil {
+				item.UID = uid.value
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// decodeEvent сопоставляет VEVENT с событием. UserID, ID и отметки времени
+// заполняет сервис при сохранении.
+func decodeEvent(c *component, zones map[string]*component, loc *time.Location) (*domain.Event, error) {
+	event := &domain.Event{}
+	if uid := c.get("UID"); uid != nil {
+		event.UID = uid.value
+	}
+	if event.UID == "" {
+		return nil, ErrMissingUID
+	}
+	if summary := c.get("SUMMARY"); summary != nil {
+		event.Text = unescapeText(summary.value)
+	}
+
+	dtstart := c.get("DTSTART")
+	if dtstart == nil {
+		return nil, fmt.Errorf("%w: missing DTSTART", domain.ErrInvalidDate)
+	}
+	start, kind, err := parseTime(dtstart, zones, loc)
+	if err != nil {
+		return nil, err
+	}
+	event.Date = start
+	event.AllDay = kind.date
+	event.TimeZone = kind.zone
+
+	switch end, duration := c.get("DTEND"), c.get("DURATION"); {
+	case end != nil:
+		if event.End, _, err = parseTime(end, zones, loc); err != nil {
+			return nil, err
+		}
+	case duration != nil:
+		d, err := parseDuration(duration.value)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("%w: DURATION %q", domain.ErrInvalidEndTime, duration.value)
+		}
+		if event.AllDay {
+			event.End = start.AddDate(0, 0, int(d/(24*time.Hour)))
+		} else {
+			event.End = start.Add(d)
+		}
+	}
+	// Окончание, совпадающее с окончанием по умолчанию, не хранится
+	if (event.AllDay && event.End.Equal(start.AddDate(0, 0, 1))) || event.End.Equal(start) {
+		event.End = time.Time{}
+	}
+
+	if rrule := c.get("RRULE"); rrule != nil {
+		if event.Recurrence, err = domain.ParseRecurrenceRule(rrule.value); err != nil {
+			return nil, err
+		}
+		for _, exdate := range c.all("EXDATE") {
+			for _, value := range strings.Split(exdate.value, ",") {
+				t, _, err := parseTime(&property{name: exdate.name, params: exdate.params, value: value}, zones, loc)
+				if err != nil {
+					return nil, err
+				}
+				event.ExDates = append(event.ExDates, t)
+			}
+		}
+	}
+	if recurrenceID := c.get("RECURRENCE-ID"); recurrenceID != nil {
+		t, _, err := parseTime(recurrenceID, zones, loc)
+		if err != nil {
+			return nil, err
+		}
+		event.RecurrenceID = &t
+	}
+
+	for _, alarm := range c.children {
+		if alarm.name != "VALARM" {
+			continue
+		}
+		reminder, err := decodeAlarm(alarm, event)
+		if err != nil {
+			return nil, err
+		}
+		event.Reminders = append(event.Reminders, reminder)
+	}
+	return event, nil
+}
+
+// decodeAlarm преобразует TRIGGER компонента VALARM в напоминание.
+// Напоминание после начала события хранится как абсолютное.
+func decodeAlarm(c *component, event *domain.Event) (domain.Reminder, error) {
+	trigger := c.get("TRIGGER")
+	if trigger == nil {
+		return domain.Reminder{}, fmt.Errorf("%w: VALARM without TRIGGER", domain.ErrInvalidReminder)
+	}
+	if strings.EqualFold(trigger.param("VALUE"), "DATE-TIME") {
+		at, err := time.Parse(utcFormat, trigger.value)
+		if err != nil {
+			return domain.Reminder{}, fmt.Errorf("%w: TRIGGER %q", domain.ErrInvalidReminder, trigger.value)
+		}
+		return domain.Reminder{At: &at}, nil
+	}
+
+	d, err := parseDuration(trigger.value)
+	if err != nil {
+		return domain.Reminder{}, fmt.Errorf("%w: TRIGGER %q", domain.ErrInvalidReminder, trigger.value)
+	}
+	// Смещение относительно окончания пересчитывается от начала
+	if strings.EqualFold(trigger.param("RELATED"), "END") {
+		d += event.EndTime().Sub(event.Date)
+	}
+	if d > 0 {
+		at := event.Date.Add(d)
+		return domain.Reminder{At: &at}, nil
+	}
+	return domain.Reminder{Offset: -d}, nil
+}
+
+// timeKind описывает, как задано время: датой и/или в каком поясе
+type timeKind struct {
+	date bool
+	zone string
+}
+
+// parseTime разбирает значение DATE или DATE-TIME с учетом параметров VALUE и TZID
+func parseTime(p *property, zones map[string]*component, loc *time.Location) (time.Time, timeKind, error) {
+	value := strings.TrimSpace(p.value)
+	if strings.EqualFold(p.param("VALUE"), "DATE") || len(value) == len(dateFormat) {
+		t, err := time.ParseInLocation(dateFormat, value, loc)
+		if err != nil {
+			return time.Time{}, timeKind{}, fmt.Errorf("%w: %s %q", domain.ErrInvalidDate, p.name, value)
+		}
+		return t, timeKind{date: true}, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(utcFormat, value)
+		if err != nil {
+			return time.Time{}, timeKind{}, fmt.Errorf("%w: %s %q", domain.ErrInvalidDate, p.name, value)
+		}
+		return t, timeKind{zone: "UTC"}, nil
+	}
+
+	kind := timeKind{}
+	if tzid := p.param("TZID"); tzid != "" {
+		zone, err := resolveZone(tzid, zones)
+		if err != nil {
+			return time.Time{}, timeKind{}, err
+		}
+		loc = zone
+		kind.zone = zone.String()
+	}
+	t, err := time.ParseInLocation(localFormat, value, loc)
+	if err != nil {
+		return time.Time{}, timeKind{}, fmt.Errorf("%w: %s %q", domain.ErrInvalidDate, p.name, value)
+	}
+	return t, kind, nil
+}
+
+// parseDuration разбирает длительность RFC 5545 (например, -PT15M, P1DT2H или P1W)
+func parseDuration(s string) (time.Duration, error) {
+	rest := s
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(rest, "-"):
+		sign, rest = -1, rest[1:]
+	case strings.HasPrefix(rest, "+"):
+		rest = rest[1:]
+	}
+	rest, ok := strings.CutPrefix(rest, "P")
+	if !ok || rest == "" {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+
+	var d time.Duration
+	inTime := false
+	number, digits, parts := 0, 0, 0
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			number = number*10 + int(r-'0')
+			digits++
+			continue
+		case r == 'T' && !inTime && digits == 0:
+			inTime = true
+			continue
+		}
+		if digits == 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		unit, ok := durationUnit(inTime, r)
+		if !ok {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		d += time.Duration(number) * unit
+		number, digits = 0, 0
+		parts++
+	}
+	if digits != 0 || parts == 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return sign * d, nil
+}
+
+// durationUnit возвращает величину части длительности; M означает минуты только после T
+func durationUnit(inTime bool, r rune) (time.Duration, bool) {
+	switch {
+	case !inTime && r == 'W':
+		return 7 * 24 * time.Hour, true
+	case !inTime && r == 'D':
+		return 24 * time.Hour, true
+	case inTime && r == 'H':
+		return time.Hour, true
+	case inTime && r == 'M':
+		return time.Minute, true
+	case inTime && r == 'S':
+		return time.Second, true
+	default:
+		return 0, false
+	}
+}
+
+// unescapeText восстанавливает значение типа TEXT (RFC 5545, 3.3.11)
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// component - компонент календаря (VCALENDAR, VEVENT, VALARM...)
+type component struct {
+	name     string
+	props    []*property
+	children []*component
+}
+
+// property - свойство компонента с параметрами
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+func (c *component) get(name string) *property {
+	for _, p := range c.props {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+func (c *component) all(name string) []*property {
+	var result []*property
+	for _, p := range c.props {
+		if p.name == name {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func (p *property) param(name string) string {
+	return p.params[name]
+}
+
+// parse разбирает поток строк содержимого в дерево компонентов.
+// Корневой компонент без имени содержит компоненты верхнего уровня.
+func parse(r io.Reader) (*component, error) {
+	root := &component{}
+	stack := []*component{root}
+
+	err := readLines(r, func(line string) error {
+		p, err := parseLine(line)
+		if err != nil {
+			return err
+		}
+		current := stack[len(stack)-1]
+		switch p.name {
+		case "BEGIN":
+			child := &component{name: strings.ToUpper(p.value)}
+			current.children = append(current.children, child)
+			stack = append(stack, child)
+		case "END":
+			if len(stack) == 1 || current.name != strings.ToUpper(p.value) {
+				return fmt.Errorf("%w: unexpected END:%s", ErrInvalidCalendar, p.value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 1 {
+				return fmt.Errorf("%w: property %s outside of component", ErrInvalidCalendar, p.name)
+			}
+			current.props = append(current.props, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("%w: unterminated %s", ErrInvalidCalendar, stack[len(stack)-1].name)
+	}
+	return root, nil
+}
+
+// readLines читает строки содержимого, объединяя строки продолжения
+// (начинающиеся с пробела или табуляции). Допускаются окончания CRLF и LF.
+func readLines(r io.Reader, fn func(string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxLineLength)
+
+	var line strings.Builder
+	flush := func() error {
+		if line.Len() == 0 {
+			return nil
+		}
+		s := line.String()
+		line.Reset()
+		return fn(s)
+	}
+
+	for scanner.Scan() {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if text != "" && (text[0] == ' ' || text[0] == '\t') {
+			if line.Len()+len(text) > maxLineLength {
+				return fmt.Errorf("%w: line too long", ErrInvalidCalendar)
+			}
+			line.WriteString(text[1:])
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		line.WriteString(text)
+	}
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: line too long", ErrInvalidCalendar)
+		}
+		return err
+	}
+	return flush()
+}
+
+// parseLine разбирает строку содержимого NAME;PARAM=VALUE,...:VALUE.
+// Значения параметров в кавычках могут содержать ":", ";" и ",".
+func parseLine(line string) (*property, error) {
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return nil, fmt.Errorf("%w: malformed line %q", ErrInvalidCalendar, line)
+	}
+	p := &property{name: strings.ToUpper(line[:i])}
+
+	for line[i] == ';' {
+		line = line[i+1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return nil, fmt.Errorf("%w: malformed parameter in %s", ErrInvalidCalendar, p.name)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var value strings.Builder
+		j := 0
+		for j < len(line) && line[j] != ';' && line[j] != ':' {
+			if line[j] == '"' {
+				end := strings.IndexByte(line[j+1:], '"')
+				if end < 0 {
+					return nil, fmt.Errorf("%w: unterminated quote in %s", ErrInvalidCalendar, p.name)
+				}
+				value.WriteString(line[j+1 : j+1+end])
+				j += end + 2
+				continue
+			}
+			value.WriteByte(line[j])
+			j++
+		}
+		if j == len(line) {
+			return nil, fmt.Errorf("%w: missing value in %s", ErrInvalidCalendar, p.name)
+		}
+		if p.params == nil {
+			p.params = make(map[string]string)
+		}
+		p.params[name] = value.String()
+		i = j
+	}
+
+	p.value = line[i+1:]
+	return p, nil
+}
@@ -0,0 +1,222 @@
+package ical
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const sampleCalendar = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"PRODID:-//Google Inc//Google Calendar 70.9054//EN\r\n" +
+	"BEGIN:VTIMEZONE\r\n" +
+	"TZID:Custom Moscow\r\n" +
+	"X-LIC-LOCATION:Europe/Moscow\r\n" +
+	"BEGIN:STANDARD\r\n" +
+	"TZOFFSETFROM:+0300\r\n" +
+	"TZOFFSETTO:+0300\r\n" +
+	"DTSTART:19700101T000000\r\n" +
+	"END:STANDARD\r\n" +
+	"END:VTIMEZONE\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240304T090000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20240304T091500\r\n" +
+	"RRULE:FREQ=WEEKLY;WKST=MO;BYDAY=MO\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20240311T090000,20240318T090000\r\n" +
+	"SUMMARY:Daily standup\\, team \\;A\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"ACTION:DISPLAY\r\n" +
+	"TRIGGER;RELATED=START:-PT10M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup@example.com\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20240325T090000\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240325T100000\r\n" +
+	"DURATION:PT30M\r\n" +
+	"SUMMARY:Standup (moved)\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:vacation@example.com\r\n" +
+	"DTSTART;VALUE=DATE:20240801\r\n" +
+	"DTEND;VALUE=DATE:20240815\r\n" +
+	"SUMMARY:Отпуск на море\r\n" +
+	"  с семьей\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER;VALUE=DATE-TIME:20240731T090000Z\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:call@example.com\r\n" +
+	"DTSTART;TZID=Custom Moscow:20240115T100000\r\n" +
+	"DTEND;TZID=Custom Moscow:20240115T110000\r\n" +
+	"SUMMARY:Call\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"TRIGGER;RELATED=END:-PT5M\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"DTSTART:20240115T100000Z\r\n" +
+	"SUMMARY:No UID\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:hourly@example.com\r\n" +
+	"DTSTART:20240115T100000Z\r\n" +
+	"RRULE:FREQ=HOURLY\r\n" +
+	"SUMMARY:Unsupported rule\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func TestDecode(t *testing.T) {
+	items, err := Decode(strings.NewReader(sampleCalendar), time.UTC)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if len(items) != 6 {
+		t.Fatalf("Expected 6 items, got %d", len(items))
+	}
+
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	series := items[0].Event
+	if items[0].Err != nil || series.UID != "standup@example.com" {
+		t.Fatalf("Unexpected series item %+v", items[0])
+	}
+	if series.Text != "Daily standup, team ;A" {
+		t.Errorf("Unexpected text %q", series.Text)
+	}
+	if !series.Date.Equal(time.Date(2024, 3, 4, 9, 0, 0, 0, berlin)) || series.TimeZone != "Europe/Berlin" {
+		t.Errorf("Unexpected start %v in %q", series.Date, series.TimeZone)
+	}
+	if series.End.Sub(series.Date) != 15*time.Minute {
+		t.Errorf("Unexpected end %v", series.End)
+	}
+	if series.Recurrence == nil || series.Recurrence.Freq != domain.FreqWeekly || len(series.ExDates) != 2 {
+		t.Errorf("Unexpected recurrence %+v, exdates %v", series.Recurrence, series.ExDates)
+	}
+	if len(series.Reminders) != 1 || series.Reminders[0].Offset != 10*time.Minute {
+		t.Errorf("Unexpected reminders %+v", series.Reminders)
+	}
+
+	override := items[1].Event
+	if override.RecurrenceID == nil || !override.RecurrenceID.Equal(time.Date(2024, 3, 25, 9, 0, 0, 0, berlin)) {
+		t.Errorf("Unexpected RECURRENCE-ID %v", override.RecurrenceID)
+	}
+	if override.End.Sub(override.Date) != 30*time.Minute {
+		t.Errorf("DURATION should set the end, got %v", override.End)
+	}
+
+	vacation := items[2].Event
+	if !vacation.AllDay || vacation.Text != "Отпуск на море с семьей" {
+		t.Errorf("Unexpected all-day event %+v", vacation)
+	}
+	if !vacation.End.Equal(time.Date(2024, 8, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected all-day end %v", vacation.End)
+	}
+	if len(vacation.Reminders) != 1 || vacation.Reminders[0].At == nil {
+		t.Errorf("Expected absolute reminder, got %+v", vacation.Reminders)
+	}
+
+	call := items[3].Event
+	if call.TimeZone != "Europe/Moscow" || !call.Date.Equal(time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)) {
+		t.Errorf("X-LIC-LOCATION should resolve the zone, got %v in %q", call.Date, call.TimeZone)
+	}
+	// За 5 минут до окончания часового события - через 55 минут после начала
+	if len(call.Reminders) != 1 || call.Reminders[0].At == nil || !call.Reminders[0].At.Equal(call.Date.Add(55*time.Minute)) {
+		t.Errorf("RELATED=END trigger should be relative to the end, got %+v", call.Reminders)
+	}
+
+	if !errors.Is(items[4].Err, ErrMissingUID) {
+		t.Errorf("Expected ErrMissingUID, got %v", items[4].Err)
+	}
+	if !errors.Is(items[5].Err, domain.ErrInvalidRecurrence) || items[5].UID != "hourly@example.com" {
+		t.Errorf("Expected ErrInvalidRecurrence with UID, got %+v", items[5])
+	}
+}
+
+func TestDecode_FloatingTime(t *testing.T) {
+	loc, _ := time.LoadLocation("Asia/Tokyo")
+	items, err := Decode(strings.NewReader("BEGIN:VCALENDAR\nBEGIN:VEVENT\nUID:1\nDTSTART:20240115T100000\nSUMMARY:Floating\nEND:VEVENT\nEND:VCALENDAR\n"), loc)
+	if err != nil || len(items) != 1 || items[0].Err != nil {
+		t.Fatalf("Decode failed: %v %+v", err, items)
+	}
+	event := items[0].Event
+	if !event.Date.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, loc)) || event.TimeZone != "" {
+		t.Errorf("Floating time should use the default zone, got %v in %q", event.Date, event.TimeZone)
+	}
+}
+
+func TestDecode_InvalidCalendar(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n",
+		"SUMMARY:outside\r\n",
+		"BEGIN:VCALENDAR\r\nno colon here\r\nEND:VCALENDAR\r\n",
+		"BEGIN:VEVENT\r\nEND:VEVENT\r\n",
+	} {
+		if _, err := Decode(strings.NewReader(data), time.UTC); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("Decode(%q): expected ErrInvalidCalendar, got %v", data, err)
+		}
+	}
+}
+
+func TestDecode_RoundTrip(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, loc)
+	rule, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO;COUNT=10")
+	at := start.Add(-24 * time.Hour)
+	original := &domain.Event{
+		ID:         "e1",
+		Text:       "Line one\nLine two; with, punctuation \\ slash",
+		Date:       start,
+		End:        start.Add(time.Hour),
+		TimeZone:   "Europe/Berlin",
+		Recurrence: rule,
+		ExDates:    []time.Time{start.AddDate(0, 0, 7)},
+		Reminders:  []domain.Reminder{{Offset: 15 * time.Minute}, {At: &at}},
+		UpdatedAt:  start,
+	}
+
+	items, err := Decode(strings.NewReader(string(Encode("test", []*domain.Event{original}))), time.UTC)
+	if err != nil || len(items) != 1 || items[0].Err != nil {
+		t.Fatalf("Decode failed: %v %+v", err, items)
+	}
+	got := items[0].Event
+	if got.UID != "e1" || got.Text != original.Text || !got.Date.Equal(original.Date) || !got.End.Equal(original.End) {
+		t.Errorf("Round trip changed the event: %+v", got)
+	}
+	if got.TimeZone != "Europe/Berlin" || got.Recurrence.String() != rule.String() || len(got.ExDates) != 1 {
+		t.Errorf("Round trip changed the recurrence: %+v", got)
+	}
+	if len(got.Reminders) != 2 || got.Reminders[0].Offset != 15*time.Minute || !got.Reminders[1].At.Equal(at) {
+		t.Errorf("Round trip changed the reminders: %+v", got.Reminders)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"-PT15M", -15 * time.Minute, false},
+		{"+PT1H30M", 90 * time.Minute, false},
+		{"P1DT2H", 26 * time.Hour, false},
+		{"P2W", 14 * 24 * time.Hour, false},
+		{"PT0S", 0, false},
+		{"P1M", 0, true},
+		{"PT", 0, true},
+		{"15M", 0, true},
+		{"PT5", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseDuration(tt.s)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseDuration(%q) = %v, %v", tt.s, got, err)
+		}
+	}
+}
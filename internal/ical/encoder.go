@@ -0,0 +1,220 @@
+// Package ical реализует преобразование событий в формат iCalendar (RFC 5545) и обратно
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const (
+	prodID = "-//event-calendar-service//EN"
+
+	utcFormat   = "20060102T150405Z"
+	localFormat = "20060102T150405"
+	dateFormat  = "20060102"
+
+	// maxLineOctets - наибольшая длина строки без переноса (RFC 5545, 3.1)
+	maxLineOctets = 75
+)
+
+// Encode сериализует события в календарь VCALENDAR с именем name.
+// Серии выводятся с RRULE и EXDATE, измененные вхождения - отдельными VEVENT
+// с UID серии и RECURRENCE-ID. Для каждого пояса событий добавляется VTIMEZONE.
+// Результат зависит только от событий, поэтому годится для вычисления ETag.
+func Encode(name string, events []*domain.Event) []byte {
+	return encode(events, func(b *bytes.Buffer) {
+		writeLine(b, "METHOD:PUBLISH")
+		if name != "" {
+			writeLine(b, "X-WR-CALNAME:"+escapeText(name))
+		}
+	})
+}
+
+// EncodeObject сериализует календарный объект CalDAV (RFC 4791, 4.1):
+// события с одним UID без свойства METHOD
+func EncodeObject(events []*domain.Event) []byte {
+	return encode(events, func(*bytes.Buffer) {})
+}
+
+func encode(events []*domain.Event, header func(*bytes.Buffer)) []byte {
+	var b bytes.Buffer
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+prodID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	header(&b)
+
+	for _, tz := range timezones(events) {
+		writeTimezone(&b, tz.loc, tz.year)
+	}
+	for _, event := range events {
+		writeEvent(&b, event)
+	}
+
+	writeLine(&b, "END:VCALENDAR")
+	return b.Bytes()
+}
+
+// UID возвращает идентификатор события в iCalendar: UID, с которым событие
+// было импортировано, или ID. Измененные вхождения используют UID серии,
+// чтобы клиенты связали их с ней через RECURRENCE-ID.
+func UID(event *domain.Event) string {
+	if event.UID != "" {
+		return event.UID
+	}
+	if event.IsOverride() {
+		return event.SeriesID
+	}
+	return event.ID
+}
+
+func writeEvent(b *bytes.Buffer, e *domain.Event) {
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+escapeText(UID(e)))
+	writeLine(b, "DTSTAMP:"+e.UpdatedAt.UTC().Format(utcFormat))
+	if !e.CreatedAt.IsZero() {
+		writeLine(b, "CREATED:"+e.CreatedAt.UTC().Format(utcFormat))
+	}
+	if !e.UpdatedAt.IsZero() {
+		writeLine(b, "LAST-MODIFIED:"+e.UpdatedAt.UTC().Format(utcFormat))
+	}
+
+	writeLine(b, timeProperty("DTSTART", e, e.Date))
+	if end := e.EndTime(); end.After(e.Date) {
+		writeLine(b, timeProperty("DTEND", e, end))
+	}
+	if e.RecurrenceID != nil {
+		writeLine(b, timeProperty("RECURRENCE-ID", e, *e.RecurrenceID))
+	}
+	writeLine(b, "SUMMARY:"+escapeText(e.Text))
+
+	if e.Recurrence != nil {
+		writeLine(b, "RRULE:"+e.Recurrence.String())
+		if len(e.ExDates) > 0 {
+			writeLine(b, timeListProperty("EXDATE", e, e.ExDates))
+		}
+	}
+
+	if e.ReminderTime != nil {
+		writeAlarm(b, e.Text, "TRIGGER;VALUE=DATE-TIME:"+e.ReminderTime.UTC().Format(utcFormat))
+	}
+	for _, reminder := range e.Reminders {
+		if reminder.IsRelative() {
+			writeAlarm(b, e.Text, "TRIGGER:"+formatDuration(-reminder.Offset))
+		} else {
+			writeAlarm(b, e.Text, "TRIGGER;VALUE=DATE-TIME:"+reminder.At.UTC().Format(utcFormat))
+		}
+	}
+
+	writeLine(b, "END:VEVENT")
+}
+
+func writeAlarm(b *bytes.Buffer, text, trigger string) {
+	writeLine(b, "BEGIN:VALARM")
+	writeLine(b, "ACTION:DISPLAY")
+	writeLine(b, "DESCRIPTION:"+escapeText(text))
+	writeLine(b, trigger)
+	writeLine(b, "END:VALARM")
+}
+
+// timeProperty форматирует свойство даты события: дату для событий на весь день,
+// местное время с TZID для событий с поясом, иначе время в UTC
+func timeProperty(name string, e *domain.Event, t time.Time) string {
+	return timeListProperty(name, e, []time.Time{t})
+}
+
+func timeListProperty(name string, e *domain.Event, times []time.Time) string {
+	loc := e.Location()
+	values := make([]string, len(times))
+	switch {
+	case e.AllDay:
+		for i, t := range times {
+			values[i] = t.In(loc).Format(dateFormat)
+		}
+		return name + ";VALUE=DATE:" + strings.Join(values, ",")
+	case hasTZID(e):
+		for i, t := range times {
+			values[i] = t.In(loc).Format(localFormat)
+		}
+		return name + ";TZID=" + e.TimeZone + ":" + strings.Join(values, ",")
+	default:
+		for i, t := range times {
+			values[i] = t.UTC().Format(utcFormat)
+		}
+		return name + ":" + strings.Join(values, ",")
+	}
+}
+
+// hasTZID проверяет, выводится ли время события в его поясе с TZID
+func hasTZID(e *domain.Event) bool {
+	return !e.AllDay && e.TimeZone != "" && e.TimeZone != "UTC"
+}
+
+// formatDuration форматирует длительность в формате RFC 5545 (например, -PT15M или P1D)
+func formatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	if days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+	}
+	if d == 0 && days > 0 {
+		return b.String()
+	}
+
+	b.WriteByte('T')
+	hours, minutes, seconds := d/time.Hour, (d%time.Hour)/time.Minute, (d%time.Minute)/time.Second
+	if hours > 0 {
+		fmt.Fprintf(&b, "%dH", hours)
+	}
+	if minutes > 0 {
+		fmt.Fprintf(&b, "%dM", minutes)
+	}
+	if seconds > 0 || (hours == 0 && minutes == 0) {
+		fmt.Fprintf(&b, "%dS", seconds)
+	}
+	return b.String()
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+// writeLine записывает строку содержимого с CRLF, перенося ее на строки
+// продолжения не длиннее 75 октетов без разрыва символов UTF-8
+func writeLine(b *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Пробел в начале строки продолжения входит в ее длину
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
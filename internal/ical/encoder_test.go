@@ -0,0 +1,184 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// unfold объединяет строки продолжения и разбивает календарь на строки содержимого
+func unfold(data []byte) []string {
+	text := strings.ReplaceAll(string(data), "\r\n ", "")
+	return strings.Split(strings.TrimSuffix(text, "\r\n"), "\r\n")
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEncode_Event(t *testing.T) {
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	reminder := date.Add(-time.Hour)
+	event := &domain.Event{
+		ID:           "e1",
+		UserID:       "user1",
+		Text:         "Meeting; room 1, floor 2\nBring notes",
+		Date:         date,
+		End:          date.Add(90 * time.Minute),
+		ReminderTime: &reminder,
+		Reminders:    []domain.Reminder{{Offset: 15 * time.Minute}},
+		CreatedAt:    date.AddDate(0, 0, -1),
+		UpdatedAt:    date.AddDate(0, 0, -1),
+	}
+
+	lines := unfold(Encode("user1", []*domain.Event{event}))
+	for _, want := range []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + prodID,
+		"X-WR-CALNAME:user1",
+		"UID:e1",
+		"DTSTAMP:20240114T100000Z",
+		"DTSTART:20240115T100000Z",
+		"DTEND:20240115T113000Z",
+		`SUMMARY:Meeting\; room 1\, floor 2\nBring notes`,
+		"TRIGGER;VALUE=DATE-TIME:20240115T090000Z",
+		"TRIGGER:-PT15M",
+		"END:VCALENDAR",
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("Expected line %q in:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	if containsLine(lines, "BEGIN:VTIMEZONE") {
+		t.Errorf("UTC events should not need VTIMEZONE")
+	}
+}
+
+func TestEncode_AllDayAndSeries(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, loc)
+	rule, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO")
+	series := &domain.Event{
+		ID:         "series",
+		Text:       "Standup",
+		Date:       start,
+		End:        start.Add(15 * time.Minute),
+		TimeZone:   "Europe/Berlin",
+		Recurrence: rule,
+		ExDates:    []time.Time{start.AddDate(0, 0, 7)},
+	}
+	moved := start.AddDate(0, 0, 14)
+	override := &domain.Event{
+		ID:           "override",
+		Text:         "Standup (moved)",
+		Date:         moved.Add(time.Hour),
+		TimeZone:     "Europe/Berlin",
+		SeriesID:     "series",
+		RecurrenceID: &moved,
+	}
+	holiday := &domain.Event{
+		ID:     "holiday",
+		Text:   "Holiday",
+		Date:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		AllDay: true,
+	}
+
+	lines := unfold(Encode("", []*domain.Event{series, override, holiday}))
+	for _, want := range []string{
+		"DTSTART;TZID=Europe/Berlin:20240304T090000",
+		"RRULE:" + rule.String(),
+		"EXDATE;TZID=Europe/Berlin:20240311T090000",
+		"UID:series",
+		"RECURRENCE-ID;TZID=Europe/Berlin:20240318T090000",
+		"DTSTART;VALUE=DATE:20240501",
+		"DTEND;VALUE=DATE:20240502",
+		"TZID:Europe/Berlin",
+	} {
+		if !containsLine(lines, want) {
+			t.Errorf("Expected line %q in:\n%s", want, strings.Join(lines, "\n"))
+		}
+	}
+	if n := strings.Count(strings.Join(lines, "\n"), "BEGIN:VTIMEZONE"); n != 1 {
+		t.Errorf("Expected one VTIMEZONE, got %d", n)
+	}
+}
+
+func TestWriteTimezone_Transitions(t *testing.T) {
+	loc, _ := time.LoadLocation("Europe/Berlin")
+	var b bytes.Buffer
+	writeTimezone(&b, loc, 2024)
+	lines := unfold(b.Bytes())
+
+	want := []string{
+		"BEGIN:DAYLIGHT",
+		"DTSTART:20240331T020000",
+		"RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU",
+		"TZOFFSETFROM:+0100",
+		"TZOFFSETTO:+0200",
+		"TZNAME:CEST",
+		"BEGIN:STANDARD",
+		"DTSTART:20241027T030000",
+		"RRULE:FREQ=YEARLY;BYMONTH=10;BYDAY=-1SU",
+		"TZOFFSETFROM:+0200",
+		"TZOFFSETTO:+0100",
+	}
+	for _, line := range want {
+		if !containsLine(lines, line) {
+			t.Errorf("Expected line %q in:\n%s", line, strings.Join(lines, "\n"))
+		}
+	}
+
+	fixed, _ := time.LoadLocation("Asia/Tokyo")
+	b.Reset()
+	writeTimezone(&b, fixed, 2024)
+	lines = unfold(b.Bytes())
+	if !containsLine(lines, "TZOFFSETTO:+0900") || containsLine(lines, "BEGIN:DAYLIGHT") {
+		t.Errorf("Unexpected VTIMEZONE for fixed zone:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestWriteLine_Folding(t *testing.T) {
+	var b bytes.Buffer
+	long := "SUMMARY:" + strings.Repeat("Событие ", 30)
+	writeLine(&b, long)
+
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("Line exceeds %d octets: %d", maxLineOctets, len(line))
+		}
+		if !strings.HasPrefix(line, "SUMMARY") && !strings.HasPrefix(line, " ") {
+			t.Errorf("Continuation line must start with a space: %q", line)
+		}
+	}
+	if got := unfold(b.Bytes()); len(got) != 1 || got[0] != long {
+		t.Errorf("Unfolded line differs from the original")
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-15 * time.Minute, "-PT15M"},
+		{-90 * time.Minute, "-PT1H30M"},
+		{-24 * time.Hour, "-P1D"},
+		{-26 * time.Hour, "-P1DT2H"},
+		{0, "PT0S"},
+		{30 * time.Second, "PT30S"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.d); got != tt.want {
+			t.Errorf("formatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
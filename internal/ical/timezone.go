@@ -0,0 +1,236 @@
+package ical
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// zoneUsage - пояс, используемый событиями календаря, и самый ранний год событий в нем
+type zoneUsage struct {
+	loc  *time.Location
+	year int
+}
+
+// timezones возвращает пояса событий, время которых выводится с TZID, по имени
+func timezones(events []*domain.Event) []zoneUsage {
+	byName := make(map[string]*zoneUsage)
+	for _, event := range events {
+		if !hasTZID(event) {
+			continue
+		}
+		loc := event.Location()
+		year := event.Date.In(loc).Year()
+		if usage, ok := byName[event.TimeZone]; ok {
+			if year < usage.year {
+				usage.year = year
+			}
+			continue
+		}
+		byName[event.TimeZone] = &zoneUsage{loc: loc, year: year}
+	}
+
+	result := make([]zoneUsage, 0, len(byName))
+	for _, usage := range byName {
+		result = append(result, *usage)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].loc.String() < result[j].loc.String()
+	})
+	return result
+}
+
+// transition - переход пояса на другое смещение
+type transition struct {
+	at         time.Time
+	offsetFrom int
+	offsetTo   int
+	name       string
+	dst        bool
+}
+
+// writeTimezone описывает пояс компонентом VTIMEZONE по переходам года year.
+// Переходы повторяются ежегодно правилом RRULE (например, последнее воскресенье
+// марта), а пояс без переходов описывается одним смещением.
+func writeTimezone(b *bytes.Buffer, loc *time.Location, year int) {
+	writeLine(b, "BEGIN:VTIMEZONE")
+	writeLine(b, "TZID:"+loc.String())
+
+	transitions := yearTransitions(loc, year)
+	if len(transitions) == 0 {
+		name, offset := time.Date(year, 1, 1, 0, 0, 0, 0, loc).Zone()
+		writeLine(b, "BEGIN:STANDARD")
+		writeLine(b, "DTSTART:19700101T000000")
+		writeLine(b, "TZOFFSETFROM:"+formatOffset(offset))
+		writeLine(b, "TZOFFSETTO:"+formatOffset(offset))
+		writeLine(b, "TZNAME:"+name)
+		writeLine(b, "END:STANDARD")
+	}
+
+	for _, tr := range transitions {
+		component := "STANDARD"
+		if tr.dst {
+			component = "DAYLIGHT"
+		}
+		// DTSTART задается местным временем до перехода
+		local := tr.at.In(time.FixedZone("", tr.offsetFrom))
+		writeLine(b, "BEGIN:"+component)
+		writeLine(b, "DTSTART:"+local.Format(localFormat))
+		writeLine(b, fmt.Sprintf("RRULE:FREQ=YEARLY;BYMONTH=%d;BYDAY=%s", local.Month(), weekdayOrdinal(local)))
+		writeLine(b, "TZOFFSETFROM:"+formatOffset(tr.offsetFrom))
+		writeLine(b, "TZOFFSETTO:"+formatOffset(tr.offsetTo))
+		writeLine(b, "TZNAME:"+tr.name)
+		writeLine(b, "END:"+component)
+	}
+
+	writeLine(b, "END:VTIMEZONE")
+}
+
+// yearTransitions находит переходы пояса в течение года с точностью до минуты
+func yearTransitions(loc *time.Location, year int) []transition {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, loc)
+	end := start.AddDate(1, 0, 0)
+
+	var result []transition
+	prev := start
+	_, prevOffset := prev.Zone()
+	for prev.Before(end) {
+		t := prev.Add(24 * time.Hour)
+		_, offset := t.Zone()
+		if offset != prevOffset {
+			// Двоичный поиск момента смены смещения между prev и t
+			lo, hi := prev, t
+			for hi.Sub(lo) > time.Minute {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.Zone(); o == prevOffset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			at := hi.Truncate(time.Minute)
+			if !at.Before(end) {
+				break
+			}
+			name, _ := at.Zone()
+			result = append(result, transition{
+				at:         at,
+				offsetFrom: prevOffset,
+				offsetTo:   offset,
+				name:       name,
+				dst:        at.IsDST(),
+			})
+		}
+		prev, prevOffset = t, offset
+	}
+	return result
+}
+
+// weekdayOrdinal возвращает день недели даты с его номером в месяце
+// в формате BYDAY: 2SU - второе воскресенье, -1SU - последнее
+func weekdayOrdinal(t time.Time) string {
+	code := weekdayCodes[t.Weekday()]
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	if t.Day()+7 > daysInMonth {
+		return "-1" + code
+	}
+	return fmt.Sprintf("%d%s", (t.Day()-1)/7+1, code)
+}
+
+var weekdayCodes = map[time.Weekday]string{
+	time.Monday:    "MO",
+	time.Tuesday:   "TU",
+	time.Wednesday: "WE",
+	time.Thursday:  "TH",
+	time.Friday:    "FR",
+	time.Saturday:  "SA",
+	time.Sunday:    "SU",
+}
+
+// formatOffset форматирует смещение от UTC в формате UTC-OFFSET (+0300, -0430)
+func formatOffset(seconds int) string {
+	sign := '+'
+	if seconds < 0 {
+		sign = '-'
+		seconds = -seconds
+	}
+	s := fmt.Sprintf("%c%02d%02d", sign, seconds/3600, seconds%3600/60)
+	if rest := seconds % 60; rest != 0 {
+		s += fmt.Sprintf("%02d", rest)
+	}
+	return s
+}
+
+// calendarZones возвращает компоненты VTIMEZONE календаря по TZID
+func calendarZones(cal *component) map[string]*component {
+	zones := make(map[string]*component)
+	for _, child := range cal.children {
+		if child.name != "VTIMEZONE" {
+			continue
+		}
+		if tzid := child.get("TZID"); tzid != nil {
+			zones[tzid.value] = child
+		}
+	}
+	return zones
+}
+
+// resolveZone находит IANA-пояс для TZID: по имени, по X-LIC-LOCATION
+// из VTIMEZONE календаря или по таблице имен поясов Windows (Outlook)
+func resolveZone(tzid string, zones map[string]*component) (*time.Location, error) {
+	candidates := []string{tzid, strings.TrimPrefix(tzid, "/")}
+	if zone, ok := zones[tzid]; ok {
+		if location := zone.get("X-LIC-LOCATION"); location != nil {
+			candidates = append(candidates, location.value)
+		}
+	}
+	if name, ok := windowsZones[tzid]; ok {
+		candidates = append(candidates, name)
+	}
+
+	for _, name := range candidates {
+		if name == "" {
+			continue
+		}
+		if loc, err := domain.LoadLocation(name); err == nil {
+			return loc, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", domain.ErrInvalidTimeZone, tzid)
+}
+
+// windowsZones сопоставляет распространенные имена поясов Windows с IANA
+var windowsZones = map[string]string{
+	"UTC":                            "UTC",
+	"GMT Standard Time":              "Europe/London",
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Romance Standard Time":          "Europe/Paris",
+	"Central Europe Standard Time":   "Europe/Budapest",
+	"Central European Standard Time": "Europe/Warsaw",
+	"E. Europe Standard Time":        "Europe/Chisinau",
+	"FLE Standard Time":              "Europe/Kiev",
+	"GTB Standard Time":              "Europe/Bucharest",
+	"Kaliningrad Standard Time":      "Europe/Kaliningrad",
+	"Russian Standard Time":          "Europe/Moscow",
+	"Samara Standard Time":           "Europe/Samara",
+	"Ekaterinburg Standard Time":     "Asia/Yekaterinburg",
+	"Omsk Standard Time":             "Asia/Omsk",
+	"N. Central Asia Standard Time":  "Asia/Novosibirsk",
+	"North Asia Standard Time":       "Asia/Krasnoyarsk",
+	"North Asia East Standard Time":  "Asia/Irkutsk",
+	"Yakutsk Standard Time":          "Asia/Yakutsk",
+	"Vladivostok Standard Time":      "Asia/Vladivostok",
+	"Magadan Standard Time":          "Asia/Magadan",
+	"Turkey Standard Time":           "Europe/Istanbul",
+	"China Standard Time":            "Asia/Shanghai",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"India Standard Time":            "Asia/Kolkata",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"Pacific Standard Time":          "America/Los_Angeles",
+}
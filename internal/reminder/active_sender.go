@@ -0,0 +1,39 @@
+package reminder
+
+import (
+	"errors"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// ActiveEventSender отправляет напоминание, только если событие все еще
+// существует и не архивировано, а участник события не отказался от участия
+type ActiveEventSender struct {
+	repo domain.EventRepository
+	next domain.ReminderSender
+}
+
+// NewActiveEventSender создает отправитель, проверяющий событие перед отправкой
+func NewActiveEventSender(repo domain.EventRepository, next domain.ReminderSender) *ActiveEventSender {
+	return &ActiveEventSender{repo: repo, next: next}
+}
+
+// SendReminder отправляет напоминание через next или молча пропускает его
+func (s *ActiveEventSender) SendReminder(task *domain.ReminderTask) error {
+	event, err := s.repo.GetByID(task.EventOwner(), task.EventID)
+	if errors.Is(err, domain.ErrEventNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if event.Archived {
+		return nil
+	}
+	if task.OwnerID != "" {
+		if attendee := event.Attendee(task.UserID); attendee == nil || attendee.Status != domain.PartStatAccepted {
+			return nil
+		}
+	}
+	return s.next.SendReminder(task)
+}
@@ -0,0 +1,35 @@
+package reminder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+type countingSender int
+
+func (c *countingSender) SendReminder(task *domain.ReminderTask) error {
+	*c++
+	return nil
+}
+
+func TestActiveEventSender_SkipsMissingAndArchived(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	date := time.Now()
+	repo.Create(&domain.Event{ID: "active", UserID: "user1", Text: "Active", Date: date})
+	repo.Create(&domain.Event{ID: "archived", UserID: "user1", Text: "Archived", Date: date, Archived: true})
+
+	var next countingSender
+	sender := NewActiveEventSender(repo, &next)
+
+	for _, id := range []string{"active", "archived", "deleted"} {
+		if err := sender.SendReminder(&domain.ReminderTask{EventID: id, UserID: "user1"}); err != nil {
+			t.Errorf("Unexpected error for %s: %v", id, err)
+		}
+	}
+	if next != 1 {
+		t.Errorf("Expected 1 delivered reminder, got %d", next)
+	}
+}
@@ -0,0 +1,91 @@
+package reminder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// ErrChannelDisabled возвращается, если пользователь выбрал канал,
+// который не включен на сервере
+var ErrChannelDisabled = errors.New("reminder channel is disabled")
+
+// CompositeReminderSender доставляет напоминание во все каналы пользователя
+type CompositeReminderSender struct {
+	senders map[domain.ReminderChannel]domain.ReminderSender
+	order   []domain.ReminderChannel
+	users   domain.UserRepository
+}
+
+// NewCompositeReminderSender создает отправитель без каналов.
+// Каналы включаются через Register, порядок регистрации задает порядок доставки.
+func NewCompositeReminderSender(users domain.UserRepository) *CompositeReminderSender {
+	return &CompositeReminderSender{
+		senders: make(map[domain.ReminderChannel]domain.ReminderSender),
+		users:   users,
+	}
+}
+
+// Register включает канал доставки
+func (s *CompositeReminderSender) Register(channel domain.ReminderChannel, sender domain.ReminderSender) {
+	if _, exists := s.senders[channel]; !exists {
+		s.order = append(s.order, channel)
+	}
+	s.senders[channel] = sender
+}
+
+// SendReminder отправляет напоминание во все каналы пользователя или, при повторной
+// попытке, только в task.Channels. Ошибки отдельных каналов объединяются
+// в domain.ChannelDeliveryError, остальные каналы при этом получают напоминание.
+func (s *CompositeReminderSender) SendReminder(task *domain.ReminderTask) error {
+	channels := task.Channels
+	if len(channels) == 0 {
+		var err error
+		if channels, err = s.channelsFor(task.UserID); err != nil {
+			return err
+		}
+	}
+
+	var failed []domain.ReminderChannel
+	var errs []error
+	for _, channel := range channels {
+		sender, ok := s.senders[channel]
+		if !ok {
+			failed = append(failed, channel)
+			errs = append(errs, fmt.Errorf("%w: %s", ErrChannelDisabled, channel))
+			continue
+		}
+		if err := sender.SendReminder(task); err != nil {
+			failed = append(failed, channel)
+			errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return &domain.ChannelDeliveryError{Failed: failed, Err: errors.Join(errs...)}
+}
+
+// channelsFor возвращает каналы пользователя. Без явного выбора используются
+// все включенные каналы, для которых у пользователя есть адрес.
+func (s *CompositeReminderSender) channelsFor(userID string) ([]domain.ReminderChannel, error) {
+	user, err := s.users.Get(userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		user = &domain.User{ID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if len(user.Channels) > 0 {
+		return user.Channels, nil
+	}
+
+	var channels []domain.ReminderChannel
+	for _, channel := range s.order {
+		if user.CanReceive(channel) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels, nil
+}
@@ -0,0 +1,79 @@
+package reminder
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+type failingSender struct{}
+
+func (failingSender) SendReminder(task *domain.ReminderTask) error {
+	return errors.New("unavailable")
+}
+
+func TestCompositeReminderSender_RoutesByUserChannels(t *testing.T) {
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "chosen", Email: "a@example.com", Channels: []domain.ReminderChannel{domain.ChannelEmail}})
+	users.Save(&domain.User{ID: "default", WebhookURL: "http://example.com/hook"})
+
+	var console, email, webhook countingSender
+	sender := NewCompositeReminderSender(users)
+	sender.Register(domain.ChannelConsole, &console)
+	sender.Register(domain.ChannelEmail, &email)
+	sender.Register(domain.ChannelWebhook, &webhook)
+
+	// Явно выбранный канал
+	if err := sender.SendReminder(&domain.ReminderTask{UserID: "chosen"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if console != 0 || email != 1 || webhook != 0 {
+		t.Errorf("Expected only email, got console=%d email=%d webhook=%d", console, email, webhook)
+	}
+
+	// Без выбора - все каналы, для которых есть адрес
+	if err := sender.SendReminder(&domain.ReminderTask{UserID: "default"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if console != 1 || email != 1 || webhook != 1 {
+		t.Errorf("Expected console and webhook, got console=%d email=%d webhook=%d", console, email, webhook)
+	}
+}
+
+func TestCompositeReminderSender_JoinsErrors(t *testing.T) {
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{
+		ID:       "user1",
+		Email:    "a@example.com",
+		Channels: []domain.ReminderChannel{domain.ChannelWebhook, domain.ChannelConsole, domain.ChannelEmail},
+	})
+
+	var console countingSender
+	sender := NewCompositeReminderSender(users)
+	sender.Register(domain.ChannelConsole, &console)
+	sender.Register(domain.ChannelEmail, failingSender{})
+
+	err := sender.SendReminder(&domain.ReminderTask{UserID: "user1"})
+	if !errors.Is(err, ErrChannelDisabled) {
+		t.Errorf("Expected error %v, got %v", ErrChannelDisabled, err)
+	}
+	if console != 1 {
+		t.Errorf("Expected console delivery despite failures, got %d", console)
+	}
+	var deliveryErr *domain.ChannelDeliveryError
+	if !errors.As(err, &deliveryErr) || !slices.Equal(deliveryErr.Failed, []domain.ReminderChannel{domain.ChannelWebhook, domain.ChannelEmail}) {
+		t.Fatalf("Expected failed webhook and email channels, got %v", err)
+	}
+
+	// Повторная попытка доставляет напоминание только в не получившие его каналы
+	sender.Register(domain.ChannelEmail, &console)
+	if err := sender.SendReminder(&domain.ReminderTask{UserID: "user1", Channels: []domain.ReminderChannel{domain.ChannelEmail}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if console != 2 {
+		t.Errorf("Expected one more delivery, got %d", console)
+	}
+}
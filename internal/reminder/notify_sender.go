@@ -0,0 +1,35 @@
+package reminder
+
+import (
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// NotifySender сообщает получателю изменений об отправке напоминания,
+// чтобы открытые клиенты показали его без почты и webhook
+type NotifySender struct {
+	notifier domain.ChangeNotifier
+	next     domain.ReminderSender
+}
+
+// NewNotifySender создает отправитель, сообщающий о напоминании перед отправкой через next
+func NewNotifySender(notifier domain.ChangeNotifier, next domain.ReminderSender) *NotifySender {
+	return &NotifySender{notifier: notifier, next: next}
+}
+
+// SendReminder сообщает о напоминании и отправляет его через next.
+// О повторных попытках доставки не сообщается, чтобы клиент не показал напоминание дважды.
+func (s *NotifySender) SendReminder(task *domain.ReminderTask) error {
+	if task.Attempt == 0 {
+		reminder := *task
+		s.notifier.Notify(&domain.Change{
+			Type:     domain.ChangeReminder,
+			UserID:   task.UserID,
+			EventID:  task.EventID,
+			Reminder: &reminder,
+			At:       time.Now(),
+		})
+	}
+	return s.next.SendReminder(task)
+}
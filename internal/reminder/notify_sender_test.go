@@ -0,0 +1,35 @@
+package reminder
+
+import (
+	"testing"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+type recordingNotifier []*domain.Change
+
+func (r *recordingNotifier) Notify(change *domain.Change) {
+	*r = append(*r, change)
+}
+
+func TestNotifySender_NotifiesOncePerReminder(t *testing.T) {
+	var notifier recordingNotifier
+	var next countingSender
+	sender := NewNotifySender(&notifier, &next)
+
+	task := &domain.ReminderTask{EventID: "e1", UserID: "user1", Text: "Meeting"}
+	sender.SendReminder(task)
+	retry := *task
+	retry.Attempt = 1
+	sender.SendReminder(&retry)
+
+	if next != 2 {
+		t.Errorf("Expected every attempt to be delivered, got %d", next)
+	}
+	if len(notifier) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(notifier))
+	}
+	if change := notifier[0]; change.Type != domain.ChangeReminder || change.Reminder.Text != "Meeting" || change.UserID != "user1" {
+		t.Errorf("Unexpected change %+v", change)
+	}
+}
@@ -0,0 +1,79 @@
+package reminder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// ErrNoRecipient возвращается, если у пользователя нет адреса для канала
+var ErrNoRecipient = errors.New("user has no address for reminder channel")
+
+// SMTPConfig содержит параметры SMTP-сервера
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // пустое значение отключает аутентификацию
+	Password string
+	From     string
+}
+
+// SMTPReminderSender отправляет напоминания письмом на адрес пользователя
+type SMTPReminderSender struct {
+	cfg   SMTPConfig
+	users domain.UserRepository
+}
+
+// NewSMTPReminderSender создает новый отправитель напоминаний по email
+func NewSMTPReminderSender(cfg SMTPConfig, users domain.UserRepository) *SMTPReminderSender {
+	return &SMTPReminderSender{cfg: cfg, users: users}
+}
+
+// SendReminder отправляет напоминание
+func (s *SMTPReminderSender) SendReminder(task *domain.ReminderTask) error {
+	user, err := s.users.Get(task.UserID)
+	if err != nil {
+		return fmt.Errorf("get user %s: %w", task.UserID, err)
+	}
+	if user.Email == "" {
+		return fmt.Errorf("%w: %s", ErrNoRecipient, domain.ChannelEmail)
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	msg := s.message(user, task)
+	if err := smtp.SendMail(addr, auth, s.cfg.From, []string{user.Email}, msg); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	return nil
+}
+
+// message формирует письмо; время события показывается в поясе пользователя
+func (s *SMTPReminderSender) message(user *domain.User, task *domain.ReminderTask) []byte {
+	subject := mime.QEncoding.Encode("utf-8", "Напоминание: "+task.Text)
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&body, "To: %s\r\n", user.Email)
+	fmt.Fprintf(&body, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	fmt.Fprintf(&body, "%s\r\n", task.Text)
+	if !task.Start.IsZero() {
+		fmt.Fprintf(&body, "Начало: %s\r\n", task.Start.In(user.Location()).Format("02.01.2006 15:04 MST"))
+	}
+	return body.Bytes()
+}
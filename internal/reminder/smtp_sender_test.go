@@ -0,0 +1,141 @@
+package reminder
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+// smtpMessage - письмо, принятое тестовым SMTP-сервером
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// startSMTPServer запускает минимальный SMTP-сервер, принимающий одно письмо
+func startSMTPServer(t *testing.T) (host string, port int, messages <-chan smtpMessage) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP test")
+
+		var msg smtpMessage
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch upper := strings.ToUpper(cmd); {
+			case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(upper, "MAIL FROM:"):
+				msg.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+				reply("250 OK")
+			case strings.HasPrefix(upper, "RCPT TO:"):
+				msg.to = append(msg.to, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+				reply("250 OK")
+			case upper == "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				msg.data = data.String()
+				ch <- msg
+				reply("250 OK")
+			case upper == "QUIT":
+				reply("221 Bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+func TestSMTPReminderSender_SendReminder(t *testing.T) {
+	host, port, messages := startSMTPServer(t)
+
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "user1", Email: "user1@example.com", TimeZone: "Europe/Moscow"})
+
+	sender := NewSMTPReminderSender(SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "calendar@example.com",
+	}, users)
+
+	task := &domain.ReminderTask{
+		EventID: "e1",
+		UserID:  "user1",
+		Text:    "Встреча с командой",
+		Start:   time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC),
+	}
+	if err := sender.SendReminder(task); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case msg := <-messages:
+		if msg.from != "calendar@example.com" {
+			t.Errorf("Expected sender %s, got %s", "calendar@example.com", msg.from)
+		}
+		if len(msg.to) != 1 || msg.to[0] != "user1@example.com" {
+			t.Errorf("Expected recipient %s, got %v", "user1@example.com", msg.to)
+		}
+		if !strings.Contains(msg.data, "Встреча с командой") {
+			t.Errorf("Expected event text in body, got %q", msg.data)
+		}
+		// Время начала показывается в поясе пользователя
+		if !strings.Contains(msg.data, "15.01.2024 10:00") {
+			t.Errorf("Expected local start time in body, got %q", msg.data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Email was not delivered")
+	}
+}
+
+func TestSMTPReminderSender_NoEmail(t *testing.T) {
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "user1"})
+
+	sender := NewSMTPReminderSender(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "calendar@example.com"}, users)
+	err := sender.SendReminder(&domain.ReminderTask{EventID: "e1", UserID: "user1", Text: "Event"})
+	if !errors.Is(err, ErrNoRecipient) {
+		t.Errorf("Expected error %v, got %v", ErrNoRecipient, err)
+	}
+}
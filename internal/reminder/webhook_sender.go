@@ -0,0 +1,100 @@
+package reminder
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const (
+	// SignatureHeader содержит подпись тела запроса: sha256=<hex HMAC>
+	SignatureHeader = "X-Signature"
+	// TimestampHeader содержит время отправки (Unix), входящее в подпись
+	TimestampHeader = "X-Timestamp"
+)
+
+// WebhookReminderSender отправляет напоминания POST-запросом на URL пользователя.
+// Тело подписывается HMAC-SHA256, чтобы получатель мог проверить отправителя.
+type WebhookReminderSender struct {
+	client *http.Client
+	secret []byte
+	users  domain.UserRepository
+}
+
+// NewWebhookReminderSender создает новый отправитель напоминаний через webhook
+func NewWebhookReminderSender(secret string, timeout time.Duration, users domain.UserRepository) *WebhookReminderSender {
+	return &WebhookReminderSender{
+		client: &http.Client{Timeout: timeout},
+		secret: []byte(secret),
+		users:  users,
+	}
+}
+
+// webhookPayload - тело запроса с напоминанием
+type webhookPayload struct {
+	EventID  string    `json:"event_id"`
+	UserID   string    `json:"user_id"`
+	Text     string    `json:"text"`
+	RemindAt time.Time `json:"remind_at"`
+	Start    time.Time `json:"start,omitempty"`
+}
+
+// SendReminder отправляет напоминание
+func (s *WebhookReminderSender) SendReminder(task *domain.ReminderTask) error {
+	user, err := s.users.Get(task.UserID)
+	if err != nil {
+		return fmt.Errorf("get user %s: %w", task.UserID, err)
+	}
+	if user.WebhookURL == "" {
+		return fmt.Errorf("%w: %s", ErrNoRecipient, domain.ChannelWebhook)
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		EventID:  task.EventID,
+		UserID:   task.UserID,
+		Text:     task.Text,
+		RemindAt: task.Time,
+		Start:    task.Start,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, user.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(s.secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("send webhook: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign вычисляет подпись тела запроса. В подпись входит время отправки,
+// чтобы перехваченный запрос нельзя было повторить позже.
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,58 @@
+package reminder
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func TestWebhookReminderSender_SignsPayload(t *testing.T) {
+	received := make(chan webhookPayload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := Sign([]byte("secret"), r.Header.Get(TimestampHeader), body)
+		if r.Header.Get(SignatureHeader) != expected {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		var payload webhookPayload
+		json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "user1", WebhookURL: server.URL})
+
+	sender := NewWebhookReminderSender("secret", time.Second, users)
+	task := &domain.ReminderTask{EventID: "e1", UserID: "user1", Text: "Event", Time: time.Now()}
+	if err := sender.SendReminder(task); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	payload := <-received
+	if payload.EventID != "e1" || payload.Text != "Event" {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+}
+
+func TestWebhookReminderSender_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "user1", WebhookURL: server.URL})
+
+	sender := NewWebhookReminderSender("secret", time.Second, users)
+	if err := sender.SendReminder(&domain.ReminderTask{EventID: "e1", UserID: "user1"}); err == nil {
+		t.Error("Expected error for non-2xx response")
+	}
+}
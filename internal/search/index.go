@@ -0,0 +1,205 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Параметры ранжирования BM25
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// prefixWeight понижает вклад слова, совпавшего с запросом только по префиксу
+	prefixWeight = 0.3
+)
+
+// Match - найденный документ и его релевантность
+type Match struct {
+	ID    string
+	Score float64
+}
+
+// Index - инвертированный индекс по текстам документов, раздельный для каждого пользователя.
+// Безопасен для конкурентного использования.
+type Index struct {
+	mu    sync.Mutex
+	users map[string]*userIndex
+}
+
+// userIndex хранит документы одного пользователя
+type userIndex struct {
+	postings map[string]map[string]int // основа -> ID документа -> число вхождений
+	docs     map[string][]string       // ID документа -> основы текста
+	totalLen int
+	terms    []string // отсортированные основы для поиска по префиксу; nil после изменений
+}
+
+// NewIndex создает пустой индекс
+func NewIndex() *Index {
+	return &Index{users: make(map[string]*userIndex)}
+}
+
+// Add индексирует текст документа, заменяя ранее проиндексированный текст
+func (i *Index) Add(userID, docID, text string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	u := i.users[userID]
+	if u == nil {
+		u = &userIndex{
+			postings: make(map[string]map[string]int),
+			docs:     make(map[string][]string),
+		}
+		i.users[userID] = u
+	}
+	u.remove(docID)
+
+	tokens := Tokenize(text)
+	u.docs[docID] = tokens
+	u.totalLen += len(tokens)
+	for _, token := range tokens {
+		postings := u.postings[token]
+		if postings == nil {
+			postings = make(map[string]int)
+			u.postings[token] = postings
+			u.terms = nil
+		}
+		postings[docID]++
+	}
+}
+
+// Remove удаляет документ из индекса
+func (i *Index) Remove(userID, docID string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	u := i.users[userID]
+	if u == nil {
+		return
+	}
+	u.remove(docID)
+	if len(u.docs) == 0 {
+		delete(i.users, userID)
+	}
+}
+
+// Search находит документы пользователя, содержащие все слова запроса
+// (точно или по префиксу), и возвращает их по убыванию релевантности
+func (i *Index) Search(userID, query string) []Match {
+	tokens := Tokenize(query)
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	u := i.users[userID]
+	if u == nil {
+		return nil
+	}
+
+	var scores map[string]float64
+	for _, token := range tokens {
+		// Для каждого слова запроса берется лучшее совпадение в документе.
+		// Все слова с этим префиксом считаются одним термином, поэтому редкое
+		// продолжение префикса не получает завышенный IDF.
+		terms := u.matchingTerms(token)
+		idf := u.idf(terms)
+		best := make(map[string]float64)
+		for _, term := range terms {
+			weight := 1.0
+			if term != token {
+				weight = prefixWeight
+			}
+			for docID, tf := range u.postings[term] {
+				score := weight * idf * u.termScore(tf, len(u.docs[docID]))
+				if score > best[docID] {
+					best[docID] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = best
+			continue
+		}
+		for docID := range scores {
+			if score, ok := best[docID]; ok {
+				scores[docID] += score
+			} else {
+				delete(scores, docID)
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for docID, score := range scores {
+		matches = append(matches, Match{ID: docID, Score: score})
+	}
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return matches[a].ID < matches[b].ID
+	})
+	return matches
+}
+
+func (u *userIndex) remove(docID string) {
+	tokens, ok := u.docs[docID]
+	if !ok {
+		return
+	}
+	for _, token := range tokens {
+		postings := u.postings[token]
+		delete(postings, docID)
+		if len(postings) == 0 {
+			delete(u.postings, token)
+			u.terms = nil
+		}
+	}
+	u.totalLen -= len(tokens)
+	delete(u.docs, docID)
+}
+
+// matchingTerms возвращает основы, начинающиеся с token
+func (u *userIndex) matchingTerms(token string) []string {
+	if u.terms == nil {
+		u.terms = make([]string, 0, len(u.postings))
+		for term := range u.postings {
+			u.terms = append(u.terms, term)
+		}
+		sort.Strings(u.terms)
+	}
+
+	var result []string
+	for i := sort.SearchStrings(u.terms, token); i < len(u.terms) && strings.HasPrefix(u.terms[i], token); i++ {
+		result = append(result, u.terms[i])
+	}
+	return result
+}
+
+// idf возвращает обратную документную частоту группы основ: число документов,
+// содержащих хотя бы одну из них (вариант BM25, всегда положительный)
+func (u *userIndex) idf(terms []string) float64 {
+	docs := make(map[string]struct{})
+	for _, term := range terms {
+		for docID := range u.postings[term] {
+			docs[docID] = struct{}{}
+		}
+	}
+	n := float64(len(u.docs))
+	df := float64(len(docs))
+	return math.Log(1 + (n-df+0.5)/(df+0.5))
+}
+
+// termScore возвращает вклад частоты слова с поправкой на длину документа
+func (u *userIndex) termScore(tf, docLen int) float64 {
+	avgLen := float64(u.totalLen) / float64(len(u.docs))
+	f := float64(tf)
+	return f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*float64(docLen)/avgLen))
+}
@@ -0,0 +1,115 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Встреча с командой", []string{"встреч", "с", "команд"}},
+		{"встречи, встречу!", []string{"встреч", "встреч"}},
+		{"Ёлка", []string{"елк"}},
+		{"Team meetings planned", []string{"team", "meet", "plann"}},
+		{"Classes", []string{"class"}},
+		{"Dentist 10:30", []string{"dentist", "10", "30"}},
+	}
+
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Tokenize(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func ids(matches []Match) []string {
+	result := make([]string, len(matches))
+	for i, m := range matches {
+		result[i] = m.ID
+	}
+	return result
+}
+
+func TestIndex_Search(t *testing.T) {
+	index := NewIndex()
+	index.Add("user1", "e1", "Dentist appointment")
+	index.Add("user1", "e2", "Call the dentist about the appointment, dentist is late")
+	index.Add("user1", "e3", "Встреча у стоматолога")
+	index.Add("user1", "e4", "Team meeting")
+	index.Add("user2", "e5", "Dentist")
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{"exact word", "dentist", []string{"e1", "e2"}},
+		{"prefix", "dent", []string{"e1", "e2"}},
+		{"all words required", "dentist call", []string{"e2"}},
+		{"russian word form", "стоматологу", []string{"e3"}},
+		{"russian prefix", "встр", []string{"e3"}},
+		{"english word form", "meetings", []string{"e4"}},
+		{"no match", "gym", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ids(index.Search("user1", tt.query))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			seen := make(map[string]bool)
+			for _, id := range got {
+				seen[id] = true
+			}
+			for _, id := range tt.want {
+				if !seen[id] {
+					t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIndex_Ranking(t *testing.T) {
+	index := NewIndex()
+	index.Add("user1", "prefix", "Dentistry conference")
+	index.Add("user1", "long", "Pick up the kids, buy groceries and then go to the dentist")
+	index.Add("user1", "short", "Dentist")
+
+	got := ids(index.Search("user1", "dentist"))
+	want := []string{"short", "long", "prefix"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected ranking %v, got %v", want, got)
+	}
+}
+
+func TestIndex_UpdateAndRemove(t *testing.T) {
+	index := NewIndex()
+	index.Add("user1", "e1", "Dentist")
+	index.Add("user1", "e1", "Gym")
+
+	if got := index.Search("user1", "dentist"); len(got) != 0 {
+		t.Errorf("Expected replaced text not to match, got %v", ids(got))
+	}
+	if got := index.Search("user1", "gym"); len(got) != 1 {
+		t.Errorf("Expected new text to match, got %v", ids(got))
+	}
+
+	index.Remove("user1", "e1")
+	if got := index.Search("user1", "gym"); len(got) != 0 {
+		t.Errorf("Expected removed document not to match, got %v", ids(got))
+	}
+}
+
+func TestIndex_UserIsolation(t *testing.T) {
+	index := NewIndex()
+	index.Add("user1", "e1", "Dentist")
+	index.Add("user2", "e2", "Dentist")
+
+	if got := ids(index.Search("user2", "dentist")); !reflect.DeepEqual(got, []string{"e2"}) {
+		t.Errorf("Expected only user2 documents, got %v", got)
+	}
+}
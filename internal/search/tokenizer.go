@@ -0,0 +1,77 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// minStemLength - минимальная длина основы в символах после отсечения окончания
+const minStemLength = 3
+
+// russianEndings содержит частые окончания существительных, прилагательных и глаголов,
+// от длинных к коротким, чтобы отсекалось самое длинное подходящее
+var russianEndings = []string{
+	"иями", "ями", "ами", "ого", "его", "ому", "ему", "ыми", "ими", "ешь", "ете", "ите", "ать", "ять", "ить", "еть",
+	"ой", "ей", "ий", "ый", "ая", "яя", "ое", "ее", "ую", "юю", "ом", "ем", "ах", "ях", "ам", "ям",
+	"ов", "ев", "ию", "ия", "ие", "ии", "ью", "ет", "ит", "ут", "ют", "ат", "ят",
+	"а", "я", "о", "е", "у", "ю", "ы", "и", "ь", "й",
+}
+
+// Tokenize разбивает текст на слова и приводит их к основам: нижний регистр,
+// ё заменяется на е, отсекаются типичные окончания русских и английских слов
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		tokens = append(tokens, stem(strings.ReplaceAll(word, "ё", "е")))
+	}
+	return tokens
+}
+
+// stem отсекает окончание слова. Это не полноценный стеммер: достаточно,
+// чтобы разные формы слова ("встреча", "встречи", "meetings") сводились
+// к общему префиксу, а остальное покрывает поиск по префиксу.
+func stem(word string) string {
+	if isCyrillic(word) {
+		for _, ending := range russianEndings {
+			if trimmed, ok := strings.CutSuffix(word, ending); ok && utf8.RuneCountInString(trimmed) >= minStemLength {
+				return trimmed
+			}
+		}
+		return word
+	}
+	return stemEnglish(word)
+}
+
+// stemEnglish отсекает окончание множественного числа, затем -ing или -ed,
+// чтобы "meetings" и "meeting" давали одну основу
+func stemEnglish(word string) string {
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ies") && len(word) > 4:
+		word = word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss") && len(word)-1 >= minStemLength:
+		word = word[:len(word)-1]
+	}
+
+	switch {
+	case strings.HasSuffix(word, "ing") && len(word)-3 >= minStemLength:
+		return word[:len(word)-3]
+	case strings.HasSuffix(word, "ed") && len(word)-2 >= minStemLength:
+		return word[:len(word)-2]
+	}
+	return word
+}
+
+func isCyrillic(word string) bool {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -3,16 +3,22 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/oziev02/event-calendar-service/configs"
+	"github.com/oziev02/event-calendar-service/internal/auth"
+	"github.com/oziev02/event-calendar-service/internal/caldav"
 	"github.com/oziev02/event-calendar-service/internal/domain"
 	httphandler "github.com/oziev02/event-calendar-service/internal/http"
 	"github.com/oziev02/event-calendar-service/internal/http/handlers"
 	"github.com/oziev02/event-calendar-service/internal/reminder"
 	"github.com/oziev02/event-calendar-service/internal/service"
 	"github.com/oziev02/event-calendar-service/internal/storage"
+	"github.com/oziev02/event-calendar-service/internal/stream"
+	"github.com/oziev02/event-calendar-service/internal/webhook"
 	"github.com/oziev02/event-calendar-service/internal/worker"
 	"github.com/oziev02/event-calendar-service/pkg/logger"
 )
@@ -23,7 +29,8 @@ type Server struct {
 	logger         logger.Logger
 	reminderWorker *worker.ReminderWorker
 	cleanupWorker  *worker.CleanupWorker
-	reminderChan   chan *domain.ReminderTask
+	webhookWorker  *worker.WebhookWorker
+	repoCloser     io.Closer
 }
 
 // NewServer создает новый HTTP сервер
@@ -32,39 +39,141 @@ func NewServer(cfg *configs.Config) (*Server, error) {
 	asyncLogger := logger.NewAsyncLogger(cfg.LoggerBufferSize)
 
 	// Инициализировать репозиторий
-	repo := storage.NewMemoryRepository()
+	repo, repoCloser, err := newEventRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := newUserRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	deadLetters, err := newDeadLetterRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := newWebhookRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := newShareRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	calendars, err := newCalendarRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	invitations, err := newInvitationRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := newAPIKeyRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtVerifier, err := newJWTVerifier(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// Инициализировать канал напоминаний
-	reminderChan := make(chan *domain.ReminderTask, 100)
+	// Изменения календарей рассылаются подключенным клиентам
+	hub := stream.NewHub(stream.DefaultBuffer)
+
+	// Изменения событий доставляются по подпискам на webhook
+	webhookWorker := worker.NewWebhookWorker(
+		webhooks,
+		webhook.NewHTTPSender(cfg.WebhookTimeout),
+		asyncLogger,
+		worker.RetryPolicy{
+			MaxAttempts: cfg.WebhookMaxAttempts,
+			BaseDelay:   cfg.WebhookRetryBaseDelay,
+			MaxDelay:    cfg.WebhookRetryMaxDelay,
+		},
+	)
+	webhookWorker.Start()
 
 	// Инициализировать отправитель напоминаний
-	reminderSender := reminder.NewConsoleReminderSender(asyncLogger)
+	// Напоминания удаленных и архивированных событий не отправляются,
+	// об отправленных напоминаниях сообщается в поток изменений
+	reminderSender := reminder.NewActiveEventSender(repo,
+		reminder.NewNotifySender(hub, newReminderSender(cfg, users, asyncLogger)))
 
 	// Инициализировать воркеры
 	reminderWorker := worker.NewReminderWorker(
-		reminderChan,
 		reminderSender,
 		asyncLogger,
 		cfg.ReminderCheckInterval,
 	)
-	reminderWorker.Start()
+	reminderWorker.SetRetryPolicy(worker.RetryPolicy{
+		MaxAttempts: cfg.ReminderMaxAttempts,
+		BaseDelay:   cfg.ReminderRetryBaseDelay,
+		MaxDelay:    cfg.ReminderRetryMaxDelay,
+	}, deadLetters)
 
-	cleanupWorker := worker.NewCleanupWorker(
+	// Инициализировать сервис приложения
+	eventService := service.NewEventService(
 		repo,
+		service.WithUserRepository(users),
+		service.WithShareRepository(shares),
+		service.WithCalendarRepository(calendars),
+		service.WithInvitationRepository(invitations),
+		service.WithReminderScheduler(reminderWorker),
+		service.WithChangeNotifier(hub),
+		service.WithChangeNotifier(webhookWorker),
+	)
+
+	// Архивирование идет через сервис, чтобы клиенты узнали об архивированных событиях
+	cleanupWorker := worker.NewCleanupWorker(
+		eventService,
 		asyncLogger,
 		cfg.CleanupInterval,
 		cfg.ArchiveAfter,
 	)
 	cleanupWorker.Start()
+	userService := service.NewUserService(users)
+	webhookService := service.NewWebhookService(webhooks)
+	apiKeyService := service.NewAPIKeyService(apiKeys)
 
-	// Инициализировать сервис приложения
-	eventService := service.NewEventService(repo)
+	// Напоминания серий после отправки переходят на следующее вхождение
+	reminderWorker.SetFollowUp(eventService.NextReminder)
+
+	// Восстановить очередь напоминаний из хранилища
+	pending, err := eventService.PendingReminders(time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("load pending reminders: %w", err)
+	}
+	for _, task := range pending {
+		reminderWorker.Schedule(task)
+	}
+	reminderWorker.Start()
 
 	// Инициализировать обработчики
-	eventHandler := handlers.NewEventHandler(eventService, asyncLogger, reminderChan)
+	eventHandler := handlers.NewEventHandler(eventService, asyncLogger)
+	userHandler := handlers.NewUserHandler(userService, asyncLogger)
+	apiHandler := handlers.NewAPIHandler(eventService, userService, webhookService, apiKeyService, asyncLogger)
+	feedHandler := handlers.NewFeedHandler(eventService, userService, asyncLogger)
+	caldavHandler := caldav.NewHandler(eventService, asyncLogger)
+	streamHandler := handlers.NewStreamHandler(hub, asyncLogger)
+
+	var adminHandler *handlers.AdminHandler
+	if cfg.AdminToken != "" {
+		deadLetterService := service.NewDeadLetterService(deadLetters, reminderWorker)
+		adminHandler = handlers.NewAdminHandler(deadLetterService, apiKeyService, cfg.AdminToken, asyncLogger)
+	}
+
+	// Пользователь определяется по JWT или ключу API, а не по параметрам запроса
+	authMiddleware := httphandler.NewAuthMiddleware(jwtVerifier, apiKeyService, asyncLogger)
 
 	// Настроить маршруты
-	handler := httphandler.Router(eventHandler, asyncLogger)
+	handler := httphandler.Router(eventHandler, userHandler, apiHandler, feedHandler, caldavHandler, streamHandler, adminHandler, authMiddleware, asyncLogger)
 
 	// Создать HTTP сервер
 	httpServer := &http.Server{
@@ -80,10 +189,173 @@ func NewServer(cfg *configs.Config) (*Server, error) {
 		logger:         asyncLogger,
 		reminderWorker: reminderWorker,
 		cleanupWorker:  cleanupWorker,
-		reminderChan:   reminderChan,
+		webhookWorker:  webhookWorker,
+		repoCloser:     repoCloser,
 	}, nil
 }
 
+// newEventRepository создает репозиторий событий в соответствии с конфигурацией.
+// Для файлового хранилища возвращается io.Closer для закрытия журнала.
+func newEventRepository(cfg *configs.Config) (domain.EventRepository, io.Closer, error) {
+	switch cfg.StorageType {
+	case "file":
+		repo, err := storage.NewFileRepository(cfg.DataDir, cfg.SnapshotEvery)
+		if err != nil {
+			return nil, nil, fmt.Errorf("open file storage: %w", err)
+		}
+		return repo, repo, nil
+	default:
+		return storage.NewMemoryRepository(), nil, nil
+	}
+}
+
+// newUserRepository создает хранилище настроек пользователей рядом с событиями
+func newUserRepository(cfg *configs.Config) (domain.UserRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		users, err := storage.NewFileUserRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open user storage: %w", err)
+		}
+		return users, nil
+	default:
+		return storage.NewMemoryUserRepository(), nil
+	}
+}
+
+// newDeadLetterRepository создает хранилище недоставленных напоминаний рядом с событиями
+func newDeadLetterRepository(cfg *configs.Config) (domain.DeadLetterRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		deadLetters, err := storage.NewFileDeadLetterRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open dead letter storage: %w", err)
+		}
+		return deadLetters, nil
+	default:
+		return storage.NewMemoryDeadLetterRepository(), nil
+	}
+}
+
+// newWebhookRepository создает хранилище подписок на webhook рядом с событиями
+func newWebhookRepository(cfg *configs.Config) (domain.WebhookRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		webhooks, err := storage.NewFileWebhookRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open webhook storage: %w", err)
+		}
+		return webhooks, nil
+	default:
+		return storage.NewMemoryWebhookRepository(), nil
+	}
+}
+
+// newCalendarRepository создает хранилище именованных календарей рядом с событиями
+func newCalendarRepository(cfg *configs.Config) (domain.CalendarRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		calendars, err := storage.NewFileCalendarRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open calendar storage: %w", err)
+		}
+		return calendars, nil
+	default:
+		return storage.NewMemoryCalendarRepository(), nil
+	}
+}
+
+// newInvitationRepository создает хранилище приглашений рядом с событиями
+func newInvitationRepository(cfg *configs.Config) (domain.InvitationRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		invitations, err := storage.NewFileInvitationRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open invitation storage: %w", err)
+		}
+		return invitations, nil
+	default:
+		return storage.NewMemoryInvitationRepository(), nil
+	}
+}
+
+// newShareRepository создает хранилище списков доступа к календарям рядом с событиями
+func newShareRepository(cfg *configs.Config) (domain.ShareRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		shares, err := storage.NewFileShareRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open share storage: %w", err)
+		}
+		return shares, nil
+	default:
+		return storage.NewMemoryShareRepository(), nil
+	}
+}
+
+// newAPIKeyRepository создает хранилище ключей API рядом с событиями
+func newAPIKeyRepository(cfg *configs.Config) (domain.APIKeyRepository, error) {
+	switch cfg.StorageType {
+	case "file":
+		keys, err := storage.NewFileAPIKeyRepository(cfg.DataDir)
+		if err != nil {
+			return nil, fmt.Errorf("open api key storage: %w", err)
+		}
+		return keys, nil
+	default:
+		return storage.NewMemoryAPIKeyRepository(), nil
+	}
+}
+
+// newJWTVerifier создает проверку JWT, если в конфигурации задан хотя бы один ключ.
+// Без ключей возвращается nil и принимаются только ключи API.
+func newJWTVerifier(cfg *configs.Config) (*auth.JWTVerifier, error) {
+	jwtCfg := auth.JWTConfig{
+		HMACSecret: []byte(cfg.AuthJWTSecret),
+		Issuer:     cfg.AuthJWTIssuer,
+		Audience:   cfg.AuthJWTAudience,
+		Leeway:     cfg.AuthJWTLeeway,
+	}
+	if cfg.AuthJWTPublicKeyFile != "" {
+		data, err := os.ReadFile(cfg.AuthJWTPublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwt public key: %w", err)
+		}
+		key, err := auth.ParseRSAPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt public key: %w", err)
+		}
+		jwtCfg.RSAPublicKey = key
+	}
+	if len(jwtCfg.HMACSecret) == 0 && jwtCfg.RSAPublicKey == nil {
+		return nil, nil
+	}
+	return auth.NewJWTVerifier(jwtCfg), nil
+}
+
+// newReminderSender создает отправитель, доставляющий напоминания
+// во включенные в конфигурации каналы
+func newReminderSender(cfg *configs.Config, users domain.UserRepository, log logger.Logger) domain.ReminderSender {
+	sender := reminder.NewCompositeReminderSender(users)
+	for _, channel := range cfg.ReminderChannels {
+		switch domain.ReminderChannel(channel) {
+		case domain.ChannelConsole:
+			sender.Register(domain.ChannelConsole, reminder.NewConsoleReminderSender(log))
+		case domain.ChannelEmail:
+			sender.Register(domain.ChannelEmail, reminder.NewSMTPReminderSender(reminder.SMTPConfig{
+				Host:     cfg.SMTPHost,
+				Port:     cfg.SMTPPort,
+				Username: cfg.SMTPUsername,
+				Password: cfg.SMTPPassword,
+				From:     cfg.SMTPFrom,
+			}, users))
+		case domain.ChannelWebhook:
+			sender.Register(domain.ChannelWebhook, reminder.NewWebhookReminderSender(cfg.WebhookSecret, cfg.WebhookTimeout, users))
+		}
+	}
+	return sender
+}
+
 // Start запускает HTTP сервер
 func (s *Server) Start() error {
 	addr := s.httpServer.Addr
@@ -97,11 +369,18 @@ func (s *Server) Shutdown(ctx context.Context) error {
 
 	s.reminderWorker.Stop()
 	s.cleanupWorker.Stop()
+	s.webhookWorker.Stop()
 
 	if err := s.httpServer.Shutdown(ctx); err != nil {
 		return err
 	}
 
+	if s.repoCloser != nil {
+		if err := s.repoCloser.Close(); err != nil {
+			return err
+		}
+	}
+
 	return s.logger.Close()
 }
 
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// APIKeyPrefix начинает каждый ключ API, чтобы его можно было отличить
+// от JWT и найти в логах или репозитории кода
+const APIKeyPrefix = "eck_"
+
+// apiKeySecretBytes - длина секретной части ключа API в байтах
+const apiKeySecretBytes = 32
+
+// APIKeyService выпускает и проверяет ключи API пользователей.
+// Ключ имеет вид eck_<id>_<secret>; хранится только SHA-256 секрета.
+type APIKeyService struct {
+	repo domain.APIKeyRepository
+}
+
+// NewAPIKeyService создает новый сервис ключей API
+func NewAPIKeyService(repo domain.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{repo: repo}
+}
+
+// Create выпускает ключ пользователю и возвращает его вместе с самим ключом,
+// который больше нигде не сохраняется
+func (s *APIKeyService) Create(userID, name string) (*domain.APIKey, string, error) {
+	if userID == "" {
+		return nil, "", domain.ErrInvalidUserID
+	}
+	buf := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, "", fmt.Errorf("generate api key: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+
+	key := &domain.APIKey{
+		ID:        generateID(),
+		UserID:    userID,
+		Name:      name,
+		Hash:      hashAPIKeySecret(secret),
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, "", err
+	}
+	return key, APIKeyPrefix + key.ID + "_" + secret, nil
+}
+
+// List возвращает ключи пользователя
+func (s *APIKeyService) List(userID string) ([]*domain.APIKey, error) {
+	return s.repo.List(userID)
+}
+
+// Revoke отзывает ключ пользователя
+func (s *APIKeyService) Revoke(userID, id string) error {
+	return s.repo.Delete(userID, id)
+}
+
+// Authenticate проверяет ключ и возвращает его описание.
+// Любая ошибка проверки возвращается как ErrInvalidAPIKey.
+func (s *APIKeyService) Authenticate(plain string) (*domain.APIKey, error) {
+	rest, ok := strings.CutPrefix(plain, APIKeyPrefix)
+	if !ok {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	// ID ключа не содержит "_", а секрет может его содержать
+	id, secret, ok := strings.Cut(rest, "_")
+	if !ok || id == "" || secret == "" {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	key, err := s.repo.Get(id)
+	if errors.Is(err, domain.ErrAPIKeyNotFound) {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKeySecret(secret))) != 1 {
+		return nil, domain.ErrInvalidAPIKey
+	}
+	return key, nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
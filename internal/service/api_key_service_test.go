@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	repo := storage.NewMemoryAPIKeyRepository()
+	service := NewAPIKeyService(repo)
+
+	key, plain, err := service.Create("user1", "laptop")
+	if err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+	if !strings.HasPrefix(plain, APIKeyPrefix) {
+		t.Fatalf("Unexpected key %q", plain)
+	}
+	stored, _ := repo.Get(key.ID)
+	if strings.Contains(plain, stored.Hash) {
+		t.Errorf("Expected only the hash of the key to be stored")
+	}
+
+	authenticated, err := service.Authenticate(plain)
+	if err != nil || authenticated.UserID != "user1" || authenticated.ID != key.ID {
+		t.Fatalf("Expected key of user1, got %+v %v", authenticated, err)
+	}
+
+	for _, invalid := range []string{"", "eck_", plain + "x", APIKeyPrefix + "unknown_secret", strings.TrimPrefix(plain, APIKeyPrefix)} {
+		if _, err := service.Authenticate(invalid); !errors.Is(err, domain.ErrInvalidAPIKey) {
+			t.Errorf("Expected ErrInvalidAPIKey for %q, got %v", invalid, err)
+		}
+	}
+
+	if err := service.Revoke("user2", key.ID); !errors.Is(err, domain.ErrAPIKeyNotFound) {
+		t.Errorf("Expected another user not to revoke the key, got %v", err)
+	}
+	if err := service.Revoke("user1", key.ID); err != nil {
+		t.Fatalf("Failed to revoke api key: %v", err)
+	}
+	if _, err := service.Authenticate(plain); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// CreateCalendar создает именованный календарь пользователя.
+// ID, владелец и время создания задаются сервисом.
+func (s *EventService) CreateCalendar(userID string, calendar *domain.Calendar) (*domain.Calendar, error) {
+	if s.calendars == nil {
+		return nil, errCalendarsDisabled
+	}
+	now := time.Now()
+	created := calendar.Clone()
+	created.ID = generateID()
+	created.UserID = userID
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	if err := created.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.calendars.Create(created); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// UpdateCalendar заменяет название, цвет, пояс и напоминание по умолчанию
+// календаря пользователя. Уже созданные события календаря не меняются.
+func (s *EventService) UpdateCalendar(userID string, calendar *domain.Calendar) (*domain.Calendar, error) {
+	existing, err := s.ownCalendar(userID, calendar.ID)
+	if err != nil {
+		return nil, err
+	}
+	updated := calendar.Clone()
+	updated.UserID = userID
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = time.Now()
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.calendars.Update(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteCalendar удаляет календарь пользователя вместе с его событиями
+func (s *EventService) DeleteCalendar(userID, id string) error {
+	if _, err := s.ownCalendar(userID, id); err != nil {
+		return err
+	}
+
+	active, err := s.repo.GetAllActive(userID)
+	if err != nil {
+		return err
+	}
+	archived, err := s.repo.GetArchived(userID)
+	if err != nil {
+		return err
+	}
+	for _, event := range append(active, archived...) {
+		if event.CalendarID != id {
+			continue
+		}
+		// Серия удаляется вместе с заменами вхождений, поэтому
+		// замена может оказаться уже удаленной
+		if event.IsRecurring() {
+			err = s.deleteSeries(event, newEventOptions(nil))
+		} else {
+			err = s.delete(event)
+		}
+		if err != nil && !errors.Is(err, domain.ErrEventNotFound) {
+			return err
+		}
+	}
+	return s.calendars.Delete(userID, id)
+}
+
+// GetCalendar возвращает календарь пользователя или календарь из открытых ему
+func (s *EventService) GetCalendar(userID, id string) (*domain.Calendar, error) {
+	owners, err := s.readableOwners(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, ownerID := range owners {
+		calendar, err := s.calendar(ownerID, id)
+		if errors.Is(err, domain.ErrCalendarNotFound) {
+			continue
+		}
+		return calendar, err
+	}
+	return nil, domain.ErrCalendarNotFound
+}
+
+// ListCalendars возвращает календари пользователя и календари открытых ему
+// пользователей; владелец каждого календаря указан в его UserID.
+// Календарь по умолчанию не хранится и в список не входит.
+func (s *EventService) ListCalendars(userID string) ([]*domain.Calendar, error) {
+	if s.calendars == nil {
+		return nil, nil
+	}
+	owners, err := s.readableOwners(userID)
+	if err != nil {
+		return nil, err
+	}
+	var result []*domain.Calendar
+	for _, ownerID := range owners {
+		calendars, err := s.calendars.List(ownerID)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, calendars...)
+	}
+	return result, nil
+}
+
+// errCalendarsDisabled возвращается при создании календаря без хранилища календарей
+var errCalendarsDisabled = errors.New("named calendars are not configured")
+
+// calendar возвращает календарь владельца
+func (s *EventService) calendar(ownerID, id string) (*domain.Calendar, error) {
+	if s.calendars == nil {
+		return nil, domain.ErrCalendarNotFound
+	}
+	return s.calendars.Get(ownerID, id)
+}
+
+// ownCalendar возвращает календарь, которым может управлять только его владелец
+func (s *EventService) ownCalendar(userID, id string) (*domain.Calendar, error) {
+	if id == domain.DefaultCalendarID {
+		return nil, domain.ErrCalendarNotFound
+	}
+	return s.calendar(userID, id)
+}
+
+// applyCalendarDefaults задает новому событию пояс и напоминание его календаря,
+// если они не указаны явно
+func (s *EventService) applyCalendarDefaults(event *domain.Event) error {
+	if event.CalendarID == "" {
+		return nil
+	}
+	calendar, err := s.calendar(event.UserID, event.CalendarID)
+	if err != nil {
+		return err
+	}
+	if event.TimeZone == "" {
+		event.TimeZone = calendar.TimeZone
+	}
+	if !event.HasReminders() && calendar.DefaultReminder != nil {
+		event.Reminders = []domain.Reminder{*calendar.DefaultReminder}
+	}
+	return nil
+}
+
+// calendarFilter отбирает события по календарям; пустой фильтр пропускает все события
+type calendarFilter map[string]bool
+
+func newCalendarFilter(ids []string) calendarFilter {
+	if len(ids) == 0 {
+		return nil
+	}
+	f := make(calendarFilter, len(ids))
+	for _, id := range ids {
+		if id == domain.DefaultCalendarID {
+			id = ""
+		}
+		f[id] = true
+	}
+	return f
+}
+
+func (f calendarFilter) matches(event *domain.Event) bool {
+	return len(f) == 0 || f[event.CalendarID]
+}
+
+// filter оставляет события выбранных календарей
+func (f calendarFilter) filter(events []*domain.Event) []*domain.Event {
+	if len(f) == 0 {
+		return events
+	}
+	result := events[:0]
+	for _, event := range events {
+		if f.matches(event) {
+			result = append(result, event)
+		}
+	}
+	return result
+}
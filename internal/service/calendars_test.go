@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func newCalendarService() *EventService {
+	return NewEventService(storage.NewMemoryRepository(), WithCalendarRepository(storage.NewMemoryCalendarRepository()))
+}
+
+func TestEventService_CalendarDefaults(t *testing.T) {
+	service := newCalendarService()
+	work, err := service.CreateCalendar("user1", &domain.Calendar{
+		Name:            "Work",
+		TimeZone:        "Europe/Moscow",
+		DefaultReminder: &domain.Reminder{Offset: 10 * time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("CreateCalendar failed: %v", err)
+	}
+
+	start := time.Date(2024, 1, 15, 7, 0, 0, 0, time.UTC)
+	event, err := service.CreateEvent("user1", "Standup", start, nil, WithCalendar(work.ID))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if event.CalendarID != work.ID || event.TimeZone != "Europe/Moscow" {
+		t.Errorf("Expected calendar and time zone to be set, got %+v", event)
+	}
+	if len(event.Reminders) != 1 || event.Reminders[0].Offset != 10*time.Minute {
+		t.Errorf("Expected default reminder, got %+v", event.Reminders)
+	}
+
+	// Явно заданные настройки события важнее настроек календаря
+	event, err = service.CreateEvent("user1", "Call", start, nil, WithCalendar(work.ID),
+		WithTimeZone("UTC"), WithReminders([]domain.Reminder{{Offset: time.Hour}}))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if event.TimeZone != "UTC" || event.Reminders[0].Offset != time.Hour {
+		t.Errorf("Expected explicit settings to win, got %+v", event)
+	}
+
+	if _, err := service.CreateEvent("user1", "Lost", start, nil, WithCalendar("missing")); !errors.Is(err, domain.ErrCalendarNotFound) {
+		t.Errorf("Expected ErrCalendarNotFound, got %v", err)
+	}
+	if _, err := service.CreateEvent("user2", "Intrusion", start, nil, WithCalendar(work.ID)); !errors.Is(err, domain.ErrCalendarNotFound) {
+		t.Errorf("Expected ErrCalendarNotFound for calendar of another user, got %v", err)
+	}
+}
+
+func TestEventService_CalendarFilterAndMove(t *testing.T) {
+	service := newCalendarService()
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	work, _ := service.CreateCalendar("user1", &domain.Calendar{Name: "Work"})
+	home, _ := service.CreateCalendar("user1", &domain.Calendar{Name: "Home"})
+
+	standup, _ := service.CreateEvent("user1", "Standup", day.Add(9*time.Hour), nil, WithCalendar(work.ID))
+	dinner, _ := service.CreateEvent("user1", "Dinner", day.Add(19*time.Hour), nil, WithCalendar(home.ID))
+	other, _ := service.CreateEvent("user1", "Other", day.Add(12*time.Hour), nil)
+
+	events, err := service.GetEventsForDay("user1", day, work.ID)
+	if err != nil {
+		t.Fatalf("GetEventsForDay failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != standup.ID {
+		t.Errorf("Expected only work events, got %+v", events)
+	}
+	events, _ = service.GetEventsForDay("user1", day, domain.DefaultCalendarID, home.ID)
+	if len(events) != 2 || events[0].ID != other.ID || events[1].ID != dinner.ID {
+		t.Errorf("Expected default and home events, got %+v", events)
+	}
+
+	moved, err := service.UpdateEvent("user1", dinner.ID, dinner.Text, dinner.Date, nil, WithCalendar(work.ID))
+	if err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	if moved.CalendarID != work.ID {
+		t.Errorf("Expected event to move to work calendar, got %q", moved.CalendarID)
+	}
+	if _, err := service.UpdateEvent("user1", dinner.ID, dinner.Text, dinner.Date, nil, WithCalendar("missing")); !errors.Is(err, domain.ErrCalendarNotFound) {
+		t.Errorf("Expected ErrCalendarNotFound, got %v", err)
+	}
+
+	// Измененное вхождение серии остается в календаре серии
+	weekly, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY")
+	series, _ := service.CreateEvent("user1", "Review", day.Add(15*time.Hour), nil, WithCalendar(work.ID), WithRecurrence(weekly))
+	override, err := service.UpdateEvent("user1", series.ID, "Moved review", day.Add(16*time.Hour), nil,
+		WithScope(domain.ScopeThis, series.Date))
+	if err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	if override.CalendarID != work.ID {
+		t.Errorf("Expected override to stay in work calendar, got %q", override.CalendarID)
+	}
+
+	if err := service.DeleteCalendar("user1", work.ID); err != nil {
+		t.Fatalf("DeleteCalendar failed: %v", err)
+	}
+	events, _ = service.GetEventsForDay("user1", day)
+	if len(events) != 1 || events[0].ID != other.ID {
+		t.Errorf("Expected events of the deleted calendar to be removed, got %+v", events)
+	}
+	if err := service.DeleteCalendar("user1", domain.DefaultCalendarID); !errors.Is(err, domain.ErrCalendarNotFound) {
+		t.Errorf("Expected default calendar to be undeletable, got %v", err)
+	}
+}
+
+func TestEventService_CalendarValidation(t *testing.T) {
+	service := newCalendarService()
+	tests := []struct {
+		name     string
+		calendar domain.Calendar
+		want     error
+	}{
+		{"empty name", domain.Calendar{}, domain.ErrInvalidCalendarName},
+		{"bad color", domain.Calendar{Name: "Work", Color: "blue"}, domain.ErrInvalidColor},
+		{"bad time zone", domain.Calendar{Name: "Work", TimeZone: "Mars/Olympus"}, domain.ErrInvalidTimeZone},
+		{"absolute reminder", domain.Calendar{Name: "Work", DefaultReminder: &domain.Reminder{At: &time.Time{}}}, domain.ErrInvalidReminder},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.CreateCalendar("user1", &tt.calendar); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// conflictHorizon ограничивает проверку серий вхождениями первого года
+const conflictHorizon = 366 * 24 * time.Hour
+
+// Conflicts возвращает события календаря владельца event и принятые им приглашения,
+// которые пересекаются по времени с event, а для серии - с ее вхождениями в течение
+// года. Вхождения той же серии, события на весь день и события без длительности
+// не учитываются.
+func (s *EventService) Conflicts(event *domain.Event) ([]*domain.Event, error) {
+	return s.conflicts(event)
+}
+
+// conflicts ищет пересечения event, пропуская события серий ignore
+func (s *EventService) conflicts(event *domain.Event, ignore ...string) ([]*domain.Event, error) {
+	if !event.OccupiesTime() {
+		return nil, nil
+	}
+	slots := []*domain.Event{event}
+	if event.IsRecurring() {
+		slots = event.Occurrences(event.Date, event.Date.Add(conflictHorizon))
+		if len(slots) == 0 {
+			return nil, nil
+		}
+	}
+
+	candidates, err := s.busyEvents(event.UserID, slots[0].Date, slots[len(slots)-1].EndTime())
+	if err != nil {
+		return nil, err
+	}
+	ignore = append(ignore, seriesKey(event))
+
+	var result []*domain.Event
+	for _, candidate := range candidates {
+		if !candidate.OccupiesTime() || slices.Contains(ignore, seriesKey(candidate)) {
+			continue
+		}
+		for _, slot := range slots {
+			if candidate.Overlaps(slot.Date, slot.EndTime()) {
+				result = append(result, candidate)
+				break
+			}
+		}
+	}
+	sortByStart(result)
+	return result, nil
+}
+
+// checkConflicts возвращает domain.ConflictError, если режим проверки
+// пересечений запрещает сохранять событие, пересекающееся с другими
+func (s *EventService) checkConflicts(event *domain.Event, o *eventOptions, ignore ...string) error {
+	if o.conflictMode != domain.ConflictReject {
+		return nil
+	}
+	conflicts, err := s.conflicts(event, ignore...)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &domain.ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// busyEvents возвращает события и вхождения серий в интервале [start, end)
+// из календаря пользователя и приглашений, которые он принял
+func (s *EventService) busyEvents(userID string, start, end time.Time) ([]*domain.Event, error) {
+	events, err := s.calendarEventsInRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	invited, err := s.invitedEventsInRange(userID, []string{userID}, start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range invited {
+		if event.Attendee(userID).Status == domain.PartStatAccepted {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// seriesKey возвращает ID серии события или ID однократного события
+func seriesKey(event *domain.Event) string {
+	if event.SeriesID != "" {
+		return event.SeriesID
+	}
+	return event.ID
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+func TestEventService_ConflictReject(t *testing.T) {
+	service := newInvitationService()
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	reject := WithConflictMode(domain.ConflictReject)
+
+	meeting, err := service.CreateEvent("user1", "Meeting", day.Add(10*time.Hour), nil, WithEnd(day.Add(11*time.Hour)))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+
+	_, err = service.CreateEvent("user1", "Call", day.Add(10*time.Hour+30*time.Minute), nil, WithEnd(day.Add(12*time.Hour)), reject)
+	var conflict *domain.ConflictError
+	if !errors.As(err, &conflict) || len(conflict.Conflicts) != 1 || conflict.Conflicts[0].ID != meeting.ID {
+		t.Fatalf("Expected conflict with the meeting, got %v", err)
+	}
+	if !errors.Is(err, domain.ErrSchedulingConflict) {
+		t.Errorf("Expected ConflictError to wrap ErrSchedulingConflict")
+	}
+	if events, _ := service.GetEventsForDay("user1", day); len(events) != 1 {
+		t.Errorf("Expected rejected event not to be saved, got %d events", len(events))
+	}
+
+	// Смежные события, события на весь день и без длительности не пересекаются
+	call, err := service.CreateEvent("user1", "Call", day.Add(11*time.Hour), nil, WithEnd(day.Add(12*time.Hour)), reject)
+	if err != nil {
+		t.Fatalf("Expected adjacent event to be saved, got %v", err)
+	}
+	if _, err := service.CreateEvent("user1", "Holiday", day, nil, WithAllDay(true), reject); err != nil {
+		t.Errorf("Expected all-day event to be saved, got %v", err)
+	}
+	if _, err := service.CreateEvent("user1", "Deadline", day.Add(10*time.Hour+30*time.Minute), nil, reject); err != nil {
+		t.Errorf("Expected event without duration to be saved, got %v", err)
+	}
+
+	// Событие не пересекается само с собой при изменении
+	if _, err := service.UpdateEvent("user1", meeting.ID, "Meeting", day.Add(9*time.Hour+30*time.Minute), nil, reject); err != nil {
+		t.Errorf("Expected event to be moved within its own time, got %v", err)
+	}
+	if _, err := service.UpdateEvent("user1", call.ID, "Call", day.Add(10*time.Hour), nil, reject); !errors.Is(err, domain.ErrSchedulingConflict) {
+		t.Errorf("Expected ErrSchedulingConflict on update, got %v", err)
+	}
+	if _, err := service.UpdateEvent("user1", call.ID, "Call", day.Add(10*time.Hour), nil, WithConflictMode(domain.ConflictWarn)); err != nil {
+		t.Fatalf("Expected warn mode to save the event, got %v", err)
+	}
+	conflicts, err := service.Conflicts(mustGetEvent(t, service, "user1", call.ID))
+	if err != nil {
+		t.Fatalf("Conflicts failed: %v", err)
+	}
+	if len(conflicts) != 1 || conflicts[0].ID != meeting.ID {
+		t.Errorf("Expected conflict with the meeting, got %+v", conflicts)
+	}
+}
+
+func TestEventService_ConflictsWithSeriesAndInvitations(t *testing.T) {
+	service := newInvitationService()
+	monday := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	reject := WithConflictMode(domain.ConflictReject)
+
+	rule, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO")
+	standup, err := service.CreateEvent("user2", "Standup", monday, nil, WithEnd(monday.Add(time.Hour)), WithRecurrence(rule))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+
+	nextMonday := monday.AddDate(0, 0, 14).Add(30 * time.Minute)
+	_, err = service.CreateEvent("user2", "Dentist", nextMonday, nil, WithEnd(nextMonday.Add(time.Hour)), reject)
+	var conflict *domain.ConflictError
+	if !errors.As(err, &conflict) || conflict.Conflicts[0].SeriesID != standup.ID || !conflict.Conflicts[0].Date.Equal(monday.AddDate(0, 0, 14)) {
+		t.Fatalf("Expected conflict with the standup occurrence, got %v", err)
+	}
+
+	// Приглашение пересекается с событиями участника, только когда он его принял
+	review, err := service.CreateEvent("user1", "Review", monday.AddDate(0, 0, 1), nil,
+		WithEnd(monday.AddDate(0, 0, 1).Add(time.Hour)), WithAttendees([]string{"user2"}))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	tuesday := monday.AddDate(0, 0, 1).Add(30 * time.Minute)
+	if _, err := service.CreateEvent("user2", "Lunch", tuesday, nil, WithEnd(tuesday.Add(time.Hour)), reject); err != nil {
+		t.Fatalf("Expected unanswered invitation not to conflict, got %v", err)
+	}
+	if _, err := service.RespondToInvitation("user2", review.ID, domain.PartStatAccepted); err != nil {
+		t.Fatalf("RespondToInvitation failed: %v", err)
+	}
+	_, err = service.CreateEvent("user2", "Gym", tuesday, nil, WithEnd(tuesday.Add(time.Hour)), reject)
+	if !errors.As(err, &conflict) || len(conflict.Conflicts) != 2 || conflict.Conflicts[0].ID != review.ID {
+		t.Errorf("Expected conflicts with the review and the lunch, got %v", err)
+	}
+
+	// Новая серия проверяется по своим вхождениям
+	daily, _ := domain.ParseRecurrenceRule("FREQ=DAILY")
+	early := monday.Add(-2 * 24 * time.Hour)
+	if _, err := service.CreateEvent("user2", "Walk", early, nil, WithEnd(early.Add(2*time.Hour)), WithRecurrence(daily), reject); !errors.Is(err, domain.ErrSchedulingConflict) {
+		t.Errorf("Expected series to conflict with the standup, got %v", err)
+	}
+}
+
+func mustGetEvent(t *testing.T, service *EventService, userID, eventID string) *domain.Event {
+	t.Helper()
+	event, err := service.GetEvent(userID, eventID)
+	if err != nil {
+		t.Fatalf("GetEvent failed: %v", err)
+	}
+	return event
+}
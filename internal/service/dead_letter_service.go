@@ -0,0 +1,54 @@
+package service
+
+import (
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// DeadLetterService управляет напоминаниями, которые не удалось доставить
+type DeadLetterService struct {
+	repo      domain.DeadLetterRepository
+	reminders domain.ReminderScheduler
+}
+
+// NewDeadLetterService создает новый сервис недоставленных напоминаний
+func NewDeadLetterService(repo domain.DeadLetterRepository, reminders domain.ReminderScheduler) *DeadLetterService {
+	return &DeadLetterService{repo: repo, reminders: reminders}
+}
+
+// List возвращает недоставленные напоминания
+func (s *DeadLetterService) List() ([]*domain.DeadLetter, error) {
+	return s.repo.List()
+}
+
+// Replay удаляет напоминание из хранилища и снова ставит его в очередь
+// с полным набором попыток. Время напоминания уже прошло, поэтому
+// оно отправляется сразу.
+func (s *DeadLetterService) Replay(id string) error {
+	letter, err := s.repo.Get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	task := letter.Task
+	task.Attempt = 0
+	s.reminders.Schedule(&task)
+	return nil
+}
+
+// ReplayAll повторно отправляет все недоставленные напоминания
+// и возвращает их количество
+func (s *DeadLetterService) ReplayAll() (int, error) {
+	letters, err := s.repo.List()
+	if err != nil {
+		return 0, err
+	}
+	for i, letter := range letters {
+		if err := s.Replay(letter.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(letters), nil
+}
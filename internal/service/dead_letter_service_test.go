@@ -0,0 +1,48 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func TestDeadLetterService_Replay(t *testing.T) {
+	repo := storage.NewMemoryDeadLetterRepository()
+	scheduler := recordingScheduler{}
+	service := NewDeadLetterService(repo, scheduler)
+
+	remindAt := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	repo.Add(&domain.DeadLetter{
+		ID:       "dl1",
+		Task:     domain.ReminderTask{EventID: "e1", UserID: "user1", Time: remindAt, Attempt: 4},
+		Attempts: 5,
+		FailedAt: remindAt.Add(time.Hour),
+	})
+	repo.Add(&domain.DeadLetter{
+		ID:       "dl2",
+		Task:     domain.ReminderTask{EventID: "e2", UserID: "user1", Time: remindAt},
+		FailedAt: remindAt.Add(2 * time.Hour),
+	})
+
+	if err := service.Replay("dl1"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(scheduler["e1"]) != 1 {
+		t.Errorf("Expected replayed reminder to be scheduled, got %v", scheduler["e1"])
+	}
+	if err := service.Replay("dl1"); !errors.Is(err, domain.ErrDeadLetterNotFound) {
+		t.Errorf("Expected error %v, got %v", domain.ErrDeadLetterNotFound, err)
+	}
+
+	count, err := service.ReplayAll()
+	if err != nil || count != 1 {
+		t.Errorf("Expected 1 replayed letter, got %d (%v)", count, err)
+	}
+	letters, _ := service.List()
+	if len(letters) != 0 {
+		t.Errorf("Expected no dead letters left, got %d", len(letters))
+	}
+}
@@ -1,6 +1,9 @@
 package service
 
 import (
+	"errors"
+	"math/rand"
+	"sort"
 	"time"
 
 	"github.com/oziev02/event-calendar-service/internal/domain"
@@ -8,19 +11,106 @@ import (
 
 // EventService обрабатывает бизнес-логику для событий
 type EventService struct {
-	repo domain.EventRepository
+	repo      domain.EventRepository
+	users     domain.UserRepository
+	reminders domain.ReminderScheduler
+	notifiers []domain.ChangeNotifier
+	shares    domain.ShareRepository
+	calendars domain.CalendarRepository
+	invites   domain.InvitationRepository
+}
+
+// Option настраивает необязательные зависимости сервиса событий
+type Option func(*EventService)
+
+// WithUserRepository подключает настройки пользователей (часовой пояс по умолчанию)
+func WithUserRepository(users domain.UserRepository) Option {
+	return func(s *EventService) {
+		s.users = users
+	}
+}
+
+// WithReminderScheduler подключает планировщик, который сервис держит
+// в согласии с напоминаниями событий при создании, изменении и удалении
+func WithReminderScheduler(reminders domain.ReminderScheduler) Option {
+	return func(s *EventService) {
+		s.reminders = reminders
+	}
+}
+
+// WithChangeNotifier подключает получателя изменений событий. Опцию можно
+// передать несколько раз, тогда изменения получают все получатели.
+func WithChangeNotifier(notifier domain.ChangeNotifier) Option {
+	return func(s *EventService) {
+		s.notifiers = append(s.notifiers, notifier)
+	}
+}
+
+// WithShareRepository подключает списки доступа, по которым пользователи
+// видят и изменяют календари других пользователей. Без них каждому
+// пользователю доступен только собственный календарь.
+func WithShareRepository(shares domain.ShareRepository) Option {
+	return func(s *EventService) {
+		s.shares = shares
+	}
+}
+
+// WithCalendarRepository подключает именованные календари пользователей.
+// Без них все события находятся в календаре по умолчанию.
+func WithCalendarRepository(calendars domain.CalendarRepository) Option {
+	return func(s *EventService) {
+		s.calendars = calendars
+	}
+}
+
+// WithInvitationRepository подключает приглашения, по которым участники
+// видят события других пользователей и отвечают на них. Без них
+// участники события сохраняются, но приглашения им не показываются.
+func WithInvitationRepository(invites domain.InvitationRepository) Option {
+	return func(s *EventService) {
+		s.invites = invites
+	}
 }
 
 // NewEventService создает новый сервис событий
-func NewEventService(repo domain.EventRepository) *EventService {
-	return &EventService{repo: repo}
+func NewEventService(repo domain.EventRepository, opts ...Option) *EventService {
+	s := &EventService{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-// CreateEvent создает новое событие
-func (s *EventService) CreateEvent(userID, text string, date time.Time, reminderTime *time.Time) (*domain.Event, error) {
+// UserLocation возвращает часовой пояс пользователя (UTC, если он не задан)
+func (s *EventService) UserLocation(userID string) (*time.Location, error) {
+	if s.users == nil {
+		return time.UTC, nil
+	}
+	user, err := s.users.Get(userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return time.UTC, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user.Location(), nil
+}
+
+// CreateEvent создает новое событие в календаре пользователя или, с опцией
+// WithOwner, в календаре другого пользователя, открытом ему на запись
+func (s *EventService) CreateEvent(userID, text string, date time.Time, reminderTime *time.Time, opts ...EventOption) (*domain.Event, error) {
+	o := newEventOptions(opts)
+	ownerID := userID
+	if o.owner != "" && o.owner != userID {
+		if err := s.authorize(userID, o.owner, domain.RoleWrite); err != nil {
+			return nil, err
+		}
+		ownerID = o.owner
+	}
+
 	event := &domain.Event{
 		ID:           generateID(),
-		UserID:       userID,
+		UserID:       ownerID,
 		Text:         text,
 		Date:         date,
 		ReminderTime: reminderTime,
@@ -28,74 +118,223 @@ func (s *EventService) CreateEvent(userID, text string, date time.Time, reminder
 		UpdatedAt:    time.Now(),
 		Archived:     false,
 	}
+	o.apply(event)
+	if err := s.applyCalendarDefaults(event); err != nil {
+		return nil, err
+	}
+	s.applyUserTimeZone(event)
 
 	if err := event.Validate(); err != nil {
 		return nil, err
 	}
+	if err := s.checkConflicts(event, o); err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.Create(event); err != nil {
+	if err := s.create(event); err != nil {
 		return nil, err
 	}
 
 	return event, nil
 }
 
-// UpdateEvent обновляет существующее событие
-func (s *EventService) UpdateEvent(userID, eventID, text string, date time.Time, reminderTime *time.Time) (*domain.Event, error) {
-	event, err := s.repo.GetByID(userID, eventID)
+// applyUserTimeZone задает событию без явного пояса пояс пользователя,
+// чтобы событие повторялось по его времени
+func (s *EventService) applyUserTimeZone(event *domain.Event) {
+	if event.TimeZone != "" || s.users == nil {
+		return
+	}
+	if user, err := s.users.Get(event.UserID); err == nil {
+		event.TimeZone = user.TimeZone
+	}
+}
+
+// UpdateEvent обновляет событие пользователя или событие календаря,
+// открытого ему на запись
+func (s *EventService) UpdateEvent(userID, eventID, text string, date time.Time, reminderTime *time.Time, opts ...EventOption) (*domain.Event, error) {
+	event, err := s.findEvent(userID, eventID, domain.RoleWrite)
 	if err != nil {
 		return nil, err
 	}
 
+	o := newEventOptions(opts)
+	if o.calendarSet && o.calendarID != "" {
+		// Событие переносится только между календарями его владельца
+		if _, err := s.calendar(event.UserID, o.calendarID); err != nil {
+			return nil, err
+		}
+	}
+	if event.IsRecurring() || event.IsOverride() {
+		return s.updateSeries(event, text, date, reminderTime, o)
+	}
+
 	event.Text = text
-	event.Date = date
 	event.ReminderTime = reminderTime
 	event.UpdatedAt = time.Now()
+	o.reschedule(event, date)
 
 	if err := event.Validate(); err != nil {
 		return nil, err
 	}
+	if err := s.checkConflicts(event, o); err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.Update(event); err != nil {
+	if err := s.update(event); err != nil {
 		return nil, err
 	}
 
 	return event, nil
 }
 
-// DeleteEvent удаляет событие
-func (s *EventService) DeleteEvent(userID, eventID string) error {
-	_, err := s.repo.GetByID(userID, eventID)
+// DeleteEvent удаляет событие пользователя или событие календаря, открытого
+// ему на запись. Для повторяющихся событий область удаления задается опцией WithScope.
+func (s *EventService) DeleteEvent(userID, eventID string, opts ...EventOption) error {
+	event, err := s.findEvent(userID, eventID, domain.RoleWrite)
 	if err != nil {
 		return err
 	}
 
-	return s.repo.Delete(userID, eventID)
+	if event.IsRecurring() || event.IsOverride() {
+		return s.deleteSeries(event, newEventOptions(opts))
+	}
+
+	return s.delete(event)
+}
+
+// ArchiveOldEvents архивирует события, закончившиеся раньше before,
+// и сообщает получателям изменений о каждом архивированном событии
+func (s *EventService) ArchiveOldEvents(before time.Time) ([]*domain.Event, error) {
+	archived, err := s.repo.ArchiveOldEvents(before)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range archived {
+		s.notify(domain.ChangeArchived, event)
+	}
+	return archived, nil
+}
+
+// GetEvent возвращает событие пользователя, открытого ему календаря
+// или событие, на которое он приглашен, по ID
+func (s *EventService) GetEvent(userID, eventID string) (*domain.Event, error) {
+	return s.findEvent(userID, eventID, domain.RoleRead)
+}
+
+// GetEvents возвращает события и вхождения серий, пересекающиеся с интервалом [from, to),
+// из календаря пользователя, открытых ему календарей и приглашений
+func (s *EventService) GetEvents(userID string, from, to time.Time) ([]*domain.Event, error) {
+	if !to.After(from) {
+		return nil, domain.ErrInvalidRange
+	}
+	return s.eventsInRange(userID, from, to, nil)
+}
+
+// ExportEvents возвращает все события календаря пользователя, включая архивные, для экспорта
+// в iCalendar. Серии не разворачиваются. События упорядочены по дате и ID,
+// чтобы одинаковые данные давали одинаковый результат.
+func (s *EventService) ExportEvents(userID string) ([]*domain.Event, error) {
+	events, err := s.repo.GetAllActive(userID)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := s.repo.GetArchived(userID)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, archived...)
+
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Date.Equal(events[j].Date) {
+			return events[i].Date.Before(events[j].Date)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
 }
 
-// GetEventsForDay возвращает события за конкретный день
-func (s *EventService) GetEventsForDay(userID string, date time.Time) ([]*domain.Event, error) {
+// GetEventsForDay возвращает события за конкретный день.
+// Границы дня считаются в часовом поясе date. Если заданы calendarIDs,
+// возвращаются только события этих календарей.
+func (s *EventService) GetEventsForDay(userID string, date time.Time, calendarIDs ...string) ([]*domain.Event, error) {
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	end := start.Add(24 * time.Hour)
-	
-	return s.repo.GetByDateRange(userID, start, end)
+	// AddDate учитывает переход на летнее время, когда в сутках 23 или 25 часов
+	end := start.AddDate(0, 0, 1)
+
+	return s.eventsInRange(userID, start, end, calendarIDs)
 }
 
 // GetEventsForWeek возвращает события за неделю, начиная с указанной даты
-func (s *EventService) GetEventsForWeek(userID string, date time.Time) ([]*domain.Event, error) {
+func (s *EventService) GetEventsForWeek(userID string, date time.Time, calendarIDs ...string) ([]*domain.Event, error) {
 	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	end := start.Add(7 * 24 * time.Hour)
-	
-	return s.repo.GetByDateRange(userID, start, end)
+	end := start.AddDate(0, 0, 7)
+
+	return s.eventsInRange(userID, start, end, calendarIDs)
 }
 
 // GetEventsForMonth возвращает события за месяц
-func (s *EventService) GetEventsForMonth(userID string, date time.Time) ([]*domain.Event, error) {
+func (s *EventService) GetEventsForMonth(userID string, date time.Time, calendarIDs ...string) ([]*domain.Event, error) {
 	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
 	nextMonth := start.AddDate(0, 1, 0)
 	end := time.Date(nextMonth.Year(), nextMonth.Month(), 1, 0, 0, 0, 0, nextMonth.Location())
-	
-	return s.repo.GetByDateRange(userID, start, end)
+
+	return s.eventsInRange(userID, start, end, calendarIDs)
+}
+
+// eventsInRange возвращает однократные события и развернутые вхождения
+// повторяющихся событий в интервале [start, end) из календаря пользователя,
+// открытых ему календарей и событий, на которые он приглашен, отсортированные
+// по дате. Владелец каждого события указан в его UserID. Непустой calendarIDs
+// ограничивает выборку этими календарями пользователя, без приглашений.
+func (s *EventService) eventsInRange(userID string, start, end time.Time, calendarIDs []string) ([]*domain.Event, error) {
+	owners, err := s.readableOwners(userID)
+	if err != nil {
+		return nil, err
+	}
+	var result []*domain.Event
+	for _, ownerID := range owners {
+		events, err := s.calendarEventsInRange(ownerID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, events...)
+	}
+	if len(calendarIDs) > 0 {
+		result = newCalendarFilter(calendarIDs).filter(result)
+	} else {
+		invited, err := s.invitedEventsInRange(userID, owners, start, end)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, invited...)
+	}
+	sortByStart(result)
+	return result, nil
+}
+
+// calendarEventsInRange возвращает события и вхождения серий календаря одного владельца
+func (s *EventService) calendarEventsInRange(ownerID string, start, end time.Time) ([]*domain.Event, error) {
+	events, err := s.repo.GetByDateRange(ownerID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*domain.Event, 0, len(events))
+	for _, event := range events {
+		// Серии попадают в выборку по дате первого вхождения и разворачиваются ниже
+		if !event.IsRecurring() {
+			result = append(result, event)
+		}
+	}
+
+	series, err := s.repo.GetRecurring(ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range series {
+		result = append(result, event.Occurrences(start, end)...)
+	}
+	return result, nil
 }
 
 // generateID генерирует простой ID (в продакшене использовать UUID)
@@ -107,7 +346,7 @@ func randomString(length int) string {
 	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	b := make([]byte, length)
 	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
+		b[i] = charset[rand.Intn(len(charset))]
 	}
 	return string(b)
 }
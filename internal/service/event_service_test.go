@@ -252,3 +252,695 @@ func TestEventService_GetEventsForMonth(t *testing.T) {
 	}
 }
 
+func TestEventService_GetEventsForWeek_Recurring(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	userID := "user1"
+	// Понедельник
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	rule, err := domain.ParseRecurrenceRule("FREQ=WEEKLY;BYDAY=MO,WE,FR")
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+
+	series, err := service.CreateEvent(userID, "Stand-up", start, nil, WithRecurrence(rule))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	events, err := service.GetEventsForWeek(userID, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 occurrences, got %d", len(events))
+	}
+
+	wantDays := []int{15, 17, 19}
+	for i, e := range events {
+		if e.SeriesID != series.ID {
+			t.Errorf("Expected SeriesID %s, got %s", series.ID, e.SeriesID)
+		}
+		if e.RecurrenceID == nil || !e.RecurrenceID.Equal(e.Date) {
+			t.Errorf("Expected RecurrenceID equal to occurrence date %v", e.Date)
+		}
+		if e.Date.Day() != wantDays[i] || e.Date.Hour() != 10 {
+			t.Errorf("Expected occurrence on day %d at 10:00, got %v", wantDays[i], e.Date)
+		}
+	}
+}
+
+func TestEventService_GetEventsForMonth_RecurringCount(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	userID := "user1"
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rule, err := domain.ParseRecurrenceRule("FREQ=DAILY;INTERVAL=2;COUNT=5")
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+
+	if _, err := service.CreateEvent(userID, "Every other day", start, nil, WithRecurrence(rule)); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	events, err := service.GetEventsForMonth(userID, start)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(events) != 5 {
+		t.Errorf("Expected 5 occurrences, got %d", len(events))
+	}
+}
+
+func createWeeklySeries(t *testing.T, service *EventService, rrule string) *domain.Event {
+	t.Helper()
+	rule, err := domain.ParseRecurrenceRule(rrule)
+	if err != nil {
+		t.Fatalf("Failed to parse rule: %v", err)
+	}
+	// Понедельник
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	series, err := service.CreateEvent("user1", "Weekly", start, nil, WithRecurrence(rule))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	return series
+}
+
+func TestEventService_UpdateEvent_ThisOccurrence(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+	series := createWeeklySeries(t, service, "FREQ=WEEKLY")
+
+	occurrence := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC)
+	moved := time.Date(2024, 1, 9, 15, 0, 0, 0, time.UTC)
+
+	override, err := service.UpdateEvent("user1", series.ID, "Moved", moved, nil, WithScope(domain.ScopeThis, occurrence))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if override.SeriesID != series.ID || !override.RecurrenceID.Equal(occurrence) {
+		t.Errorf("Expected override of %s at %v, got %+v", series.ID, occurrence, override)
+	}
+
+	events, err := service.GetEventsForWeek("user1", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(events))
+	}
+	if events[0].ID != override.ID || !events[0].Date.Equal(moved) {
+		t.Errorf("Expected moved occurrence %v, got %+v", moved, events[0])
+	}
+
+	// Остальные вхождения не изменились
+	events, _ = service.GetEventsForWeek("user1", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if len(events) != 1 || events[0].Text != "Weekly" {
+		t.Errorf("Expected unchanged occurrence, got %+v", events)
+	}
+}
+
+func TestEventService_DeleteEvent_ThisOccurrence(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+	series := createWeeklySeries(t, service, "FREQ=WEEKLY")
+
+	occurrence := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC)
+	if err := service.DeleteEvent("user1", series.ID, WithScope(domain.ScopeThis, occurrence)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events, _ := service.GetEventsForMonth("user1", occurrence)
+	if len(events) != 4 {
+		t.Errorf("Expected 4 occurrences in January, got %d", len(events))
+	}
+	for _, e := range events {
+		if e.Date.Equal(occurrence) {
+			t.Errorf("Expected occurrence %v to be cancelled", occurrence)
+		}
+	}
+
+	err := service.DeleteEvent("user1", series.ID, WithScope(domain.ScopeThis, occurrence))
+	if !errors.Is(err, domain.ErrEventNotFound) {
+		t.Errorf("Expected error %v, got %v", domain.ErrEventNotFound, err)
+	}
+}
+
+func TestEventService_UpdateEvent_ThisAndFollowing(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+	series := createWeeklySeries(t, service, "FREQ=WEEKLY;COUNT=6")
+
+	occurrence := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	newStart := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	following, err := service.UpdateEvent("user1", series.ID, "Rescheduled", newStart, nil, WithScope(domain.ScopeFollowing, occurrence))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if following.ID == series.ID || following.Recurrence.Count != 4 {
+		t.Errorf("Expected new series with 4 remaining occurrences, got %+v", following)
+	}
+
+	events, _ := service.GetEventsForMonth("user1", occurrence)
+	if len(events) != 5 {
+		t.Fatalf("Expected 5 occurrences in January, got %d", len(events))
+	}
+	for _, e := range events {
+		before := e.Date.Before(occurrence)
+		if before && (e.Text != "Weekly" || e.Date.Hour() != 10) {
+			t.Errorf("Expected original occurrence before split, got %+v", e)
+		}
+		if !before && (e.Text != "Rescheduled" || e.Date.Hour() != 12) {
+			t.Errorf("Expected rescheduled occurrence after split, got %+v", e)
+		}
+	}
+}
+
+func TestEventService_UpdateEvent_ThisAndFollowing_KeepsOverrides(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+	series := createWeeklySeries(t, service, "FREQ=WEEKLY")
+
+	moved := time.Date(2024, 1, 23, 10, 0, 0, 0, time.UTC)
+	override, err := service.UpdateEvent("user1", series.ID, "Moved", moved, nil,
+		WithScope(domain.ScopeThis, time.Date(2024, 1, 22, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("Failed to move occurrence: %v", err)
+	}
+
+	// Разделение раньше измененного вхождения переносит его в новую серию
+	occurrence := time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC)
+	following, err := service.UpdateEvent("user1", series.ID, "Rescheduled", occurrence.Add(2*time.Hour), nil,
+		WithScope(domain.ScopeFollowing, occurrence))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	stored := mustGetEvent(t, service, "user1", override.ID)
+	if want := time.Date(2024, 1, 22, 12, 0, 0, 0, time.UTC); stored.SeriesID != following.ID || !stored.RecurrenceID.Equal(want) {
+		t.Errorf("Expected override of %s at %v, got %s at %v", following.ID, want, stored.SeriesID, stored.RecurrenceID)
+	}
+
+	events, _ := service.GetEventsForWeek("user1", time.Date(2024, 1, 22, 0, 0, 0, 0, time.UTC))
+	if len(events) != 1 || events[0].ID != override.ID || !events[0].Date.Equal(moved) {
+		t.Errorf("Expected only moved occurrence %v, got %+v", moved, events)
+	}
+}
+
+func TestEventService_DeleteEvent_ThisAndFollowing(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+	series := createWeeklySeries(t, service, "FREQ=WEEKLY")
+
+	// Измененное вхождение после точки разделения удаляется вместе с серией
+	_, err := service.UpdateEvent("user1", series.ID, "Moved", time.Date(2024, 1, 23, 10, 0, 0, 0, time.UTC), nil,
+		WithScope(domain.ScopeThis, time.Date(2024, 1, 22, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("Failed to move occurrence: %v", err)
+	}
+
+	occurrence := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	if err := service.DeleteEvent("user1", series.ID, WithScope(domain.ScopeFollowing, occurrence)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events, _ := service.GetEventsForMonth("user1", occurrence)
+	if len(events) != 2 {
+		t.Errorf("Expected 2 occurrences before split, got %d", len(events))
+	}
+	for _, e := range events {
+		if !e.Date.Before(occurrence) {
+			t.Errorf("Expected no occurrences after %v, got %v", occurrence, e.Date)
+		}
+	}
+}
+
+func TestEventService_UpdateEvent_MoveSeriesStart(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+	series := createWeeklySeries(t, service, "FREQ=WEEKLY")
+
+	if err := service.DeleteEvent("user1", series.ID, WithScope(domain.ScopeThis, time.Date(2024, 1, 8, 10, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("Failed to cancel occurrence: %v", err)
+	}
+	moved := time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC)
+	override, err := service.UpdateEvent("user1", series.ID, "Moved", moved, nil,
+		WithScope(domain.ScopeThis, time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("Failed to move occurrence: %v", err)
+	}
+
+	// Перенос начала самой серии с понедельника на вторник сдвигает исключения и замены
+	if _, err := service.UpdateEvent("user1", series.ID, "Weekly", time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), nil); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	events, _ := service.GetEventsForWeek("user1", time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC))
+	if len(events) != 0 {
+		t.Errorf("Expected cancelled occurrence to stay cancelled, got %+v", events)
+	}
+	events, _ = service.GetEventsForWeek("user1", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if len(events) != 1 || events[0].ID != override.ID || !events[0].Date.Equal(moved) {
+		t.Errorf("Expected only moved occurrence %v, got %+v", moved, events)
+	}
+	stored := mustGetEvent(t, service, "user1", override.ID)
+	if want := time.Date(2024, 1, 16, 10, 0, 0, 0, time.UTC); !stored.RecurrenceID.Equal(want) {
+		t.Errorf("Expected recurrence id %v, got %v", want, stored.RecurrenceID)
+	}
+}
+
+func TestEventService_CreateEvent_EndBeforeStart(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	_, err := service.CreateEvent("user1", "Meeting", start, nil, WithEnd(start.Add(-time.Hour)))
+	if !errors.Is(err, domain.ErrInvalidEndTime) {
+		t.Errorf("Expected error %v, got %v", domain.ErrInvalidEndTime, err)
+	}
+}
+
+func TestEventService_GetEventsForDay_Overlapping(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	userID := "user1"
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	// Многодневное событие, начавшееся до запрошенного дня
+	_, err := service.CreateEvent(userID, "Conference", day.AddDate(0, 0, -2), nil,
+		WithAllDay(true), WithEnd(day.AddDate(0, 0, 1)))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	// Ночная смена, переходящая через полночь
+	_, err = service.CreateEvent(userID, "Night shift", day.Add(-2*time.Hour), nil, WithEnd(day.Add(6*time.Hour)))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	// Закончилось ровно в начале дня
+	_, err = service.CreateEvent(userID, "Late call", day.Add(-time.Hour), nil, WithEnd(day))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	// Событие на весь предыдущий день
+	_, err = service.CreateEvent(userID, "Yesterday", day.AddDate(0, 0, -1), nil, WithAllDay(true))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	events, err := service.GetEventsForDay(userID, day)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Text != "Conference" || events[1].Text != "Night shift" {
+		t.Errorf("Unexpected events %s, %s", events[0].Text, events[1].Text)
+	}
+}
+
+func TestEventService_UpdateEvent_KeepsDuration(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	event, err := service.CreateEvent("user1", "Meeting", start, nil, WithEnd(start.Add(90*time.Minute)))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	newStart := time.Date(2024, 1, 16, 14, 0, 0, 0, time.UTC)
+	updated, err := service.UpdateEvent("user1", event.ID, "Meeting", newStart, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !updated.End.Equal(newStart.Add(90 * time.Minute)) {
+		t.Errorf("Expected End %v, got %v", newStart.Add(90*time.Minute), updated.End)
+	}
+}
+
+func TestEventService_GetEventsForDay_RecurringOverlapping(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	rule, _ := domain.ParseRecurrenceRule("FREQ=DAILY")
+	start := time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)
+	_, err := service.CreateEvent("user1", "On-call", start, nil, WithRecurrence(rule), WithEnd(start.Add(4*time.Hour)))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	// В день попадают вхождение, начавшееся накануне вечером, и вечернее вхождение
+	events, err := service.GetEventsForDay("user1", time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 occurrences, got %d", len(events))
+	}
+	if events[0].Date.Day() != 9 || !events[0].End.Equal(events[0].Date.Add(4*time.Hour)) {
+		t.Errorf("Unexpected first occurrence %v - %v", events[0].Date, events[0].End)
+	}
+}
+
+func TestEventService_CreateEvent_UserTimeZone(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "user1", TimeZone: "Europe/Moscow"})
+	service := NewEventService(repo, WithUserRepository(users))
+
+	moscow, _ := time.LoadLocation("Europe/Moscow")
+	event, err := service.CreateEvent("user1", "Meeting", time.Date(2024, 1, 15, 1, 0, 0, 0, moscow), nil)
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if event.TimeZone != "Europe/Moscow" {
+		t.Errorf("Expected TimeZone %s, got %s", "Europe/Moscow", event.TimeZone)
+	}
+
+	// 01:00 по Москве — это 22:00 UTC предыдущего дня
+	events, _ := service.GetEventsForDay("user1", time.Date(2024, 1, 15, 0, 0, 0, 0, moscow))
+	if len(events) != 1 {
+		t.Errorf("Expected 1 event in Moscow day, got %d", len(events))
+	}
+	events, _ = service.GetEventsForDay("user1", time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+	if len(events) != 0 {
+		t.Errorf("Expected 0 events in UTC day, got %d", len(events))
+	}
+}
+
+func TestEventService_GetEventsForDay_DSTTransition(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	// 10 марта 2024 года в Нью-Йорке длится 23 часа
+	late := time.Date(2024, 3, 10, 23, 30, 0, 0, newYork)
+	if _, err := service.CreateEvent("user1", "Late", late, nil); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	next := time.Date(2024, 3, 11, 0, 30, 0, 0, newYork)
+	if _, err := service.CreateEvent("user1", "Next day", next, nil); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	events, err := service.GetEventsForDay("user1", time.Date(2024, 3, 10, 0, 0, 0, 0, newYork))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Text != "Late" {
+		t.Errorf("Expected only the late event, got %d events", len(events))
+	}
+}
+
+func TestEventService_Recurring_WallClockAcrossDST(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	rule, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY")
+	start := time.Date(2024, 3, 4, 9, 0, 0, 0, newYork)
+	_, err := service.CreateEvent("user1", "Standup", start, nil,
+		WithRecurrence(rule), WithTimeZone("America/New_York"), WithEnd(start.Add(30*time.Minute)))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	events, err := service.GetEventsForMonth("user1", time.Date(2024, 3, 1, 0, 0, 0, 0, newYork))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("Expected 4 occurrences, got %d", len(events))
+	}
+	for _, event := range events {
+		local := event.Date.In(newYork)
+		if local.Hour() != 9 || local.Minute() != 0 {
+			t.Errorf("Expected occurrence at 09:00 local time, got %v", local)
+		}
+		if event.End.Sub(event.Date) != 30*time.Minute {
+			t.Errorf("Expected 30m duration, got %v", event.End.Sub(event.Date))
+		}
+	}
+}
+
+func TestEventService_PendingReminders(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+	if _, err := service.CreateEvent("user1", "Past", now, &past); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if _, err := service.CreateEvent("user2", "Future", now.Add(2*time.Hour), &future); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if _, err := service.CreateEvent("user1", "No reminder", now, nil); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	tasks, err := service.PendingReminders(now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(tasks) != 1 {
+		t.Fatalf("Expected 1 pending reminder, got %d", len(tasks))
+	}
+	if tasks[0].UserID != "user2" || !tasks[0].Time.Equal(future) {
+		t.Errorf("Unexpected reminder %+v", tasks[0])
+	}
+}
+
+// recordingScheduler запоминает запланированные напоминания по событиям
+type recordingScheduler map[string][]time.Time
+
+func (r recordingScheduler) Schedule(task *domain.ReminderTask) {
+	r[task.EventID] = append(r[task.EventID], task.Time)
+}
+
+func (r recordingScheduler) Cancel(eventID string) {
+	delete(r, eventID)
+}
+
+func TestEventService_RemindersFollowEventChanges(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	scheduler := recordingScheduler{}
+	service := NewEventService(repo, WithReminderScheduler(scheduler))
+
+	date := time.Now().Add(48 * time.Hour)
+	reminder := date.Add(-time.Hour)
+	event, err := service.CreateEvent("user1", "Meeting", date, &reminder)
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if len(scheduler[event.ID]) != 1 {
+		t.Fatalf("Expected 1 scheduled reminder, got %d", len(scheduler[event.ID]))
+	}
+
+	moved := reminder.Add(24 * time.Hour)
+	if _, err := service.UpdateEvent("user1", event.ID, "Meeting", date.Add(24*time.Hour), &moved); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if times := scheduler[event.ID]; len(times) != 1 || !times[0].Equal(moved) {
+		t.Errorf("Expected only the rescheduled reminder, got %v", times)
+	}
+
+	if _, err := service.UpdateEvent("user1", event.ID, "Meeting", date, nil); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if len(scheduler[event.ID]) != 0 {
+		t.Errorf("Expected removed reminder to be cancelled, got %v", scheduler[event.ID])
+	}
+
+	if _, err := service.UpdateEvent("user1", event.ID, "Meeting", date, &reminder); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if err := service.DeleteEvent("user1", event.ID); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+	if len(scheduler[event.ID]) != 0 {
+		t.Errorf("Expected reminders of deleted event to be cancelled, got %v", scheduler[event.ID])
+	}
+}
+
+func TestEventService_RelativeRemindersFollowDate(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	scheduler := recordingScheduler{}
+	service := NewEventService(repo, WithReminderScheduler(scheduler))
+
+	date := time.Now().Add(72 * time.Hour).Truncate(time.Minute)
+	at := date.Add(-48 * time.Hour)
+	reminders := []domain.Reminder{
+		{Offset: 24 * time.Hour},
+		{Offset: 15 * time.Minute},
+		{At: &at},
+	}
+	event, err := service.CreateEvent("user1", "Meeting", date, nil, WithReminders(reminders))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	if len(scheduler[event.ID]) != 3 {
+		t.Fatalf("Expected 3 scheduled reminders, got %d", len(scheduler[event.ID]))
+	}
+
+	moved := date.Add(2 * time.Hour)
+	if _, err := service.UpdateEvent("user1", event.ID, "Meeting", moved, nil); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+
+	expected := []time.Time{moved.Add(-24 * time.Hour), moved.Add(-15 * time.Minute), at}
+	times := scheduler[event.ID]
+	if len(times) != len(expected) {
+		t.Fatalf("Expected %d reminders, got %d", len(expected), len(times))
+	}
+	for i := range expected {
+		if !times[i].Equal(expected[i]) {
+			t.Errorf("Expected reminder %d at %v, got %v", i, expected[i], times[i])
+		}
+	}
+}
+
+func TestEventService_CreateEvent_InvalidReminder(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	_, err := service.CreateEvent("user1", "Meeting", time.Now(), nil,
+		WithReminders([]domain.Reminder{{Offset: -time.Hour}}))
+	if !errors.Is(err, domain.ErrInvalidReminder) {
+		t.Errorf("Expected error %v, got %v", domain.ErrInvalidReminder, err)
+	}
+}
+
+func TestEventService_PendingReminders_NextOccurrence(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	rule, _ := domain.ParseRecurrenceRule("FREQ=DAILY")
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	_, err := service.CreateEvent("user1", "Standup", start, nil,
+		WithRecurrence(rule), WithReminders([]domain.Reminder{{Offset: 10 * time.Minute}}))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	tasks, err := service.PendingReminders(now)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	expected := time.Date(2024, 1, 11, 8, 50, 0, 0, time.UTC)
+	if len(tasks) != 1 || !tasks[0].Time.Equal(expected) {
+		t.Errorf("Expected reminder at %v, got %+v", expected, tasks)
+	}
+}
+
+func TestEventService_NextReminder(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	rule, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY")
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	_, err := service.CreateEvent("user1", "Planning", start, nil, WithRecurrence(rule),
+		WithReminders([]domain.Reminder{{Offset: 24 * time.Hour}, {Offset: 15 * time.Minute}}))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	tasks, _ := service.PendingReminders(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC))
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 pending reminders, got %d", len(tasks))
+	}
+
+	// Каждое напоминание после отправки переходит на следующее вхождение
+	for _, task := range tasks {
+		next := service.NextReminder(task)
+		if next == nil {
+			t.Fatalf("Expected next reminder for %v", task.Time)
+		}
+		if next.Index != task.Index || !next.Time.Equal(task.Time.AddDate(0, 0, 7)) {
+			t.Errorf("Expected reminder %d at %v, got %d at %v", task.Index, task.Time.AddDate(0, 0, 7), next.Index, next.Time)
+		}
+	}
+}
+
+func TestEventService_PendingReminders_AfterRestart(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	rule, _ := domain.ParseRecurrenceRule("FREQ=DAILY")
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	_, err := service.CreateEvent("user1", "Standup", start, nil, WithRecurrence(rule),
+		WithReminders([]domain.Reminder{{Offset: 24 * time.Hour}, {Offset: 10 * time.Minute}}))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	// Перезапуск между напоминанием за 10 минут и началом вхождения 10 января
+	tasks, _ := service.PendingReminders(time.Date(2024, 1, 10, 8, 55, 0, 0, time.UTC))
+	expected := []time.Time{
+		time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 11, 8, 50, 0, 0, time.UTC),
+	}
+	if len(tasks) != len(expected) {
+		t.Fatalf("Expected %d pending reminders, got %+v", len(expected), tasks)
+	}
+	for i, task := range tasks {
+		if task.Index != i || !task.Time.Equal(expected[i]) {
+			t.Errorf("Expected reminder %d at %v, got %d at %v", i, expected[i], task.Index, task.Time)
+		}
+		if want := time.Date(2024, 1, 11, 9, 0, 0, 0, time.UTC); !task.Start.Equal(want) {
+			t.Errorf("Expected reminder %d for occurrence %v, got %v", i, want, task.Start)
+		}
+	}
+
+	// Напоминание за сутки переходит на следующие сутки, а не через одно вхождение
+	next := service.NextReminder(tasks[0])
+	if next == nil || !next.Time.Equal(expected[0].AddDate(0, 0, 1)) {
+		t.Errorf("Expected next reminder at %v, got %+v", expected[0].AddDate(0, 0, 1), next)
+	}
+}
+
+func TestEventService_Reminders_InsideReminderWindow(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	scheduler := recordingScheduler{}
+	service := NewEventService(repo, WithReminderScheduler(scheduler))
+
+	// До ближайшего вхождения 12 часов: напоминание за сутки для него уже прошло
+	rule, _ := domain.ParseRecurrenceRule("FREQ=DAILY")
+	start := time.Now().Add(12 * time.Hour).Truncate(time.Minute)
+	event, err := service.CreateEvent("user1", "Standup", start, nil, WithRecurrence(rule),
+		WithReminders([]domain.Reminder{{Offset: 24 * time.Hour}, {Offset: 10 * time.Minute}}))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+
+	check := func(stage string) {
+		t.Helper()
+		times := scheduler[event.ID]
+		if len(times) != 2 {
+			t.Fatalf("%s: expected 2 scheduled reminders, got %v", stage, times)
+		}
+		if !times[0].Equal(start) || !times[1].Equal(start.Add(-10*time.Minute)) {
+			t.Errorf("%s: expected reminders at %v and %v, got %v", stage, start, start.Add(-10*time.Minute), times)
+		}
+	}
+	check("create")
+
+	if _, err := service.UpdateEvent("user1", event.ID, "Daily standup", start, nil); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	check("update")
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const (
+	// maxFreeBusyRange ограничивает интервал запроса занятости и подбора встреч
+	maxFreeBusyRange = 92 * 24 * time.Hour
+	// maxFreeBusyUsers ограничивает число пользователей в одном запросе
+	maxFreeBusyUsers = 50
+	// maxSlots ограничивает число предлагаемых слотов
+	maxSlots = 100
+	// slotStep - шаг, с которым начинаются предлагаемые слоты
+	slotStep = 15 * time.Minute
+)
+
+// FreeBusy возвращает для каждого пользователя упорядоченные интервалы в [from, to),
+// когда он занят событиями своего календаря или принятыми приглашениями.
+// Пересекающиеся и смежные события объединяются в один интервал; события
+// на весь день и события без длительности время не занимают.
+func (s *EventService) FreeBusy(userIDs []string, from, to time.Time) (map[string][]domain.Interval, error) {
+	if err := checkFreeBusyQuery(userIDs, from, to); err != nil {
+		return nil, err
+	}
+	result := make(map[string][]domain.Interval, len(userIDs))
+	for _, userID := range userIDs {
+		busy, err := s.busyIntervals(userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		result[userID] = busy
+	}
+	return result, nil
+}
+
+// FindSlots возвращает до limit первых интервалов длительностью duration в [from, to),
+// когда все пользователи свободны и находятся в своем рабочем времени по местному
+// времени их часовых поясов. Слоты начинаются в моменты, кратные 15 минутам,
+// и не пересекаются друг с другом.
+func (s *EventService) FindSlots(userIDs []string, from, to time.Time, duration time.Duration, limit int) ([]domain.Interval, error) {
+	if err := checkFreeBusyQuery(userIDs, from, to); err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("%w: duration must be positive", domain.ErrInvalidFreeBusyQuery)
+	}
+	if limit < 1 || limit > maxSlots {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", domain.ErrInvalidFreeBusyQuery, maxSlots)
+	}
+
+	var unavailable []domain.Interval
+	for _, userID := range userIDs {
+		busy, err := s.busyIntervals(userID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		hours, loc, err := s.workingHours(userID)
+		if err != nil {
+			return nil, err
+		}
+		unavailable = append(unavailable, busy...)
+		unavailable = append(unavailable, domain.Gaps(hours.Windows(loc, from, to), from, to)...)
+	}
+
+	var slots []domain.Interval
+	for _, gap := range domain.Gaps(domain.MergeIntervals(unavailable), from, to) {
+		for start := ceilTime(gap.Start, slotStep); !start.Add(duration).After(gap.End); start = ceilTime(start.Add(duration), slotStep) {
+			slots = append(slots, domain.Interval{Start: start, End: start.Add(duration)})
+			if len(slots) == limit {
+				return slots, nil
+			}
+		}
+	}
+	return slots, nil
+}
+
+// busyIntervals возвращает объединенные интервалы занятости пользователя, обрезанные по [from, to)
+func (s *EventService) busyIntervals(userID string, from, to time.Time) ([]domain.Interval, error) {
+	events, err := s.busyEvents(userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	intervals := make([]domain.Interval, 0, len(events))
+	for _, event := range events {
+		if !event.OccupiesTime() {
+			continue
+		}
+		start, end := event.Date, event.EndTime()
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			intervals = append(intervals, domain.Interval{Start: start, End: end})
+		}
+	}
+	return domain.MergeIntervals(intervals), nil
+}
+
+// workingHours возвращает рабочее время и часовой пояс пользователя
+func (s *EventService) workingHours(userID string) (domain.WorkingHours, *time.Location, error) {
+	if s.users == nil {
+		return domain.DefaultWorkingHours(), time.UTC, nil
+	}
+	user, err := s.users.Get(userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.DefaultWorkingHours(), time.UTC, nil
+	}
+	if err != nil {
+		return domain.WorkingHours{}, nil, err
+	}
+	return user.Hours(), user.Location(), nil
+}
+
+func checkFreeBusyQuery(userIDs []string, from, to time.Time) error {
+	if !to.After(from) {
+		return domain.ErrInvalidRange
+	}
+	if to.Sub(from) > maxFreeBusyRange {
+		return fmt.Errorf("%w: range longer than %d days", domain.ErrInvalidFreeBusyQuery, maxFreeBusyRange/(24*time.Hour))
+	}
+	if len(userIDs) == 0 || len(userIDs) > maxFreeBusyUsers {
+		return fmt.Errorf("%w: between 1 and %d users required", domain.ErrInvalidFreeBusyQuery, maxFreeBusyUsers)
+	}
+	for _, userID := range userIDs {
+		if userID == "" {
+			return domain.ErrInvalidUserID
+		}
+	}
+	return nil
+}
+
+// ceilTime округляет t вверх до шага step
+func ceilTime(t time.Time, step time.Duration) time.Time {
+	if truncated := t.Truncate(step); truncated.Before(t) {
+		return truncated.Add(step)
+	}
+	return t
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func TestEventService_FreeBusyAndSlots(t *testing.T) {
+	users := storage.NewMemoryUserRepository()
+	users.Save(&domain.User{ID: "user2", TimeZone: "Europe/Moscow"})
+	service := NewEventService(storage.NewMemoryRepository(), WithUserRepository(users),
+		WithInvitationRepository(storage.NewMemoryInvitationRepository()))
+
+	monday := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) time.Time {
+		return monday.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	service.CreateEvent("user1", "Standup", at(9, 0), nil, WithEnd(at(10, 0)))
+	service.CreateEvent("user1", "Review", at(9, 30), nil, WithEnd(at(11, 0)))
+	service.CreateEvent("user1", "Holiday", monday, nil, WithAllDay(true))
+	lunch, _ := service.CreateEvent("user3", "Lunch", at(12, 0), nil, WithEnd(at(13, 0)), WithAttendees([]string{"user2"}))
+	service.RespondToInvitation("user2", lunch.ID, domain.PartStatAccepted)
+
+	busy, err := service.FreeBusy([]string{"user1", "user2"}, monday, monday.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("FreeBusy failed: %v", err)
+	}
+	if len(busy["user1"]) != 1 || !busy["user1"][0].Start.Equal(at(9, 0)) || !busy["user1"][0].End.Equal(at(11, 0)) {
+		t.Errorf("Expected merged busy interval 09:00-11:00, got %+v", busy["user1"])
+	}
+	if len(busy["user2"]) != 1 || !busy["user2"][0].Start.Equal(at(12, 0)) {
+		t.Errorf("Expected accepted invitation to be busy, got %+v", busy["user2"])
+	}
+
+	// Рабочее время user2 по Москве - с 06:00 до 15:00 UTC
+	slots, err := service.FindSlots([]string{"user1", "user2"}, monday, monday.AddDate(0, 0, 1), time.Hour, 5)
+	if err != nil {
+		t.Fatalf("FindSlots failed: %v", err)
+	}
+	want := []time.Time{at(11, 0), at(13, 0), at(14, 0)}
+	if len(slots) != len(want) {
+		t.Fatalf("Expected %d slots, got %+v", len(want), slots)
+	}
+	for i, start := range want {
+		if !slots[i].Start.Equal(start) || !slots[i].End.Equal(start.Add(time.Hour)) {
+			t.Errorf("Slot %d: expected start %v, got %+v", i, start, slots[i])
+		}
+	}
+
+	// Слоты начинаются с шагом 15 минут
+	slots, _ = service.FindSlots([]string{"user1"}, at(11, 5), at(18, 0), 45*time.Minute, 2)
+	if len(slots) != 2 || !slots[0].Start.Equal(at(11, 15)) || !slots[1].Start.Equal(at(12, 0)) {
+		t.Errorf("Expected aligned slots at 11:15 and 12:00, got %+v", slots)
+	}
+
+	if _, err := service.FindSlots([]string{"user1"}, monday, monday.AddDate(0, 0, 1), 0, 5); !errors.Is(err, domain.ErrInvalidFreeBusyQuery) {
+		t.Errorf("Expected ErrInvalidFreeBusyQuery for zero duration, got %v", err)
+	}
+	if _, err := service.FreeBusy([]string{"user1"}, monday, monday.AddDate(1, 0, 0)); !errors.Is(err, domain.ErrInvalidFreeBusyQuery) {
+		t.Errorf("Expected ErrInvalidFreeBusyQuery for long range, got %v", err)
+	}
+	if _, err := service.FreeBusy(nil, monday, monday.AddDate(0, 0, 1)); !errors.Is(err, domain.ErrInvalidFreeBusyQuery) {
+		t.Errorf("Expected ErrInvalidFreeBusyQuery without users, got %v", err)
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// ImportResult - итог импорта одного события
+type ImportResult struct {
+	Event   *domain.Event
+	Created bool
+	Err     error
+}
+
+// ImportEvents создает или обновляет события из внешнего календаря.
+// Событие сопоставляется с сохраненным по UID, а если календарь был выгружен
+// из сервиса, то по ID, поэтому повторный импорт не создает дубликатов.
+// Измененные вхождения (с RecurrenceID) привязываются к серии с тем же UID;
+// замены вхождений обновленной серии, которых нет в календаре, удаляются.
+// Результаты возвращаются в порядке events; ошибка возвращается, только если
+// не удалось прочитать сохраненные события.
+func (s *EventService) ImportEvents(userID string, events []*domain.Event) ([]ImportResult, error) {
+	if userID == "" {
+		return nil, domain.ErrInvalidUserID
+	}
+	stored, err := s.ExportEvents(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	byUID := make(map[string]*domain.Event)
+	overrides := make(map[string][]*domain.Event) // ключ: ID серии
+	for _, event := range stored {
+		if event.IsOverride() {
+			overrides[event.SeriesID] = append(overrides[event.SeriesID], event)
+		} else {
+			byUID[importUID(event)] = event
+		}
+	}
+
+	// Исходные начала вхождений, замененных в календаре, по UID серии
+	recurrenceIDs := make(map[string][]time.Time)
+	for _, event := range events {
+		if event.RecurrenceID != nil {
+			recurrenceIDs[event.UID] = append(recurrenceIDs[event.UID], *event.RecurrenceID)
+		}
+	}
+
+	now := time.Now()
+	results := make([]ImportResult, len(events))
+	var imported []*domain.Event
+	for i, event := range events {
+		if event.RecurrenceID != nil {
+			continue
+		}
+		results[i] = s.importEvent(userID, event, byUID[event.UID], now)
+		if results[i].Err == nil {
+			byUID[event.UID] = results[i].Event
+			imported = append(imported, results[i].Event)
+		}
+	}
+
+	// Замены вхождений обрабатываются после серий, на которые ссылаются
+	var series []*domain.Event
+	replaced := make(map[string][]time.Time) // ключ: ID серии
+	for i, event := range events {
+		if event.RecurrenceID == nil {
+			continue
+		}
+		master := byUID[event.UID]
+		if master == nil {
+			results[i].Err = fmt.Errorf("%w: series %q", domain.ErrEventNotFound, event.UID)
+			continue
+		}
+		if !master.IsRecurring() {
+			results[i].Err = domain.ErrOccurrenceNotFound
+			continue
+		}
+		results[i] = s.importOverride(master, event, overrides[master.ID], now)
+		if results[i].Err == nil {
+			if _, ok := replaced[master.ID]; !ok {
+				series = append(series, master)
+			}
+			replaced[master.ID] = append(replaced[master.ID], *event.RecurrenceID)
+		}
+	}
+
+	for _, master := range imported {
+		if err := s.deleteStaleOverrides(overrides[master.ID], recurrenceIDs[importUID(master)]); err != nil {
+			return nil, err
+		}
+	}
+	for _, master := range series {
+		if err := s.excludeReplaced(master, replaced[master.ID], now); err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
+
+// importEvent создает событие или заменяет сохраненное событие existing
+func (s *EventService) importEvent(userID string, incoming, existing *domain.Event, now time.Time) ImportResult {
+	event := incoming.Clone()
+	event.UserID = userID
+	event.SeriesID = ""
+	event.RecurrenceID = nil
+	event.UpdatedAt = now
+	if existing != nil {
+		event.ID = existing.ID
+		event.UID = existing.UID
+		event.CalendarID = existing.CalendarID
+		event.Attendees = domain.CloneAttendees(existing.Attendees)
+		event.CreatedAt = existing.CreatedAt
+		event.Archived = existing.Archived
+	} else {
+		event.ID = generateID()
+		event.CreatedAt = now
+	}
+	return s.saveImported(event, existing == nil)
+}
+
+// importOverride создает или заменяет измененное вхождение серии master
+func (s *EventService) importOverride(master, incoming *domain.Event, stored []*domain.Event, now time.Time) ImportResult {
+	event := incoming.Clone()
+	event.UserID = master.UserID
+	event.CalendarID = master.CalendarID
+	event.Attendees = domain.CloneAttendees(master.Attendees)
+	event.UID = master.UID
+	event.SeriesID = master.ID
+	event.Recurrence = nil
+	event.ExDates = nil
+	event.UpdatedAt = now
+
+	var existing *domain.Event
+	for _, override := range stored {
+		if override.RecurrenceID.Equal(*incoming.RecurrenceID) {
+			existing = override
+			break
+		}
+	}
+	if existing != nil {
+		event.ID = existing.ID
+		event.CreatedAt = existing.CreatedAt
+		event.Archived = existing.Archived
+	} else {
+		event.ID = generateID()
+		event.CreatedAt = now
+	}
+	return s.saveImported(event, existing == nil)
+}
+
+func (s *EventService) saveImported(event *domain.Event, create bool) ImportResult {
+	s.applyUserTimeZone(event)
+	if err := event.Validate(); err != nil {
+		return ImportResult{Err: err}
+	}
+
+	var err error
+	if create {
+		err = s.createImported(event)
+	} else {
+		err = s.update(event)
+	}
+	if err != nil {
+		return ImportResult{Err: err}
+	}
+	return ImportResult{Event: event, Created: create}
+}
+
+// deleteStaleOverrides удаляет замены вхождений импортированной серии,
+// которых нет в календаре
+func (s *EventService) deleteStaleOverrides(stored []*domain.Event, recurrenceIDs []time.Time) error {
+	for _, override := range stored {
+		if containsTime(recurrenceIDs, *override.RecurrenceID) {
+			continue
+		}
+		if err := s.delete(override); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// excludeReplaced исключает из серии вхождения, замененные при импорте:
+// календари обычно не повторяют RECURRENCE-ID замен в EXDATE серии
+func (s *EventService) excludeReplaced(master *domain.Event, recurrenceIDs []time.Time, now time.Time) error {
+	exdates := master.ExDates
+	for _, recurrenceID := range recurrenceIDs {
+		if !containsTime(exdates, recurrenceID) {
+			exdates = appendExDate(exdates, recurrenceID)
+		}
+	}
+	if len(exdates) == len(master.ExDates) {
+		return nil
+	}
+	updated := *master
+	updated.ExDates = exdates
+	updated.UpdatedAt = now
+	return s.update(&updated)
+}
+
+// importUID возвращает UID, по которому событие сопоставляется при импорте
+func importUID(event *domain.Event) string {
+	if event.UID != "" {
+		return event.UID
+	}
+	return event.ID
+}
+
+func containsTime(times []time.Time, t time.Time) bool {
+	for _, v := range times {
+		if v.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func importedSeries(start time.Time) []*domain.Event {
+	rule, _ := domain.ParseRecurrenceRule("FREQ=WEEKLY;COUNT=4")
+	moved := start.AddDate(0, 0, 7)
+	return []*domain.Event{
+		// Замена вхождения идет раньше серии, как бывает в выгрузках
+		{UID: "series@ext", Text: "Moved sync", Date: moved.Add(2 * time.Hour), RecurrenceID: &moved},
+		{UID: "series@ext", Text: "Weekly sync", Date: start, End: start.Add(time.Hour), Recurrence: rule},
+		{UID: "single@ext", Text: "Dentist", Date: start.AddDate(0, 0, 1)},
+	}
+}
+
+func TestEventService_ImportEvents(t *testing.T) {
+	service := NewEventService(storage.NewMemoryRepository())
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	results, err := service.ImportEvents("user1", importedSeries(start))
+	if err != nil {
+		t.Fatalf("ImportEvents failed: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || !result.Created {
+			t.Fatalf("Item %d: expected created, got %+v", i, result)
+		}
+	}
+	if results[0].Event.SeriesID != results[1].Event.ID {
+		t.Errorf("Override should reference the imported series")
+	}
+
+	events, _ := service.GetEvents("user1", start, start.AddDate(0, 1, 0))
+	var texts []string
+	for _, e := range events {
+		texts = append(texts, e.Text)
+	}
+	want := []string{"Weekly sync", "Dentist", "Moved sync", "Weekly sync", "Weekly sync"}
+	if len(texts) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, texts)
+	}
+	for i := range want {
+		if texts[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, texts)
+		}
+	}
+
+	// Повторный импорт обновляет события, а не создает новые
+	again := importedSeries(start)
+	again[2].Text = "Dentist (rescheduled)"
+	results, err = service.ImportEvents("user1", again)
+	if err != nil {
+		t.Fatalf("ImportEvents failed: %v", err)
+	}
+	for i, result := range results {
+		if result.Err != nil || result.Created {
+			t.Fatalf("Item %d: expected updated, got %+v", i, result)
+		}
+	}
+	all, _ := service.ExportEvents("user1")
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 stored events after re-import, got %d", len(all))
+	}
+	if dentist, _ := service.GetEvent("user1", results[2].Event.ID); dentist.Text != "Dentist (rescheduled)" {
+		t.Errorf("Expected updated text, got %q", dentist.Text)
+	}
+
+	// Замена, которой больше нет в календаре, удаляется вместе с исключением
+	results, _ = service.ImportEvents("user1", again[1:])
+	all, _ = service.ExportEvents("user1")
+	if len(all) != 2 {
+		t.Errorf("Expected stale override to be deleted, got %d events", len(all))
+	}
+	if series := results[0].Event; len(series.ExDates) != 0 {
+		t.Errorf("Expected series without exdates, got %v", series.ExDates)
+	}
+}
+
+func TestEventService_ImportEvents_Errors(t *testing.T) {
+	service := NewEventService(storage.NewMemoryRepository())
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	existing, _ := service.CreateEvent("user1", "Exported", start, nil)
+
+	orphan := start.AddDate(0, 0, 7)
+	results, err := service.ImportEvents("user1", []*domain.Event{
+		{UID: "empty@ext", Date: start},
+		{UID: "orphan@ext", Text: "Orphan", Date: orphan, RecurrenceID: &orphan},
+		{UID: existing.ID, Text: "Exported and edited", Date: start},
+	})
+	if err != nil {
+		t.Fatalf("ImportEvents failed: %v", err)
+	}
+	if !errors.Is(results[0].Err, domain.ErrInvalidEventText) {
+		t.Errorf("Expected ErrInvalidEventText, got %v", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, domain.ErrEventNotFound) {
+		t.Errorf("Expected ErrEventNotFound for override without series, got %v", results[1].Err)
+	}
+	// Событие, выгруженное из сервиса, сопоставляется по ID
+	if results[2].Err != nil || results[2].Created || results[2].Event.ID != existing.ID {
+		t.Errorf("Expected update of the exported event, got %+v", results[2])
+	}
+
+	if _, err := service.ImportEvents("", nil); !errors.Is(err, domain.ErrInvalidUserID) {
+		t.Errorf("Expected ErrInvalidUserID, got %v", err)
+	}
+}
+
+func TestEventService_ImportEvents_SkipsPastReminders(t *testing.T) {
+	scheduler := recordingScheduler{}
+	service := NewEventService(storage.NewMemoryRepository(), WithReminderScheduler(scheduler))
+
+	past := time.Now().AddDate(-1, 0, 0).Truncate(time.Minute)
+	future := time.Now().AddDate(0, 0, 1).Truncate(time.Minute)
+	reminders := []domain.Reminder{{Offset: 15 * time.Minute}}
+	results, err := service.ImportEvents("user1", []*domain.Event{
+		{UID: "past@ext", Text: "Old meeting", Date: past, Reminders: reminders},
+		{UID: "future@ext", Text: "Planning", Date: future, Reminders: reminders},
+	})
+	if err != nil {
+		t.Fatalf("ImportEvents failed: %v", err)
+	}
+
+	if times := scheduler[results[0].Event.ID]; len(times) != 0 {
+		t.Errorf("Expected no reminders for past event, got %v", times)
+	}
+	if times := scheduler[results[1].Event.ID]; len(times) != 1 || !times[0].Equal(future.Add(-15*time.Minute)) {
+		t.Errorf("Expected reminder before future event, got %v", times)
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// RespondToInvitation сохраняет ответ участника на приглашение. Ответ на серию
+// относится ко всем ее вхождениям, включая измененные.
+func (s *EventService) RespondToInvitation(userID, eventID string, status domain.PartStat) (*domain.Event, error) {
+	if _, err := domain.ParsePartStat(string(status)); err != nil {
+		return nil, err
+	}
+	return s.respond(userID, eventID, true, func(attendee *domain.Attendee) {
+		attendee.Status = status
+	})
+}
+
+// ProposeTime предлагает владельцу события другое время. Ответ участника
+// не меняется, а предложение удаляется, когда владелец переносит событие.
+// Нулевой end означает прежнюю длительность.
+func (s *EventService) ProposeTime(userID, eventID string, start, end time.Time) (*domain.Event, error) {
+	return s.respond(userID, eventID, false, func(attendee *domain.Attendee) {
+		attendee.Proposal = &domain.TimeProposal{Start: start, End: end}
+	})
+}
+
+// Invitations возвращает события других пользователей, на которые приглашен
+// пользователь, включая отклоненные, в порядке начала. Серии не разворачиваются.
+func (s *EventService) Invitations(userID string) ([]*domain.Event, error) {
+	if s.invites == nil {
+		return nil, nil
+	}
+	invitations, err := s.invites.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	var result []*domain.Event
+	for _, invitation := range invitations {
+		event, err := s.repo.GetByID(invitation.OwnerID, invitation.EventID)
+		if errors.Is(err, domain.ErrEventNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !event.Archived && event.Attendee(userID) != nil {
+			result = append(result, event)
+		}
+	}
+	sortByStart(result)
+	return result, nil
+}
+
+// respond изменяет участника userID в событии и, если withOverrides,
+// в измененных вхождениях серии
+func (s *EventService) respond(userID, eventID string, withOverrides bool, change func(*domain.Attendee)) (*domain.Event, error) {
+	event, err := s.invitedEvent(userID, eventID)
+	if errors.Is(err, domain.ErrEventNotFound) {
+		// Событие может быть видно пользователю, который на него не приглашен
+		if _, findErr := s.GetEvent(userID, eventID); findErr == nil {
+			return nil, domain.ErrNotAttendee
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	events := []*domain.Event{event}
+	if withOverrides && event.IsRecurring() {
+		overrides, err := s.overrides(event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, overrides...)
+	}
+
+	now := time.Now()
+	var changed []*domain.Event
+	for _, e := range events {
+		attendee := e.Attendee(userID)
+		if attendee == nil {
+			continue
+		}
+		change(attendee)
+		respondedAt := now
+		attendee.RespondedAt = &respondedAt
+		e.UpdatedAt = now
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		changed = append(changed, e)
+	}
+	for _, e := range changed {
+		if err := s.update(e); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// invitedEvent ищет событие другого пользователя, на которое приглашен пользователь
+func (s *EventService) invitedEvent(userID, eventID string) (*domain.Event, error) {
+	if s.invites == nil {
+		return nil, domain.ErrEventNotFound
+	}
+	invitations, err := s.invites.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, invitation := range invitations {
+		if invitation.EventID != eventID {
+			continue
+		}
+		event, err := s.repo.GetByID(invitation.OwnerID, eventID)
+		if errors.Is(err, domain.ErrEventNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if event.Attendee(userID) != nil {
+			return event, nil
+		}
+	}
+	return nil, domain.ErrEventNotFound
+}
+
+// invitedEventsInRange возвращает события и вхождения серий в интервале [start, end),
+// на которые приглашен пользователь и от которых он не отказался. Календари
+// владельцев из owners пропускаются: их события уже входят в выборку.
+func (s *EventService) invitedEventsInRange(userID string, owners []string, start, end time.Time) ([]*domain.Event, error) {
+	if s.invites == nil {
+		return nil, nil
+	}
+	invitations, err := s.invites.ListByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	visited := make(map[string]bool, len(owners))
+	for _, ownerID := range owners {
+		visited[ownerID] = true
+	}
+	var result []*domain.Event
+	for _, invitation := range invitations {
+		if visited[invitation.OwnerID] {
+			continue
+		}
+		visited[invitation.OwnerID] = true
+
+		events, err := s.calendarEventsInRange(invitation.OwnerID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			if event.IsInvited(userID) {
+				result = append(result, event)
+			}
+		}
+	}
+	return result, nil
+}
+
+// syncInvitations приводит приглашения в соответствие с участниками события
+func (s *EventService) syncInvitations(event *domain.Event) error {
+	if s.invites == nil {
+		return nil
+	}
+	return s.invites.SetAttendees(event.UserID, event.ID, event.AttendeeIDs())
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func newInvitationService() *EventService {
+	return NewEventService(storage.NewMemoryRepository(), WithInvitationRepository(storage.NewMemoryInvitationRepository()))
+}
+
+func TestEventService_InvitationsInViews(t *testing.T) {
+	service := newInvitationService()
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	meeting, err := service.CreateEvent("user1", "Planning", day.Add(10*time.Hour), nil, WithAttendees([]string{"user2", "user3"}))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if len(meeting.Attendees) != 2 || meeting.Attendees[0].Status != domain.PartStatNeedsAction {
+		t.Fatalf("Expected attendees awaiting response, got %+v", meeting.Attendees)
+	}
+
+	events, err := service.GetEventsForDay("user2", day)
+	if err != nil {
+		t.Fatalf("GetEventsForDay failed: %v", err)
+	}
+	if len(events) != 1 || events[0].ID != meeting.ID || events[0].UserID != "user1" {
+		t.Fatalf("Expected invitation in the day view, got %+v", events)
+	}
+	if _, err := service.GetEvent("user2", meeting.ID); err != nil {
+		t.Errorf("Expected attendee to read the event, got %v", err)
+	}
+	if _, err := service.UpdateEvent("user2", meeting.ID, "Hijacked", meeting.Date, nil); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied for attendee, got %v", err)
+	}
+
+	if _, err := service.RespondToInvitation("user2", meeting.ID, domain.PartStatDeclined); err != nil {
+		t.Fatalf("RespondToInvitation failed: %v", err)
+	}
+	if events, _ := service.GetEventsForDay("user2", day); len(events) != 0 {
+		t.Errorf("Expected declined invitation to be hidden, got %+v", events)
+	}
+	invitations, err := service.Invitations("user2")
+	if err != nil {
+		t.Fatalf("Invitations failed: %v", err)
+	}
+	if len(invitations) != 1 || invitations[0].Attendee("user2").Status != domain.PartStatDeclined {
+		t.Errorf("Expected declined invitation to be listed, got %+v", invitations)
+	}
+
+	// Удаленный из списка участник теряет доступ к событию
+	if _, err := service.UpdateEvent("user1", meeting.ID, meeting.Text, meeting.Date, nil, WithAttendees([]string{"user3"})); err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	if _, err := service.GetEvent("user2", meeting.ID); !errors.Is(err, domain.ErrEventNotFound) {
+		t.Errorf("Expected ErrEventNotFound for removed attendee, got %v", err)
+	}
+	if _, err := service.RespondToInvitation("user4", meeting.ID, domain.PartStatAccepted); !errors.Is(err, domain.ErrEventNotFound) {
+		t.Errorf("Expected ErrEventNotFound for stranger, got %v", err)
+	}
+}
+
+func TestEventService_RespondToInvitation(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	scheduler := recordingScheduler{}
+	service := NewEventService(repo, WithReminderScheduler(scheduler),
+		WithInvitationRepository(storage.NewMemoryInvitationRepository()))
+
+	date := time.Now().Add(48 * time.Hour).Truncate(time.Minute)
+	reminder := date.Add(-time.Hour)
+	meeting, err := service.CreateEvent("user1", "Review", date, &reminder, WithAttendees([]string{"user2"}))
+	if err != nil {
+		t.Fatalf("CreateEvent failed: %v", err)
+	}
+	if len(scheduler[meeting.ID]) != 1 {
+		t.Fatalf("Expected reminder only for the owner, got %v", scheduler[meeting.ID])
+	}
+
+	if _, err := service.RespondToInvitation("user2", meeting.ID, domain.PartStatAccepted); err != nil {
+		t.Fatalf("RespondToInvitation failed: %v", err)
+	}
+	tasks, _ := service.PendingReminders(time.Now())
+	if len(tasks) != 2 || tasks[1].UserID != "user2" || tasks[1].EventOwner() != "user1" {
+		t.Errorf("Expected reminders for owner and accepted attendee, got %+v", tasks)
+	}
+
+	start := date.Add(2 * time.Hour)
+	updated, err := service.ProposeTime("user2", meeting.ID, start, time.Time{})
+	if err != nil {
+		t.Fatalf("ProposeTime failed: %v", err)
+	}
+	attendee := updated.Attendee("user2")
+	if attendee.Status != domain.PartStatAccepted || attendee.Proposal == nil || !attendee.Proposal.Start.Equal(start) {
+		t.Errorf("Expected proposal to keep the response, got %+v", attendee)
+	}
+	if _, err := service.ProposeTime("user2", meeting.ID, start, start.Add(-time.Hour)); !errors.Is(err, domain.ErrInvalidProposal) {
+		t.Errorf("Expected ErrInvalidProposal, got %v", err)
+	}
+
+	// Перенос события владельцем снимает предложения, но сохраняет ответы
+	rescheduled, err := service.UpdateEvent("user1", meeting.ID, meeting.Text, start, nil)
+	if err != nil {
+		t.Fatalf("UpdateEvent failed: %v", err)
+	}
+	attendee = rescheduled.Attendee("user2")
+	if attendee.Proposal != nil || attendee.Status != domain.PartStatAccepted {
+		t.Errorf("Expected proposal to be cleared, got %+v", attendee)
+	}
+
+	if _, err := service.RespondToInvitation("user1", meeting.ID, domain.PartStatAccepted); !errors.Is(err, domain.ErrNotAttendee) {
+		t.Errorf("Expected ErrNotAttendee for the owner, got %v", err)
+	}
+	if _, err := service.CreateEvent("user1", "Self", date, nil, WithAttendees([]string{"user1"})); !errors.Is(err, domain.ErrInvalidAttendee) {
+		t.Errorf("Expected ErrInvalidAttendee for the owner as attendee, got %v", err)
+	}
+}
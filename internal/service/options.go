@@ -0,0 +1,199 @@
+package service
+
+import (
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// EventOption задает дополнительные параметры события при создании и обновлении.
+// При обновлении поля, не указанные через опции, сохраняют прежние значения.
+type EventOption func(*eventOptions)
+
+type eventOptions struct {
+	recurrence    *domain.RecurrenceRule
+	recurrenceSet bool
+
+	end       time.Time
+	endSet    bool
+	allDay    bool
+	allDaySet bool
+
+	timeZone    string
+	timeZoneSet bool
+
+	reminders    []domain.Reminder
+	remindersSet bool
+
+	scope      domain.EditScope
+	occurrence time.Time
+
+	owner string
+
+	calendarID  string
+	calendarSet bool
+
+	attendees    []string
+	attendeesSet bool
+
+	conflictMode domain.ConflictMode
+}
+
+// WithRecurrence задает правило повторения события (nil делает событие однократным)
+func WithRecurrence(rule *domain.RecurrenceRule) EventOption {
+	return func(o *eventOptions) {
+		o.recurrence = rule
+		o.recurrenceSet = true
+	}
+}
+
+// WithEnd задает окончание события (нулевое значение убирает длительность)
+func WithEnd(end time.Time) EventOption {
+	return func(o *eventOptions) {
+		o.end = end
+		o.endSet = true
+	}
+}
+
+// WithAllDay помечает событие как событие на весь день
+func WithAllDay(allDay bool) EventOption {
+	return func(o *eventOptions) {
+		o.allDay = allDay
+		o.allDaySet = true
+	}
+}
+
+// WithTimeZone задает часовой пояс события (IANA)
+func WithTimeZone(tz string) EventOption {
+	return func(o *eventOptions) {
+		o.timeZone = tz
+		o.timeZoneSet = true
+	}
+}
+
+// WithReminders задает список напоминаний события (nil удаляет их)
+func WithReminders(reminders []domain.Reminder) EventOption {
+	return func(o *eventOptions) {
+		o.reminders = reminders
+		o.remindersSet = true
+	}
+}
+
+// WithScope задает область изменения или удаления повторяющегося события.
+// occurrence — исходное начало вхождения, относительно которого применяется область.
+func WithScope(scope domain.EditScope, occurrence time.Time) EventOption {
+	return func(o *eventOptions) {
+		o.scope = scope
+		o.occurrence = occurrence
+	}
+}
+
+// WithOwner создает событие в календаре другого пользователя.
+// Календарь должен быть открыт создающему пользователю на запись.
+func WithOwner(ownerID string) EventOption {
+	return func(o *eventOptions) {
+		o.owner = ownerID
+	}
+}
+
+// WithCalendar помещает событие в календарь владельца события.
+// domain.DefaultCalendarID или пустая строка означают календарь по умолчанию.
+func WithCalendar(calendarID string) EventOption {
+	return func(o *eventOptions) {
+		if calendarID == domain.DefaultCalendarID {
+			calendarID = ""
+		}
+		o.calendarID = calendarID
+		o.calendarSet = true
+	}
+}
+
+// WithAttendees задает пользователей, приглашенных на событие (nil удаляет участников).
+// Участники, уже приглашенные раньше, сохраняют свои ответы.
+func WithAttendees(userIDs []string) EventOption {
+	return func(o *eventOptions) {
+		o.attendees = userIDs
+		o.attendeesSet = true
+	}
+}
+
+// WithConflictMode задает проверку пересечений с другими событиями владельца.
+// С domain.ConflictReject событие, пересекающееся с другими, не сохраняется
+// и возвращается domain.ConflictError; в остальных режимах событие
+// сохраняется, а пересечения можно получить через Conflicts.
+func WithConflictMode(mode domain.ConflictMode) EventOption {
+	return func(o *eventOptions) {
+		o.conflictMode = mode
+	}
+}
+
+func newEventOptions(opts []EventOption) *eventOptions {
+	o := &eventOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// apply переносит заданные опции в событие
+func (o *eventOptions) apply(event *domain.Event) {
+	if o.recurrenceSet {
+		event.Recurrence = o.recurrence
+	}
+	if o.allDaySet {
+		event.AllDay = o.allDay
+	}
+	if o.endSet {
+		event.End = o.end
+	}
+	if o.timeZoneSet {
+		event.TimeZone = o.timeZone
+	}
+	if o.remindersSet {
+		event.Reminders = o.reminders
+	}
+	if o.calendarSet {
+		event.CalendarID = o.calendarID
+	}
+	if o.attendeesSet {
+		event.Attendees = mergeAttendees(event.Attendees, o.attendees)
+	}
+}
+
+// mergeAttendees возвращает участников userIDs, сохраняя ответы уже приглашенных
+func mergeAttendees(current []domain.Attendee, userIDs []string) []domain.Attendee {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	result := make([]domain.Attendee, 0, len(userIDs))
+	for _, userID := range userIDs {
+		attendee := domain.Attendee{UserID: userID, Status: domain.PartStatNeedsAction}
+		for _, existing := range current {
+			if existing.UserID == userID {
+				attendee = existing
+				break
+			}
+		}
+		result = append(result, attendee)
+	}
+	return result
+}
+
+// reschedule переносит событие на новое начало с сохранением длительности и применяет опции.
+// При переключении между событием на весь день и событием со временем без явного
+// окончания длительность сбрасывается, так как прежняя теряет смысл.
+// Предложения участников о другом времени после переноса события теряют смысл и удаляются.
+func (o *eventOptions) reschedule(event *domain.Event, date time.Time) {
+	start, end := event.Date, event.EndTime()
+	if !event.End.IsZero() {
+		event.End = event.EndFor(date)
+	}
+	event.Date = date
+	if o.allDaySet && o.allDay != event.AllDay && !o.endSet {
+		event.End = time.Time{}
+	}
+	o.apply(event)
+	if !event.Date.Equal(start) || !event.EndTime().Equal(end) {
+		event.ClearProposals()
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// reminderHorizon ограничивает поиск ближайшего вхождения серии для напоминаний
+const reminderHorizon = 366 * 24 * time.Hour
+
+// create сохраняет новое событие, приглашает его участников и планирует напоминания.
+// Уже наступившие напоминания нового события отправляются сразу.
+func (s *EventService) create(event *domain.Event) error {
+	return s.insert(event, true)
+}
+
+// createImported сохраняет импортированное событие. Планируются только будущие
+// напоминания: наступившие напоминания старых событий календаря давно не нужны.
+func (s *EventService) createImported(event *domain.Event) error {
+	return s.insert(event, false)
+}
+
+func (s *EventService) insert(event *domain.Event, sendDue bool) error {
+	if err := s.repo.Create(event); err != nil {
+		return err
+	}
+	if err := s.syncInvitations(event); err != nil {
+		return err
+	}
+	if s.reminders != nil {
+		if sendDue {
+			for _, task := range reminderTasks(event, time.Now()) {
+				s.reminders.Schedule(task)
+			}
+		} else {
+			s.scheduleFuture(event, time.Now())
+		}
+	}
+	s.notify(domain.ChangeCreated, event)
+	return nil
+}
+
+// update сохраняет событие, обновляет приглашения и заменяет запланированные
+// напоминания. Наступившие напоминания повторно не планируются.
+func (s *EventService) update(event *domain.Event) error {
+	if err := s.repo.Update(event); err != nil {
+		return err
+	}
+	if err := s.syncInvitations(event); err != nil {
+		return err
+	}
+	if s.reminders != nil {
+		s.reminders.Cancel(event.ID)
+		s.scheduleFuture(event, time.Now())
+	}
+	s.notify(domain.ChangeUpdated, event)
+	return nil
+}
+
+// delete удаляет событие, его приглашения и отменяет напоминания
+func (s *EventService) delete(event *domain.Event) error {
+	if err := s.repo.Delete(event.UserID, event.ID); err != nil {
+		return err
+	}
+	if s.invites != nil {
+		if err := s.invites.SetAttendees(event.UserID, event.ID, nil); err != nil {
+			return err
+		}
+	}
+	if s.reminders != nil {
+		s.reminders.Cancel(event.ID)
+	}
+	s.notify(domain.ChangeDeleted, event)
+	return nil
+}
+
+// notify сообщает об изменении события каждому, кто его видит (см. audience).
+// Получатели получают копию события, чтобы дальнейшие изменения вызывающим
+// кодом их не затронули. Для удаленного события копия не передается.
+func (s *EventService) notify(kind domain.ChangeType, event *domain.Event) {
+	if len(s.notifiers) == 0 {
+		return
+	}
+	var snapshot *domain.Event
+	if kind != domain.ChangeDeleted {
+		snapshot = event.Clone()
+	}
+	now := time.Now()
+	for _, userID := range s.audience(event) {
+		change := &domain.Change{
+			Type:    kind,
+			UserID:  userID,
+			EventID: event.ID,
+			Event:   snapshot,
+			At:      now,
+		}
+		for _, notifier := range s.notifiers {
+			notifier.Notify(change)
+		}
+	}
+}
+
+// audience возвращает пользователей, которые видят событие: владельца, пользователей
+// с доступом к его календарю и приглашенных, не отказавшихся от участия
+func (s *EventService) audience(event *domain.Event) []string {
+	users := []string{event.UserID}
+	if s.shares != nil {
+		// Без списка доступа изменение все равно получат владелец и участники
+		if shares, err := s.shares.ListByOwner(event.UserID); err == nil {
+			for _, share := range shares {
+				users = append(users, share.GranteeID)
+			}
+		}
+	}
+	for _, attendee := range event.Attendees {
+		if attendee.Status != domain.PartStatDeclined && !slices.Contains(users, attendee.UserID) {
+			users = append(users, attendee.UserID)
+		}
+	}
+	return users
+}
+
+func (s *EventService) scheduleFuture(event *domain.Event, now time.Time) {
+	for _, task := range reminderTasks(event, now) {
+		if task.Time.After(now) {
+			s.reminders.Schedule(task)
+		}
+	}
+}
+
+// PendingReminders возвращает напоминания, которые еще не наступили к моменту now.
+// Используется для восстановления очереди напоминаний при запуске.
+func (s *EventService) PendingReminders(now time.Time) ([]*domain.ReminderTask, error) {
+	events, err := s.repo.GetWithReminders()
+	if err != nil {
+		return nil, err
+	}
+
+	var tasks []*domain.ReminderTask
+	for _, event := range events {
+		for _, task := range reminderTasks(event, now) {
+			if task.Time.After(now) {
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+// NextReminder возвращает то же напоминание для следующего вхождения серии
+// после отправки task или nil, если событие не повторяется или удалено
+func (s *EventService) NextReminder(task *domain.ReminderTask) *domain.ReminderTask {
+	event, err := s.repo.GetByID(task.EventOwner(), task.EventID)
+	if err != nil || event.Archived || !event.IsRecurring() {
+		return nil
+	}
+
+	// Следующее вхождение ищется по времени отправленного напоминания
+	for _, next := range reminderTasks(event, task.Time) {
+		if next.Index == task.Index && next.UserID == task.UserID {
+			return next
+		}
+	}
+	return nil
+}
+
+// reminderTasks возвращает задачи для всех напоминаний события каждому получателю:
+// владельцу и принявшим приглашение участникам. Для серии каждое напоминание
+// берется от ближайшего вхождения, для которого оно еще не наступило к now.
+func reminderTasks(event *domain.Event, now time.Time) []*domain.ReminderTask {
+	targets := make([]*domain.Event, len(event.ReminderTimes()))
+	times := make([]time.Time, len(targets))
+	if !event.IsRecurring() {
+		copy(times, event.ReminderTimes())
+		for i := range targets {
+			targets[i] = event
+		}
+	} else {
+		found := 0
+		for _, occurrence := range event.Occurrences(now, now.Add(reminderHorizon)) {
+			if occurrence.Date.Before(now) {
+				continue
+			}
+			for i, t := range occurrence.ReminderTimes() {
+				// Измененное вхождение может иметь другой набор напоминаний
+				if i >= len(targets) {
+					break
+				}
+				if targets[i] == nil && t.After(now) {
+					targets[i], times[i] = occurrence, t
+					found++
+				}
+			}
+			if found == len(targets) {
+				break
+			}
+		}
+	}
+
+	recipients := event.ReminderRecipients()
+	tasks := make([]*domain.ReminderTask, 0, len(times)*len(recipients))
+	for _, recipient := range recipients {
+		for i, target := range targets {
+			if target == nil {
+				continue
+			}
+			task := &domain.ReminderTask{
+				EventID: event.ID,
+				UserID:  recipient,
+				Text:    event.Text,
+				Time:    times[i],
+				Start:   target.Date,
+				Index:   i,
+			}
+			if recipient != event.UserID {
+				task.OwnerID = event.UserID
+			}
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+)
+
+// EventSearch задает параметры поиска событий
+type EventSearch struct {
+	From, To  time.Time            // интервал [From, To)
+	Text      string               // подстрока текста события без учета регистра
+	Calendars []string             // календари событий; пустой - все календари
+	Archive   domain.ArchiveFilter // по умолчанию только активные события
+	Sort      domain.SortOrder     // по умолчанию по началу по возрастанию
+	Cursor    string               // курсор из предыдущей страницы
+	Limit     int                  // размер страницы, по умолчанию 50, не больше 500
+}
+
+// EventPage содержит страницу результатов поиска
+type EventPage struct {
+	Events []*domain.Event
+	// NextCursor передается в следующий запрос; пустой на последней странице
+	NextCursor string
+}
+
+// searchCursor указывает на последнее событие страницы. Курсор сравнивается
+// по значению сортировки, а не по номеру позиции, поэтому страницы не сдвигаются
+// при добавлении и удалении событий между запросами.
+type searchCursor struct {
+	Sort  string `json:"s"`
+	Key   int64  `json:"k"`
+	Start int64  `json:"t"`
+	ID    string `json:"i"`
+}
+
+// SearchEvents ищет события и вхождения серий в интервале с фильтрами
+// по тексту и архивации и возвращает одну страницу результатов
+func (s *EventService) SearchEvents(userID string, q EventSearch) (*EventPage, error) {
+	if !q.To.After(q.From) {
+		return nil, domain.ErrInvalidRange
+	}
+	if q.Archive == "" {
+		q.Archive = domain.ArchiveActive
+	}
+	if q.Sort.Field == "" {
+		q.Sort.Field = domain.SortByStart
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	var after *searchCursor
+	if q.Cursor != "" {
+		cursor, err := decodeCursor(q.Cursor)
+		if err != nil || cursor.Sort != q.Sort.String() {
+			return nil, domain.ErrInvalidCursor
+		}
+		after = cursor
+	}
+
+	events, err := s.searchCandidates(userID, q.From, q.To, q.Archive, q.Calendars)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.ToLower(strings.TrimSpace(q.Text))
+	calendars := newCalendarFilter(q.Calendars)
+	matched := events[:0]
+	for _, event := range events {
+		if !calendars.matches(event) {
+			continue
+		}
+		if text == "" || strings.Contains(strings.ToLower(event.Text), text) {
+			matched = append(matched, event)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return compareCursors(cursorFor(matched[i], q.Sort), cursorFor(matched[j], q.Sort), q.Sort.Desc) < 0
+	})
+
+	if after != nil {
+		start := sort.Search(len(matched), func(i int) bool {
+			return compareCursors(cursorFor(matched[i], q.Sort), after, q.Sort.Desc) > 0
+		})
+		matched = matched[start:]
+	}
+
+	page := &EventPage{Events: matched}
+	if len(matched) > limit {
+		page.Events = matched[:limit]
+		page.NextCursor = encodeCursor(cursorFor(page.Events[limit-1], q.Sort))
+	}
+	return page, nil
+}
+
+// SearchText ищет события пользователя и открытых ему календарей по словам текста с учетом форм слов
+// и префиксов и возвращает не больше limit самых релевантных.
+// В отличие от SearchEvents поиск не ограничен интервалом дат.
+func (s *EventService) SearchText(userID, query string, archive domain.ArchiveFilter, limit int) ([]domain.TextMatch, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, domain.ErrInvalidQuery
+	}
+	if archive == "" {
+		archive = domain.ArchiveAll
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	owners, err := s.readableOwners(userID)
+	if err != nil {
+		return nil, err
+	}
+	var matches []domain.TextMatch
+	for _, ownerID := range owners {
+		found, err := s.repo.SearchText(ownerID, query)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, found...)
+	}
+	if len(owners) > 1 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Score > matches[j].Score
+		})
+	}
+
+	result := make([]domain.TextMatch, 0, limit)
+	for _, match := range matches {
+		if match.Event.Archived && !archive.IncludesArchived() || !match.Event.Archived && !archive.IncludesActive() {
+			continue
+		}
+		result = append(result, match)
+		if len(result) == limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// searchCandidates возвращает события и вхождения серий в интервале [from, to)
+// из календаря пользователя и открытых ему календарей с учетом фильтра архивации.
+// Без фильтра по календарям в выборку входят и активные приглашения.
+func (s *EventService) searchCandidates(userID string, from, to time.Time, archive domain.ArchiveFilter, calendarIDs []string) ([]*domain.Event, error) {
+	var result []*domain.Event
+	if archive.IncludesActive() {
+		active, err := s.eventsInRange(userID, from, to, calendarIDs)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, active...)
+	}
+
+	if archive.IncludesArchived() {
+		owners, err := s.readableOwners(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ownerID := range owners {
+			archived, err := s.repo.GetArchived(ownerID)
+			if err != nil {
+				return nil, err
+			}
+			for _, event := range archived {
+				if event.IsRecurring() {
+					result = append(result, event.Occurrences(from, to)...)
+				} else if event.Overlaps(from, to) {
+					result = append(result, event)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// cursorFor возвращает позицию события в порядке сортировки order
+func cursorFor(event *domain.Event, order domain.SortOrder) *searchCursor {
+	c := &searchCursor{
+		Sort:  order.String(),
+		Start: event.Date.UnixNano(),
+		ID:    event.ID,
+	}
+	switch order.Field {
+	case domain.SortByCreated:
+		c.Key = event.CreatedAt.UnixNano()
+	case domain.SortByUpdated:
+		c.Key = event.UpdatedAt.UnixNano()
+	default:
+		c.Key = c.Start
+	}
+	return c
+}
+
+// compareCursors сравнивает позиции по значению сортировки, затем по началу
+// и ID, чтобы порядок был однозначным и для вхождений одной серии
+func compareCursors(a, b *searchCursor, desc bool) int {
+	result := compareInt64(a.Key, b.Key)
+	if result == 0 {
+		result = compareInt64(a.Start, b.Start)
+	}
+	if result == 0 {
+		result = strings.Compare(a.ID, b.ID)
+	}
+	if desc {
+		return -result
+	}
+	return result
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func encodeCursor(c *searchCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*searchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func TestEventService_SearchEvents_Pages(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		if _, err := service.CreateEvent("user1", "Standup", start.AddDate(0, 0, i), nil); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+	rule, _ := domain.ParseRecurrenceRule("FREQ=DAILY;COUNT=3")
+	if _, err := service.CreateEvent("user1", "Gym", start.Add(time.Hour), nil, WithRecurrence(rule)); err != nil {
+		t.Fatalf("Failed to create series: %v", err)
+	}
+
+	q := EventSearch{
+		From:  start,
+		To:    start.AddDate(0, 1, 0),
+		Limit: 3,
+	}
+	var seen []*domain.Event
+	for page := 0; ; page++ {
+		if page > 5 {
+			t.Fatal("Cursor did not reach the last page")
+		}
+		result, err := service.SearchEvents("user1", q)
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		seen = append(seen, result.Events...)
+		if result.NextCursor == "" {
+			break
+		}
+		q.Cursor = result.NextCursor
+	}
+
+	if len(seen) != 8 {
+		t.Fatalf("Expected 8 events across pages, got %d", len(seen))
+	}
+	for i := 1; i < len(seen); i++ {
+		if seen[i].Date.Before(seen[i-1].Date) {
+			t.Errorf("Events out of order at %d: %v before %v", i, seen[i].Date, seen[i-1].Date)
+		}
+	}
+}
+
+func TestEventService_SearchEvents_Filters(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	service := NewEventService(repo)
+
+	day := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	service.CreateEvent("user1", "Dentist appointment", day, nil)
+	service.CreateEvent("user1", "Встреча с командой", day.AddDate(0, 0, 1), nil)
+	old, _ := service.CreateEvent("user1", "Old dentist visit", day.AddDate(0, 0, 2), nil)
+	archived := *old
+	archived.Archived = true
+	if err := repo.Update(&archived); err != nil {
+		t.Fatalf("Failed to archive event: %v", err)
+	}
+
+	from, to := day, day.AddDate(0, 0, 7)
+
+	result, err := service.SearchEvents("user1", EventSearch{From: from, To: to, Text: "DENTIST"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if len(result.Events) != 1 || result.Events[0].Text != "Dentist appointment" {
+		t.Errorf("Expected only the active dentist event, got %d events", len(result.Events))
+	}
+
+	result, _ = service.SearchEvents("user1", EventSearch{From: from, To: to, Text: "встреча"})
+	if len(result.Events) != 1 {
+		t.Errorf("Expected case-insensitive match for Cyrillic text, got %d events", len(result.Events))
+	}
+
+	result, _ = service.SearchEvents("user1", EventSearch{From: from, To: to, Archive: domain.ArchiveArchived})
+	if len(result.Events) != 1 || result.Events[0].ID != old.ID {
+		t.Errorf("Expected only the archived event, got %d events", len(result.Events))
+	}
+
+	result, _ = service.SearchEvents("user1", EventSearch{
+		From:    from,
+		To:      to,
+		Archive: domain.ArchiveAll,
+		Sort:    domain.SortOrder{Field: domain.SortByStart, Desc: true},
+	})
+	if len(result.Events) != 3 || result.Events[0].ID != old.ID {
+		t.Errorf("Expected all events, latest first, got %d events", len(result.Events))
+	}
+}
+
+func TestEventService_SearchEvents_InvalidCursor(t *testing.T) {
+	service := NewEventService(storage.NewMemoryRepository())
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, day := range []int{1, 2} {
+		service.CreateEvent("user1", "Event", from.AddDate(0, 0, day), nil)
+	}
+	result, _ := service.SearchEvents("user1", EventSearch{From: from, To: from.AddDate(0, 1, 0), Limit: 1})
+
+	tests := []struct {
+		name string
+		q    EventSearch
+	}{
+		{"garbage", EventSearch{From: from, To: from.AddDate(0, 1, 0), Cursor: "not-a-cursor"}},
+		{"other sort", EventSearch{
+			From:   from,
+			To:     from.AddDate(0, 1, 0),
+			Sort:   domain.SortOrder{Field: domain.SortByCreated},
+			Cursor: result.NextCursor,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.SearchEvents("user1", tt.q); !errors.Is(err, domain.ErrInvalidCursor) {
+				t.Errorf("Expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+
+	if _, err := service.SearchEvents("user1", EventSearch{From: from, To: from}); !errors.Is(err, domain.ErrInvalidRange) {
+		t.Errorf("Expected ErrInvalidRange, got %v", err)
+	}
+}
@@ -0,0 +1,448 @@
+package service
+
+import (
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// updateSeries обновляет повторяющееся событие или его вхождение с учетом области изменения.
+// Для серии область по умолчанию — вся серия, для измененного вхождения — только оно.
+func (s *EventService) updateSeries(event *domain.Event, text string, date time.Time, reminderTime *time.Time, o *eventOptions) (*domain.Event, error) {
+	master, occurrence, err := s.resolveSeries(event, o)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.scope {
+	case domain.ScopeThis:
+		return s.updateOccurrence(master, occurrence, text, date, reminderTime, o)
+	case domain.ScopeFollowing:
+		if occurrence.Equal(master.Date) {
+			return s.updateWholeSeries(master, occurrence, text, date, reminderTime, o)
+		}
+		return s.splitSeries(master, occurrence, text, date, reminderTime, o)
+	default:
+		return s.updateWholeSeries(master, occurrence, text, date, reminderTime, o)
+	}
+}
+
+// deleteSeries удаляет повторяющееся событие или его вхождения с учетом области изменения
+func (s *EventService) deleteSeries(event *domain.Event, o *eventOptions) error {
+	master, occurrence, err := s.resolveSeries(event, o)
+	if err != nil {
+		return err
+	}
+
+	switch o.scope {
+	case domain.ScopeThis:
+		if override, err := s.findOverride(master, occurrence); err != nil {
+			return err
+		} else if override != nil {
+			// Вхождение уже исключено из серии, достаточно удалить его замену
+			return s.delete(override)
+		}
+
+		if !master.HasOccurrence(occurrence) {
+			return domain.ErrOccurrenceNotFound
+		}
+		updated := *master
+		updated.ExDates = appendExDate(master.ExDates, occurrence)
+		updated.UpdatedAt = time.Now()
+		return s.update(&updated)
+
+	case domain.ScopeFollowing:
+		if occurrence.After(master.Date) {
+			if err := s.deleteOverrides(master, occurrence); err != nil {
+				return err
+			}
+			truncated := *master
+			truncated.Recurrence, _ = truncateRule(master, occurrence)
+			truncated.UpdatedAt = time.Now()
+			return s.update(&truncated)
+		}
+		fallthrough
+
+	default:
+		if err := s.deleteOverrides(master, time.Time{}); err != nil {
+			return err
+		}
+		return s.delete(master)
+	}
+}
+
+// OccurrenceOnDay возвращает исходное начало вхождения серии, которое приходится
+// на календарный день day, заданный его полночью в поясе пользователя. День события
+// на весь день берется в поясе самого события. Для обычного события возвращается
+// нулевое время, для измененного вхождения - его исходное начало.
+func (s *EventService) OccurrenceOnDay(userID, eventID string, day time.Time) (time.Time, error) {
+	event, err := s.findEvent(userID, eventID, domain.RoleRead)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if event.IsOverride() {
+		return *event.RecurrenceID, nil
+	}
+	if !event.IsRecurring() {
+		return time.Time{}, nil
+	}
+
+	if event.AllDay {
+		day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, event.Location())
+	}
+	for _, start := range event.Recurrence.Between(event.Date.In(event.Location()), day, day.AddDate(0, 0, 1)) {
+		if event.HasOccurrence(start) {
+			return start, nil
+		}
+		// Вхождение исключено из серии, но может быть заменено
+		if override, err := s.findOverride(event, start); err != nil {
+			return time.Time{}, err
+		} else if override != nil {
+			return start, nil
+		}
+	}
+	return time.Time{}, domain.ErrOccurrenceNotFound
+}
+
+// resolveSeries возвращает серию и начало вхождения, к которому относится изменение.
+// Для измененного вхождения без явной области (или с областью "this") серией считается
+// само вхождение, поэтому оно обновляется и удаляется как обычное событие.
+func (s *EventService) resolveSeries(event *domain.Event, o *eventOptions) (*domain.Event, time.Time, error) {
+	if event.IsOverride() {
+		if o.scope == "" || o.scope == domain.ScopeThis {
+			o.scope = domain.ScopeAll
+			return event, time.Time{}, nil
+		}
+		master, err := s.repo.GetByID(event.UserID, event.SeriesID)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		return master, *event.RecurrenceID, nil
+	}
+
+	if o.scope == domain.ScopeThis || o.scope == domain.ScopeFollowing {
+		if o.occurrence.IsZero() {
+			return nil, time.Time{}, domain.ErrOccurrenceNotFound
+		}
+		if override, err := s.findOverride(event, o.occurrence); err != nil {
+			return nil, time.Time{}, err
+		} else if override == nil && !event.HasOccurrence(o.occurrence) {
+			return nil, time.Time{}, domain.ErrOccurrenceNotFound
+		}
+	}
+	return event, o.occurrence, nil
+}
+
+// updateOccurrence изменяет одно вхождение: исключает его из серии (EXDATE)
+// и сохраняет измененную копию как отдельное событие с RECURRENCE-ID
+func (s *EventService) updateOccurrence(master *domain.Event, occurrence time.Time, text string, date time.Time, reminderTime *time.Time, o *eventOptions) (*domain.Event, error) {
+	override, err := s.findOverride(master, occurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if override != nil {
+		updated := *override
+		updated.Text = text
+		updated.ReminderTime = reminderTime
+		updated.UpdatedAt = now
+		o.reschedule(&updated, date)
+		updated.Recurrence = nil
+		if err := updated.Validate(); err != nil {
+			return nil, err
+		}
+		if err := s.checkConflicts(&updated, o); err != nil {
+			return nil, err
+		}
+		if err := s.update(&updated); err != nil {
+			return nil, err
+		}
+		return &updated, nil
+	}
+
+	recurrenceID := occurrence
+	override = &domain.Event{
+		ID:           generateID(),
+		UserID:       master.UserID,
+		CalendarID:   master.CalendarID,
+		UID:          master.UID,
+		Text:         text,
+		Date:         occurrence,
+		AllDay:       master.AllDay,
+		TimeZone:     master.TimeZone,
+		ReminderTime: reminderTime,
+		Reminders:    domain.ShiftReminders(master.Reminders, occurrence.Sub(master.Date)),
+		Attendees:    domain.CloneAttendees(master.Attendees),
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		SeriesID:     master.ID,
+		RecurrenceID: &recurrenceID,
+	}
+	if !master.End.IsZero() {
+		override.End = master.EndFor(occurrence)
+	}
+	o.reschedule(override, date)
+	override.Recurrence = nil
+	if err := override.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkConflicts(override, o); err != nil {
+		return nil, err
+	}
+
+	updated := *master
+	updated.ExDates = appendExDate(master.ExDates, occurrence)
+	updated.UpdatedAt = now
+	if err := s.update(&updated); err != nil {
+		return nil, err
+	}
+	if err := s.create(override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// updateWholeSeries изменяет всю серию. Если изменение пришло от конкретного вхождения,
+// начало серии сдвигается на ту же величину, что и вхождение. Исключения и замены
+// сдвигаются вместе с началом серии.
+func (s *EventService) updateWholeSeries(master *domain.Event, occurrence time.Time, text string, date time.Time, reminderTime *time.Time, o *eventOptions) (*domain.Event, error) {
+	updated := *master
+	updated.Text = text
+	updated.ReminderTime = reminderTime
+	updated.UpdatedAt = time.Now()
+
+	start := date
+	var shift time.Duration
+	if !occurrence.IsZero() && master.IsRecurring() {
+		// Время задано относительно вхождения, переносим его на начало серии
+		shift = date.Sub(occurrence)
+		start = master.Date.Add(shift)
+		if reminderTime != nil {
+			rt := reminderTime.Add(master.Date.Sub(occurrence))
+			updated.ReminderTime = &rt
+		}
+		if o.endSet && !o.end.IsZero() {
+			o.end = o.end.Add(master.Date.Sub(occurrence))
+		}
+		if o.remindersSet {
+			o.reminders = domain.ShiftReminders(o.reminders, master.Date.Sub(occurrence))
+		}
+	}
+
+	o.reschedule(&updated, start)
+	if occurrence.IsZero() && master.IsRecurring() {
+		// Новое начало задано для самой серии
+		shift = updated.Date.Sub(master.Date)
+	}
+	if master.IsOverride() {
+		// Измененное вхождение не может стать самостоятельной серией
+		updated.Recurrence = nil
+	}
+
+	if err := updated.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkConflicts(&updated, o); err != nil {
+		return nil, err
+	}
+
+	if shift != 0 {
+		updated.ExDates = make([]time.Time, len(master.ExDates))
+		for i, exdate := range master.ExDates {
+			updated.ExDates[i] = exdate.Add(shift)
+		}
+		if err := s.shiftOverrides(master, shift); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.update(&updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// splitSeries завершает серию перед вхождением occurrence и начинает с него новую серию
+// с измененными данными ("это и последующие")
+func (s *EventService) splitSeries(master *domain.Event, occurrence time.Time, text string, date time.Time, reminderTime *time.Time, o *eventOptions) (*domain.Event, error) {
+	truncatedRule, remainingRule := truncateRule(master, occurrence)
+	now := time.Now()
+
+	following := &domain.Event{
+		ID:           generateID(),
+		UserID:       master.UserID,
+		CalendarID:   master.CalendarID,
+		Text:         text,
+		Date:         occurrence,
+		AllDay:       master.AllDay,
+		TimeZone:     master.TimeZone,
+		ReminderTime: reminderTime,
+		Reminders:    domain.ShiftReminders(master.Reminders, occurrence.Sub(master.Date)),
+		Attendees:    domain.CloneAttendees(master.Attendees),
+		Recurrence:   remainingRule,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if !master.End.IsZero() {
+		following.End = master.EndFor(occurrence)
+	}
+	o.reschedule(following, date)
+
+	// Исключения из оставшейся части серии переходят в новую серию. Замена самого
+	// вхождения occurrence уступает место новой серии, поэтому его исключение не нужно.
+	shift := date.Sub(occurrence)
+	for _, exdate := range master.ExDates {
+		if exdate.After(occurrence) {
+			following.ExDates = append(following.ExDates, exdate.Add(shift))
+		}
+	}
+
+	if err := following.Validate(); err != nil {
+		return nil, err
+	}
+	// Вхождения серии начиная с occurrence переходят в новую серию
+	if err := s.checkConflicts(following, o, master.ID); err != nil {
+		return nil, err
+	}
+
+	truncated := *master
+	truncated.Recurrence = truncatedRule
+	truncated.UpdatedAt = now
+	truncated.ExDates = nil
+	for _, exdate := range master.ExDates {
+		if exdate.Before(occurrence) {
+			truncated.ExDates = append(truncated.ExDates, exdate)
+		}
+	}
+
+	if err := s.update(&truncated); err != nil {
+		return nil, err
+	}
+	if err := s.create(following); err != nil {
+		return nil, err
+	}
+	if err := s.moveOverrides(master, following, occurrence, shift); err != nil {
+		return nil, err
+	}
+	return following, nil
+}
+
+// overrides возвращает сохраненные измененные вхождения серии
+func (s *EventService) overrides(master *domain.Event) ([]*domain.Event, error) {
+	events, err := s.repo.GetAllActive(master.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*domain.Event
+	for _, event := range events {
+		if event.IsOverride() && event.SeriesID == master.ID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// findOverride находит измененное вхождение серии с исходным началом occurrence
+func (s *EventService) findOverride(master *domain.Event, occurrence time.Time) (*domain.Event, error) {
+	overrides, err := s.overrides(master)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if override.RecurrenceID.Equal(occurrence) {
+			return override, nil
+		}
+	}
+	return nil, nil
+}
+
+// deleteOverrides удаляет измененные вхождения серии, начиная с from
+// (нулевое значение удаляет все)
+func (s *EventService) deleteOverrides(master *domain.Event, from time.Time) error {
+	overrides, err := s.overrides(master)
+	if err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		if override.RecurrenceID.Before(from) {
+			continue
+		}
+		if err := s.delete(override); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveOverrides переносит измененные вхождения серии начиная с from в серию following
+// и сдвигает их RECURRENCE-ID на shift. Замена вхождения from удаляется:
+// это вхождение теперь начинает серию following.
+func (s *EventService) moveOverrides(master, following *domain.Event, from time.Time, shift time.Duration) error {
+	overrides, err := s.overrides(master)
+	if err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		if override.RecurrenceID.Before(from) {
+			continue
+		}
+		if override.RecurrenceID.Equal(from) {
+			if err := s.delete(override); err != nil {
+				return err
+			}
+			continue
+		}
+		updated := *override
+		updated.SeriesID = following.ID
+		recurrenceID := override.RecurrenceID.Add(shift)
+		updated.RecurrenceID = &recurrenceID
+		if err := s.update(&updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// shiftOverrides сдвигает RECURRENCE-ID измененных вхождений вслед за серией
+func (s *EventService) shiftOverrides(master *domain.Event, shift time.Duration) error {
+	overrides, err := s.overrides(master)
+	if err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		updated := *override
+		recurrenceID := override.RecurrenceID.Add(shift)
+		updated.RecurrenceID = &recurrenceID
+		if err := s.update(&updated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// truncateRule делит правило серии на часть до occurrence и оставшуюся часть.
+// COUNT распределяется между частями, иначе первая часть ограничивается UNTIL.
+func truncateRule(master *domain.Event, occurrence time.Time) (*domain.RecurrenceRule, *domain.RecurrenceRule) {
+	before := master.Recurrence.Clone()
+	after := master.Recurrence.Clone()
+
+	if master.Recurrence.Count > 0 {
+		n := len(master.Recurrence.Between(master.Date.In(master.Location()), master.Date, occurrence))
+		before.Count = n
+		after.Count = master.Recurrence.Count - n
+		return before, after
+	}
+
+	until := occurrence.Add(-time.Second)
+	before.Until = &until
+	return before, after
+}
+
+// appendExDate возвращает новый список исключений с добавленным началом вхождения
+func appendExDate(exdates []time.Time, occurrence time.Time) []time.Time {
+	result := make([]time.Time, 0, len(exdates)+1)
+	result = append(result, exdates...)
+	return append(result, occurrence)
+}
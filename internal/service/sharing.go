@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// errSharingDisabled возвращается при управлении доступом без хранилища списков доступа
+var errSharingDisabled = errors.New("calendar sharing is not configured")
+
+// ShareCalendar открывает пользователю granteeID календарь владельца ownerID
+// с ролью role или меняет роль. Доступом управляет владелец и пользователи с ролью manage.
+func (s *EventService) ShareCalendar(userID, ownerID, granteeID string, role domain.Role) (*domain.Share, error) {
+	if s.shares == nil {
+		return nil, errSharingDisabled
+	}
+	if err := s.authorize(userID, ownerID, domain.RoleManage); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	share := &domain.Share{
+		OwnerID:   ownerID,
+		GranteeID: granteeID,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := share.Validate(); err != nil {
+		return nil, err
+	}
+	existing, err := s.shares.Get(ownerID, granteeID)
+	if err == nil {
+		share.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, domain.ErrShareNotFound) {
+		return nil, err
+	}
+
+	if err := s.shares.Save(share); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// UnshareCalendar закрывает пользователю granteeID доступ к календарю владельца.
+// Кроме управляющих доступом, пользователь может отказаться от открытого ему календаря сам.
+func (s *EventService) UnshareCalendar(userID, ownerID, granteeID string) error {
+	if s.shares == nil {
+		return errSharingDisabled
+	}
+	if userID != granteeID {
+		if err := s.authorize(userID, ownerID, domain.RoleManage); err != nil {
+			return err
+		}
+	}
+	return s.shares.Delete(ownerID, granteeID)
+}
+
+// CalendarShares возвращает список доступа к календарю владельца
+func (s *EventService) CalendarShares(userID, ownerID string) ([]*domain.Share, error) {
+	if err := s.authorize(userID, ownerID, domain.RoleManage); err != nil {
+		return nil, err
+	}
+	if s.shares == nil {
+		return nil, nil
+	}
+	return s.shares.ListByOwner(ownerID)
+}
+
+// SharedCalendars возвращает календари других пользователей, открытые пользователю
+func (s *EventService) SharedCalendars(userID string) ([]*domain.Share, error) {
+	if s.shares == nil {
+		return nil, nil
+	}
+	return s.shares.ListByGrantee(userID)
+}
+
+// access возвращает роль пользователя в календаре владельца.
+// Владельцу доступно все, остальным - только открытое им.
+func (s *EventService) access(userID, ownerID string) (domain.Role, error) {
+	if userID == ownerID {
+		return domain.RoleManage, nil
+	}
+	if s.shares == nil {
+		return "", domain.ErrAccessDenied
+	}
+	share, err := s.shares.Get(ownerID, userID)
+	if errors.Is(err, domain.ErrShareNotFound) {
+		return "", domain.ErrAccessDenied
+	}
+	if err != nil {
+		return "", err
+	}
+	return share.Role, nil
+}
+
+// authorize проверяет, что пользователь имеет в календаре владельца права роли required
+func (s *EventService) authorize(userID, ownerID string, required domain.Role) error {
+	role, err := s.access(userID, ownerID)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(required) {
+		return domain.ErrAccessDenied
+	}
+	return nil
+}
+
+// readableOwners возвращает владельцев календарей, которые видит пользователь:
+// его собственный календарь и открытые ему
+func (s *EventService) readableOwners(userID string) ([]string, error) {
+	owners := []string{userID}
+	shared, err := s.SharedCalendars(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shared {
+		owners = append(owners, share.OwnerID)
+	}
+	return owners, nil
+}
+
+// findEvent ищет событие в календаре пользователя, затем в открытых ему
+// и среди приглашений и проверяет, что роль пользователя включает required.
+// Приглашенный участник может только читать событие. События календарей,
+// к которым у пользователя нет доступа, не находятся, чтобы не раскрывать их ID.
+func (s *EventService) findEvent(userID, eventID string, required domain.Role) (*domain.Event, error) {
+	event, err := s.repo.GetByID(userID, eventID)
+	if err == nil || !errors.Is(err, domain.ErrEventNotFound) {
+		return event, err
+	}
+
+	shared, err := s.SharedCalendars(userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, share := range shared {
+		event, err := s.repo.GetByID(share.OwnerID, eventID)
+		if errors.Is(err, domain.ErrEventNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !share.Role.Allows(required) {
+			return nil, domain.ErrAccessDenied
+		}
+		return event, nil
+	}
+
+	event, err = s.invitedEvent(userID, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.RoleRead.Allows(required) {
+		return nil, domain.ErrAccessDenied
+	}
+	return event, nil
+}
+
+// sortByStart упорядочивает события по началу
+func sortByStart(events []*domain.Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+}
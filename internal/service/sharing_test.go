@@ -0,0 +1,184 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+func newSharingService() *EventService {
+	return NewEventService(storage.NewMemoryRepository(), WithShareRepository(storage.NewMemoryShareRepository()))
+}
+
+func TestEventService_SharedCalendarRead(t *testing.T) {
+	service := newSharingService()
+	day := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	own, _ := service.CreateEvent("bob", "Own", day.Add(9*time.Hour), nil)
+	shared, _ := service.CreateEvent("alice", "Project sync", day.Add(10*time.Hour), nil)
+	service.CreateEvent("carol", "Private", day.Add(11*time.Hour), nil)
+
+	if _, err := service.ShareCalendar("alice", "alice", "bob", domain.RoleRead); err != nil {
+		t.Fatalf("ShareCalendar failed: %v", err)
+	}
+
+	events, err := service.GetEventsForDay("bob", day)
+	if err != nil {
+		t.Fatalf("GetEventsForDay failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != own.ID || events[1].ID != shared.ID {
+		t.Fatalf("Expected own and shared events, got %+v", events)
+	}
+	if events[1].UserID != "alice" {
+		t.Errorf("Expected shared event to keep its owner, got %s", events[1].UserID)
+	}
+
+	if _, err := service.GetEvent("bob", shared.ID); err != nil {
+		t.Errorf("Expected shared event to be readable, got %v", err)
+	}
+	if _, err := service.UpdateEvent("bob", shared.ID, "Renamed", shared.Date, nil); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied on update with read role, got %v", err)
+	}
+	if err := service.DeleteEvent("bob", shared.ID); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied on delete with read role, got %v", err)
+	}
+	if _, err := service.CreateEvent("bob", "Intrusion", day, nil, WithOwner("alice")); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied on create with read role, got %v", err)
+	}
+
+	// Календарь, который не открыт пользователю, не виден вовсе
+	if _, err := service.GetEventsForDay("carol", day); err != nil {
+		t.Fatalf("GetEventsForDay failed: %v", err)
+	}
+	if _, err := service.GetEvent("carol", shared.ID); !errors.Is(err, domain.ErrEventNotFound) {
+		t.Errorf("Expected ErrEventNotFound for unshared calendar, got %v", err)
+	}
+
+	// После отзыва доступа события владельца пропадают из выборки
+	if err := service.UnshareCalendar("alice", "alice", "bob"); err != nil {
+		t.Fatalf("UnshareCalendar failed: %v", err)
+	}
+	events, _ = service.GetEventsForDay("bob", day)
+	if len(events) != 1 || events[0].ID != own.ID {
+		t.Errorf("Expected only own event after unshare, got %+v", events)
+	}
+}
+
+func TestEventService_SharedCalendarWrite(t *testing.T) {
+	service := newSharingService()
+	day := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	service.ShareCalendar("alice", "alice", "bob", domain.RoleWrite)
+
+	created, err := service.CreateEvent("bob", "Planning", day, nil, WithOwner("alice"))
+	if err != nil {
+		t.Fatalf("Expected create in shared calendar, got %v", err)
+	}
+	if created.UserID != "alice" {
+		t.Errorf("Expected event in alice's calendar, got %s", created.UserID)
+	}
+
+	updated, err := service.UpdateEvent("bob", created.ID, "Planning v2", day, nil)
+	if err != nil {
+		t.Fatalf("Expected update with write role, got %v", err)
+	}
+	if updated.UserID != "alice" || updated.Text != "Planning v2" {
+		t.Errorf("Unexpected updated event %+v", updated)
+	}
+
+	// Роль write не дает управлять доступом
+	if _, err := service.ShareCalendar("bob", "alice", "carol", domain.RoleRead); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Expected ErrAccessDenied when sharing without manage role, got %v", err)
+	}
+
+	if err := service.DeleteEvent("bob", created.ID); err != nil {
+		t.Fatalf("Expected delete with write role, got %v", err)
+	}
+	if _, err := service.GetEvent("alice", created.ID); !errors.Is(err, domain.ErrEventNotFound) {
+		t.Errorf("Expected event to be deleted, got %v", err)
+	}
+}
+
+func TestEventService_ManageShares(t *testing.T) {
+	service := newSharingService()
+
+	first, _ := service.ShareCalendar("alice", "alice", "bob", domain.RoleManage)
+	if _, err := service.ShareCalendar("bob", "alice", "carol", domain.RoleRead); err != nil {
+		t.Fatalf("Expected manager to share the calendar, got %v", err)
+	}
+
+	// Повторное открытие меняет роль и сохраняет время создания записи
+	changed, err := service.ShareCalendar("alice", "alice", "bob", domain.RoleWrite)
+	if err != nil {
+		t.Fatalf("ShareCalendar failed: %v", err)
+	}
+	if changed.Role != domain.RoleWrite || !changed.CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("Unexpected share after role change %+v", changed)
+	}
+
+	shares, err := service.CalendarShares("alice", "alice")
+	if err != nil || len(shares) != 2 {
+		t.Fatalf("Expected two shares, got %v %v", shares, err)
+	}
+	if _, err := service.CalendarShares("carol", "alice"); !errors.Is(err, domain.ErrAccessDenied) {
+		t.Errorf("Expected reader not to see the access list, got %v", err)
+	}
+
+	// Пользователь может сам отказаться от открытого ему календаря
+	if err := service.UnshareCalendar("carol", "alice", "carol"); err != nil {
+		t.Errorf("Expected grantee to leave the calendar, got %v", err)
+	}
+	if shared, _ := service.SharedCalendars("carol"); len(shared) != 0 {
+		t.Errorf("Expected no shared calendars, got %+v", shared)
+	}
+
+	if _, err := service.ShareCalendar("alice", "alice", "alice", domain.RoleRead); !errors.Is(err, domain.ErrInvalidUserID) {
+		t.Errorf("Expected ErrInvalidUserID when sharing with the owner, got %v", err)
+	}
+	if _, err := service.ShareCalendar("alice", "alice", "dave", domain.Role("admin")); !errors.Is(err, domain.ErrInvalidRole) {
+		t.Errorf("Expected ErrInvalidRole, got %v", err)
+	}
+}
+
+// changeRecorder запоминает изменения по получателям
+type changeRecorder map[string][]domain.ChangeType
+
+func (r changeRecorder) Notify(change *domain.Change) {
+	r[change.UserID] = append(r[change.UserID], change.Type)
+}
+
+func TestEventService_ChangesReachReaders(t *testing.T) {
+	changes := changeRecorder{}
+	service := NewEventService(storage.NewMemoryRepository(), WithChangeNotifier(changes),
+		WithShareRepository(storage.NewMemoryShareRepository()),
+		WithInvitationRepository(storage.NewMemoryInvitationRepository()))
+	service.ShareCalendar("alice", "alice", "bob", domain.RoleRead)
+
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	event, err := service.CreateEvent("alice", "Review", start, nil, WithAttendees([]string{"carol", "dave", "bob"}))
+	if err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	service.RespondToInvitation("dave", event.ID, domain.PartStatDeclined)
+	service.UpdateEvent("alice", event.ID, "Design review", start, nil)
+	service.DeleteEvent("alice", event.ID)
+
+	// Доступ к календарю и приглашение не дублируют изменения
+	full := []domain.ChangeType{domain.ChangeCreated, domain.ChangeUpdated, domain.ChangeUpdated, domain.ChangeDeleted}
+	for _, userID := range []string{"alice", "bob", "carol"} {
+		if got := changes[userID]; !slices.Equal(got, full) {
+			t.Errorf("Expected %s to receive %v, got %v", userID, full, got)
+		}
+	}
+	// Отказавшийся участник перестает получать изменения
+	if got := changes["dave"]; len(got) != 1 || got[0] != domain.ChangeCreated {
+		t.Errorf("Expected declined attendee to receive only creation, got %v", got)
+	}
+	if got := changes["erin"]; len(got) != 0 {
+		t.Errorf("Expected no changes for unrelated user, got %v", got)
+	}
+}
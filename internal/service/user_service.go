@@ -0,0 +1,110 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// UserService обрабатывает бизнес-логику для настроек пользователей
+type UserService struct {
+	repo domain.UserRepository
+}
+
+// NewUserService создает новый сервис пользователей
+func NewUserService(repo domain.UserRepository) *UserService {
+	return &UserService{repo: repo}
+}
+
+// GetUser возвращает настройки пользователя или настройки по умолчанию,
+// если пользователь их еще не сохранял
+func (s *UserService) GetUser(userID string) (*domain.User, error) {
+	if userID == "" {
+		return nil, domain.ErrInvalidUserID
+	}
+
+	user, err := s.repo.Get(userID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return &domain.User{ID: userID}, nil
+	}
+	return user, err
+}
+
+// UpdateUser сохраняет настройки пользователя
+func (s *UserService) UpdateUser(user *domain.User) (*domain.User, error) {
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// feedTokenBytes - длина секрета подписки на календарь в байтах
+const feedTokenBytes = 32
+
+// EnableFeed возвращает секрет подписки на календарь пользователя,
+// создавая его при первом обращении
+func (s *UserService) EnableFeed(userID string) (string, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.FeedToken != "" {
+		return user.FeedToken, nil
+	}
+	return s.setFeedToken(user)
+}
+
+// RotateFeedToken заменяет секрет подписки; прежний адрес перестает работать
+func (s *UserService) RotateFeedToken(userID string) (string, error) {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return "", err
+	}
+	return s.setFeedToken(user)
+}
+
+// DisableFeed удаляет секрет, выключая подписку на календарь
+func (s *UserService) DisableFeed(userID string) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.FeedToken == "" {
+		return nil
+	}
+	user.FeedToken = ""
+	return s.repo.Save(user)
+}
+
+// CheckFeedToken проверяет секрет из адреса подписки
+func (s *UserService) CheckFeedToken(userID, token string) error {
+	user, err := s.GetUser(userID)
+	if err != nil {
+		return err
+	}
+	if user.FeedToken == "" || subtle.ConstantTimeCompare([]byte(user.FeedToken), []byte(token)) != 1 {
+		return domain.ErrInvalidFeedToken
+	}
+	return nil
+}
+
+func (s *UserService) setFeedToken(user *domain.User) (string, error) {
+	buf := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("generate feed token: %w", err)
+	}
+	user.FeedToken = base64.RawURLEncoding.EncodeToString(buf)
+	if err := s.repo.Save(user); err != nil {
+		return "", err
+	}
+	return user.FeedToken, nil
+}
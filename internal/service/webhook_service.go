@@ -0,0 +1,86 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// webhookSecretBytes - длина создаваемого секрета подписки на webhook в байтах
+const webhookSecretBytes = 32
+
+// WebhookService управляет подписками внешних систем на изменения событий
+type WebhookService struct {
+	repo domain.WebhookRepository
+}
+
+// NewWebhookService создает новый сервис подписок на webhook
+func NewWebhookService(repo domain.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// Subscribe создает подписку пользователя на изменения типов types
+// (пустой список - все изменения). Если secret не задан, он создается.
+func (s *WebhookService) Subscribe(userID, url, secret string, types []domain.ChangeType) (*domain.WebhookSubscription, error) {
+	sub := &domain.WebhookSubscription{
+		ID:        generateID(),
+		UserID:    userID,
+		URL:       url,
+		Secret:    secret,
+		Types:     uniqueChangeTypes(types),
+		CreatedAt: time.Now(),
+	}
+	if err := sub.Validate(); err != nil {
+		return nil, err
+	}
+	if sub.Secret == "" {
+		buf := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, fmt.Errorf("generate webhook secret: %w", err)
+		}
+		sub.Secret = base64.RawURLEncoding.EncodeToString(buf)
+	}
+	if err := s.repo.Create(sub); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// List возвращает подписки пользователя
+func (s *WebhookService) List(userID string) ([]*domain.WebhookSubscription, error) {
+	return s.repo.List(userID)
+}
+
+// Get возвращает подписку пользователя
+func (s *WebhookService) Get(userID, id string) (*domain.WebhookSubscription, error) {
+	return s.repo.Get(userID, id)
+}
+
+// Unsubscribe удаляет подписку; сообщения в очереди по ней больше не отправляются
+func (s *WebhookService) Unsubscribe(userID, id string) error {
+	return s.repo.Delete(userID, id)
+}
+
+// Deliveries возвращает историю доставки по подписке пользователя, начиная с последних попыток
+func (s *WebhookService) Deliveries(userID, id string) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.repo.Get(userID, id); err != nil {
+		return nil, err
+	}
+	return s.repo.Deliveries(id)
+}
+
+// uniqueChangeTypes убирает повторы, сохраняя порядок
+func uniqueChangeTypes(types []domain.ChangeType) []domain.ChangeType {
+	var result []domain.ChangeType
+	seen := make(map[domain.ChangeType]bool, len(types))
+	for _, t := range types {
+		if !seen[t] {
+			seen[t] = true
+			result = append(result, t)
+		}
+	}
+	return result
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const apiKeysFileName = "api_keys.json"
+
+// MemoryAPIKeyRepository хранит ключи API в памяти
+type MemoryAPIKeyRepository struct {
+	mu   sync.RWMutex
+	keys map[string]*domain.APIKey // ключ: ID ключа API
+}
+
+// NewMemoryAPIKeyRepository создает новое хранилище ключей API
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{
+		keys: make(map[string]*domain.APIKey),
+	}
+}
+
+// Create сохраняет новый ключ
+func (r *MemoryAPIKeyRepository) Create(key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *key
+	r.keys[key.ID] = &copied
+	return nil
+}
+
+// Get получает ключ по ID
+func (r *MemoryAPIKeyRepository) Get(id string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key, exists := r.keys[id]
+	if !exists {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	copied := *key
+	return &copied, nil
+}
+
+// List возвращает ключи пользователя в порядке создания
+func (r *MemoryAPIKeyRepository) List(userID string) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.APIKey
+	for _, key := range r.keys {
+		if key.UserID == userID {
+			copied := *key
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// Delete удаляет ключ пользователя
+func (r *MemoryAPIKeyRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists || key.UserID != userID {
+		return domain.ErrAPIKeyNotFound
+	}
+	delete(r.keys, id)
+	return nil
+}
+
+// FileAPIKeyRepository хранит ключи API в JSON-файле.
+// Ключи меняются редко, поэтому файл перезаписывается целиком.
+type FileAPIKeyRepository struct {
+	*MemoryAPIKeyRepository
+	path string
+}
+
+// NewFileAPIKeyRepository открывает хранилище ключей API в директории dir
+func NewFileAPIKeyRepository(dir string) (*FileAPIKeyRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileAPIKeyRepository{
+		MemoryAPIKeyRepository: NewMemoryAPIKeyRepository(),
+		path:                   filepath.Join(dir, apiKeysFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read api keys: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.keys); err != nil {
+		return nil, fmt.Errorf("decode api keys: %w", err)
+	}
+	return r, nil
+}
+
+// Create сохраняет новый ключ и записывает файл
+func (r *FileAPIKeyRepository) Create(key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *key
+	r.keys[key.ID] = &copied
+	if err := writeJSONFile(r.path, r.keys); err != nil {
+		delete(r.keys, key.ID)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет ключ и записывает файл
+func (r *FileAPIKeyRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key, exists := r.keys[id]
+	if !exists || key.UserID != userID {
+		return domain.ErrAPIKeyNotFound
+	}
+	delete(r.keys, id)
+	if err := writeJSONFile(r.path, r.keys); err != nil {
+		r.keys[id] = key
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const calendarsFileName = "calendars.json"
+
+// MemoryCalendarRepository хранит календари пользователей в памяти
+type MemoryCalendarRepository struct {
+	mu        sync.RWMutex
+	calendars map[string]*domain.Calendar // ключ: ID календаря
+}
+
+// NewMemoryCalendarRepository создает новое хранилище календарей
+func NewMemoryCalendarRepository() *MemoryCalendarRepository {
+	return &MemoryCalendarRepository{
+		calendars: make(map[string]*domain.Calendar),
+	}
+}
+
+// Create сохраняет новый календарь
+func (r *MemoryCalendarRepository) Create(calendar *domain.Calendar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calendars[calendar.ID] = calendar.Clone()
+	return nil
+}
+
+// Update заменяет календарь пользователя
+func (r *MemoryCalendarRepository) Update(calendar *domain.Calendar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.calendars[calendar.ID]
+	if !exists || existing.UserID != calendar.UserID {
+		return domain.ErrCalendarNotFound
+	}
+	r.calendars[calendar.ID] = calendar.Clone()
+	return nil
+}
+
+// Get получает календарь пользователя по ID
+func (r *MemoryCalendarRepository) Get(userID, id string) (*domain.Calendar, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	calendar, exists := r.calendars[id]
+	if !exists || calendar.UserID != userID {
+		return nil, domain.ErrCalendarNotFound
+	}
+	return calendar.Clone(), nil
+}
+
+// List возвращает календари пользователя в порядке создания
+func (r *MemoryCalendarRepository) List(userID string) ([]*domain.Calendar, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.Calendar
+	for _, calendar := range r.calendars {
+		if calendar.UserID == userID {
+			result = append(result, calendar.Clone())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.Before(result[j].CreatedAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// Delete удаляет календарь пользователя
+func (r *MemoryCalendarRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calendar, exists := r.calendars[id]
+	if !exists || calendar.UserID != userID {
+		return domain.ErrCalendarNotFound
+	}
+	delete(r.calendars, id)
+	return nil
+}
+
+// FileCalendarRepository хранит календари в JSON-файле.
+// Календари меняются редко, поэтому файл перезаписывается целиком.
+type FileCalendarRepository struct {
+	*MemoryCalendarRepository
+	path string
+}
+
+// NewFileCalendarRepository открывает хранилище календарей в директории dir
+func NewFileCalendarRepository(dir string) (*FileCalendarRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileCalendarRepository{
+		MemoryCalendarRepository: NewMemoryCalendarRepository(),
+		path:                     filepath.Join(dir, calendarsFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read calendars: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.calendars); err != nil {
+		return nil, fmt.Errorf("decode calendars: %w", err)
+	}
+	return r, nil
+}
+
+// Create сохраняет новый календарь и записывает файл
+func (r *FileCalendarRepository) Create(calendar *domain.Calendar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calendars[calendar.ID] = calendar.Clone()
+	if err := writeJSONFile(r.path, r.calendars); err != nil {
+		delete(r.calendars, calendar.ID)
+		return err
+	}
+	return nil
+}
+
+// Update заменяет календарь и записывает файл
+func (r *FileCalendarRepository) Update(calendar *domain.Calendar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.calendars[calendar.ID]
+	if !exists || existing.UserID != calendar.UserID {
+		return domain.ErrCalendarNotFound
+	}
+	r.calendars[calendar.ID] = calendar.Clone()
+	if err := writeJSONFile(r.path, r.calendars); err != nil {
+		r.calendars[calendar.ID] = existing
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет календарь и записывает файл
+func (r *FileCalendarRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calendar, exists := r.calendars[id]
+	if !exists || calendar.UserID != userID {
+		return domain.ErrCalendarNotFound
+	}
+	delete(r.calendars, id)
+	if err := writeJSONFile(r.path, r.calendars); err != nil {
+		r.calendars[id] = calendar
+		return err
+	}
+	return nil
+}
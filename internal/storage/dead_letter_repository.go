@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const deadLettersFileName = "dead_letters.json"
+
+// MemoryDeadLetterRepository хранит недоставленные напоминания в памяти
+type MemoryDeadLetterRepository struct {
+	mu      sync.RWMutex
+	letters map[string]*domain.DeadLetter
+}
+
+// NewMemoryDeadLetterRepository создает новое хранилище недоставленных напоминаний
+func NewMemoryDeadLetterRepository() *MemoryDeadLetterRepository {
+	return &MemoryDeadLetterRepository{
+		letters: make(map[string]*domain.DeadLetter),
+	}
+}
+
+// Add сохраняет недоставленное напоминание
+func (r *MemoryDeadLetterRepository) Add(letter *domain.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *letter
+	r.letters[letter.ID] = &copied
+	return nil
+}
+
+// List возвращает недоставленные напоминания в порядке их появления
+func (r *MemoryDeadLetterRepository) List() ([]*domain.DeadLetter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	result := make([]*domain.DeadLetter, 0, len(r.letters))
+	for _, letter := range r.letters {
+		copied := *letter
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].FailedAt.Before(result[j].FailedAt)
+	})
+	return result, nil
+}
+
+// Get получает недоставленное напоминание по ID
+func (r *MemoryDeadLetterRepository) Get(id string) (*domain.DeadLetter, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	letter, exists := r.letters[id]
+	if !exists {
+		return nil, domain.ErrDeadLetterNotFound
+	}
+	copied := *letter
+	return &copied, nil
+}
+
+// Delete удаляет недоставленное напоминание
+func (r *MemoryDeadLetterRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.letters[id]; !exists {
+		return domain.ErrDeadLetterNotFound
+	}
+	delete(r.letters, id)
+	return nil
+}
+
+// FileDeadLetterRepository хранит недоставленные напоминания в JSON-файле.
+// Их немного, поэтому файл перезаписывается целиком при каждом изменении.
+type FileDeadLetterRepository struct {
+	*MemoryDeadLetterRepository
+	path string
+}
+
+// NewFileDeadLetterRepository открывает хранилище недоставленных напоминаний в директории dir
+func NewFileDeadLetterRepository(dir string) (*FileDeadLetterRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileDeadLetterRepository{
+		MemoryDeadLetterRepository: NewMemoryDeadLetterRepository(),
+		path:                       filepath.Join(dir, deadLettersFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read dead letters: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.letters); err != nil {
+		return nil, fmt.Errorf("decode dead letters: %w", err)
+	}
+	return r, nil
+}
+
+// Add сохраняет недоставленное напоминание и записывает файл
+func (r *FileDeadLetterRepository) Add(letter *domain.DeadLetter) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *letter
+	r.letters[letter.ID] = &copied
+	if err := writeJSONFile(r.path, r.letters); err != nil {
+		delete(r.letters, letter.ID)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет недоставленное напоминание и записывает файл
+func (r *FileDeadLetterRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	letter, exists := r.letters[id]
+	if !exists {
+		return domain.ErrDeadLetterNotFound
+	}
+	delete(r.letters, id)
+	if err := writeJSONFile(r.path, r.letters); err != nil {
+		r.letters[id] = letter
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"encoding/json"
+	"os"
+)
+
+// writeJSONFile атомарно записывает значение в файл в формате JSON
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic записывает данные во временный файл и переименовывает его,
+// чтобы при сбое на диске оставалась либо старая, либо новая версия файла
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
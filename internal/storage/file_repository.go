@@ -0,0 +1,364 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const (
+	walFileName      = "events.wal"
+	snapshotFileName = "events.snapshot"
+
+	opCreate  = "create"
+	opUpdate  = "update"
+	opDelete  = "delete"
+	opArchive = "archive"
+)
+
+// walRecord представляет одну запись журнала изменений
+type walRecord struct {
+	Seq     uint64        `json:"seq"`
+	Op      string        `json:"op"`
+	Event   *domain.Event `json:"event,omitempty"`
+	UserID  string        `json:"user_id,omitempty"`
+	EventID string        `json:"event_id,omitempty"`
+	Before  time.Time     `json:"before,omitempty"`
+}
+
+// snapshot представляет сжатое состояние репозитория на момент записи Seq
+type snapshot struct {
+	Seq    uint64          `json:"seq"`
+	Events []*domain.Event `json:"events"`
+}
+
+// FileRepository хранит события в памяти и записывает каждое изменение
+// в журнал на диске (WAL). Журнал периодически сжимается в снимок,
+// при запуске состояние восстанавливается из снимка и журнала.
+type FileRepository struct {
+	mu            sync.Mutex // сериализует изменения и запись в журнал
+	mem           *MemoryRepository
+	dir           string
+	wal           *os.File
+	seq           uint64
+	sinceSnapshot int
+	snapshotEvery int
+}
+
+// NewFileRepository открывает репозиторий в директории dir и восстанавливает
+// состояние. snapshotEvery задает количество записей журнала между снимками.
+func NewFileRepository(dir string, snapshotEvery int) (*FileRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	if snapshotEvery <= 0 {
+		snapshotEvery = 1000
+	}
+
+	r := &FileRepository{
+		mem:           NewMemoryRepository(),
+		dir:           dir,
+		snapshotEvery: snapshotEvery,
+	}
+
+	if err := r.loadSnapshot(); err != nil {
+		return nil, err
+	}
+	if err := r.replayWAL(); err != nil {
+		return nil, err
+	}
+
+	wal, err := os.OpenFile(r.walPath(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open wal: %w", err)
+	}
+	r.wal = wal
+
+	return r, nil
+}
+
+// Create создает новое событие
+func (r *FileRepository) Create(event *domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.mem.GetByID(event.UserID, event.ID); err == nil {
+		return errors.New("event already exists")
+	}
+
+	if err := r.append(&walRecord{Op: opCreate, Event: event}); err != nil {
+		return err
+	}
+	if err := r.mem.Create(event); err != nil {
+		return err
+	}
+	r.maybeCompact()
+	return nil
+}
+
+// Update обновляет существующее событие
+func (r *FileRepository) Update(event *domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.mem.GetByID(event.UserID, event.ID); err != nil {
+		return err
+	}
+
+	if err := r.append(&walRecord{Op: opUpdate, Event: event}); err != nil {
+		return err
+	}
+	if err := r.mem.Update(event); err != nil {
+		return err
+	}
+	r.maybeCompact()
+	return nil
+}
+
+// Delete удаляет событие
+func (r *FileRepository) Delete(userID, eventID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.mem.GetByID(userID, eventID); err != nil {
+		return err
+	}
+
+	if err := r.append(&walRecord{Op: opDelete, UserID: userID, EventID: eventID}); err != nil {
+		return err
+	}
+	if err := r.mem.Delete(userID, eventID); err != nil {
+		return err
+	}
+	r.maybeCompact()
+	return nil
+}
+
+// GetByID получает событие по ID
+func (r *FileRepository) GetByID(userID, eventID string) (*domain.Event, error) {
+	return r.mem.GetByID(userID, eventID)
+}
+
+// GetByDateRange получает события, пересекающиеся с диапазоном дат
+func (r *FileRepository) GetByDateRange(userID string, start, end time.Time) ([]*domain.Event, error) {
+	return r.mem.GetByDateRange(userID, start, end)
+}
+
+// GetAllActive получает все активные события пользователя
+func (r *FileRepository) GetAllActive(userID string) ([]*domain.Event, error) {
+	return r.mem.GetAllActive(userID)
+}
+
+// GetRecurring получает активные повторяющиеся события пользователя
+func (r *FileRepository) GetRecurring(userID string) ([]*domain.Event, error) {
+	return r.mem.GetRecurring(userID)
+}
+
+// GetArchived получает архивные события пользователя
+func (r *FileRepository) GetArchived(userID string) ([]*domain.Event, error) {
+	return r.mem.GetArchived(userID)
+}
+
+// GetWithReminders получает активные события всех пользователей, у которых есть напоминания
+func (r *FileRepository) GetWithReminders() ([]*domain.Event, error) {
+	return r.mem.GetWithReminders()
+}
+
+// SearchText находит события пользователя по словам текста
+func (r *FileRepository) SearchText(userID, query string) ([]domain.TextMatch, error) {
+	return r.mem.SearchText(userID, query)
+}
+
+// ArchiveOldEvents архивирует события старше указанного времени
+func (r *FileRepository) ArchiveOldEvents(before time.Time) ([]*domain.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.append(&walRecord{Op: opArchive, Before: before}); err != nil {
+		return nil, err
+	}
+	archived, err := r.mem.ArchiveOldEvents(before)
+	if err != nil {
+		return nil, err
+	}
+	r.maybeCompact()
+	return archived, nil
+}
+
+// Close сбрасывает журнал на диск и закрывает файл
+func (r *FileRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.wal == nil {
+		return nil
+	}
+	if err := r.wal.Sync(); err != nil {
+		return err
+	}
+	err := r.wal.Close()
+	r.wal = nil
+	return err
+}
+
+// append записывает запись в журнал до применения изменения в памяти
+func (r *FileRepository) append(rec *walRecord) error {
+	if r.wal == nil {
+		return errors.New("repository is closed")
+	}
+
+	rec.Seq = r.seq + 1
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode wal record: %w", err)
+	}
+	data = append(data, '\n')
+
+	if _, err := r.wal.Write(data); err != nil {
+		return fmt.Errorf("write wal: %w", err)
+	}
+	if err := r.wal.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %w", err)
+	}
+
+	r.seq = rec.Seq
+	r.sinceSnapshot++
+	return nil
+}
+
+// maybeCompact сжимает журнал в снимок, когда накопилось snapshotEvery записей.
+// Вызывается после применения изменения в памяти, чтобы снимок включал его.
+func (r *FileRepository) maybeCompact() {
+	if r.sinceSnapshot < r.snapshotEvery {
+		return
+	}
+	// Ошибка сжатия не теряет данные: журнал остается на месте,
+	// попытка повторится после следующего изменения
+	_ = r.compact()
+}
+
+// compact записывает снимок текущего состояния и очищает журнал
+func (r *FileRepository) compact() error {
+	if err := r.writeSnapshot(); err != nil {
+		return err
+	}
+
+	// Записи журнала до r.seq уже в снимке и пропускаются при восстановлении,
+	// поэтому при ошибке открытия нового файла старый журнал остается рабочим
+	wal, err := os.OpenFile(r.walPath(), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	r.wal.Close()
+	r.wal = wal
+	r.sinceSnapshot = 0
+	return nil
+}
+
+// writeSnapshot атомарно записывает снимок текущего состояния
+func (r *FileRepository) writeSnapshot() error {
+	r.mem.mu.RLock()
+	snap := snapshot{Seq: r.seq, Events: make([]*domain.Event, 0, len(r.mem.events))}
+	for _, event := range r.mem.events {
+		snap.Events = append(snap.Events, event)
+	}
+	data, err := json.Marshal(snap)
+	r.mem.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(r.snapshotPath(), data)
+}
+
+// loadSnapshot загружает последний снимок, если он существует
+func (r *FileRepository) loadSnapshot() error {
+	data, err := os.ReadFile(r.snapshotPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	for _, event := range snap.Events {
+		r.mem.put(event)
+	}
+	r.seq = snap.Seq
+	return nil
+}
+
+// replayWAL применяет записи журнала, сделанные после снимка.
+// Недописанная последняя запись (сбой во время записи) отбрасывается.
+func (r *FileRepository) replayWAL() error {
+	f, err := os.Open(r.walPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open wal: %w", err)
+	}
+	defer f.Close()
+
+	reader := bufio.NewReader(f)
+	var valid int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read wal: %w", err)
+		}
+
+		var rec walRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &rec); err != nil {
+			break
+		}
+		valid += int64(len(line))
+
+		if rec.Seq <= r.seq {
+			continue
+		}
+		r.apply(&rec)
+		r.seq = rec.Seq
+		r.sinceSnapshot++
+	}
+
+	// Обрезать поврежденный хвост, чтобы новые записи не склеились с ним
+	return os.Truncate(r.walPath(), valid)
+}
+
+// apply применяет запись журнала к состоянию в памяти
+func (r *FileRepository) apply(rec *walRecord) {
+	switch rec.Op {
+	case opCreate, opUpdate:
+		r.mem.put(rec.Event)
+	case opDelete:
+		r.mem.remove(rec.UserID, rec.EventID)
+	case opArchive:
+		_, _ = r.mem.ArchiveOldEvents(rec.Before)
+	}
+}
+
+func (r *FileRepository) walPath() string {
+	return filepath.Join(r.dir, walFileName)
+}
+
+func (r *FileRepository) snapshotPath() string {
+	return filepath.Join(r.dir, snapshotFileName)
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+func newTestEvent(id string, date time.Time) *domain.Event {
+	return &domain.Event{
+		ID:        id,
+		UserID:    "user1",
+		Text:      "Event " + id,
+		Date:      date,
+		CreatedAt: date,
+		UpdatedAt: date,
+	}
+}
+
+func TestFileRepository_ReplayAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	repo, err := NewFileRepository(dir, 100)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if err := repo.Create(newTestEvent(id, date)); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+
+	updated := newTestEvent("e2", date)
+	updated.Text = "Updated"
+	if err := repo.Update(updated); err != nil {
+		t.Fatalf("Failed to update event: %v", err)
+	}
+	if err := repo.Delete("user1", "e3"); err != nil {
+		t.Fatalf("Failed to delete event: %v", err)
+	}
+	if _, err := repo.ArchiveOldEvents(date.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to archive events: %v", err)
+	}
+	if err := repo.Close(); err != nil {
+		t.Fatalf("Failed to close repository: %v", err)
+	}
+
+	reopened, err := NewFileRepository(dir, 100)
+	if err != nil {
+		t.Fatalf("Failed to reopen repository: %v", err)
+	}
+	defer reopened.Close()
+
+	event, err := reopened.GetByID("user1", "e2")
+	if err != nil {
+		t.Fatalf("Expected event e2 after restart, got %v", err)
+	}
+	if event.Text != "Updated" {
+		t.Errorf("Expected Text %s, got %s", "Updated", event.Text)
+	}
+	if !event.Archived {
+		t.Error("Expected event to be archived after restart")
+	}
+
+	_, err = reopened.GetByID("user1", "e3")
+	if !errors.Is(err, domain.ErrEventNotFound) {
+		t.Errorf("Expected error %v, got %v", domain.ErrEventNotFound, err)
+	}
+
+	// Полнотекстовый индекс восстанавливается вместе с событиями
+	matches, err := reopened.SearchText("user1", "updated")
+	if err != nil || len(matches) != 1 || matches[0].Event.ID != "e2" {
+		t.Errorf("Expected text search to find e2 after restart, got %v (%v)", matches, err)
+	}
+	if matches, _ := reopened.SearchText("user1", "e3"); len(matches) != 0 {
+		t.Errorf("Expected deleted event not to be found, got %v", matches)
+	}
+}
+
+func TestFileRepository_Compaction(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	repo, err := NewFileRepository(dir, 2)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if err := repo.Create(newTestEvent(id, date)); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+	}
+	repo.Close()
+
+	if _, err := os.Stat(filepath.Join(dir, snapshotFileName)); err != nil {
+		t.Fatalf("Expected snapshot to be written, got %v", err)
+	}
+
+	reopened, err := NewFileRepository(dir, 2)
+	if err != nil {
+		t.Fatalf("Failed to reopen repository: %v", err)
+	}
+	defer reopened.Close()
+
+	events, err := reopened.GetAllActive("user1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Errorf("Expected 3 events, got %d", len(events))
+	}
+}
+
+func TestFileRepository_TornTail(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	repo, err := NewFileRepository(dir, 100)
+	if err != nil {
+		t.Fatalf("Failed to open repository: %v", err)
+	}
+	if err := repo.Create(newTestEvent("e1", date)); err != nil {
+		t.Fatalf("Failed to create event: %v", err)
+	}
+	repo.Close()
+
+	// Имитировать сбой во время записи последней записи журнала
+	f, err := os.OpenFile(filepath.Join(dir, walFileName), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	f.WriteString(`{"seq":2,"op":"create","event":{"ID":"e2"`)
+	f.Close()
+
+	reopened, err := NewFileRepository(dir, 100)
+	if err != nil {
+		t.Fatalf("Failed to reopen repository: %v", err)
+	}
+	if err := reopened.Create(newTestEvent("e2", date)); err != nil {
+		t.Fatalf("Failed to create event after recovery: %v", err)
+	}
+	reopened.Close()
+
+	again, err := NewFileRepository(dir, 100)
+	if err != nil {
+		t.Fatalf("Failed to reopen repository: %v", err)
+	}
+	defer again.Close()
+
+	events, _ := again.GetAllActive("user1")
+	if len(events) != 2 {
+		t.Errorf("Expected 2 events, got %d", len(events))
+	}
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// intervalTree хранит события пользователя в декартовом дереве (treap),
+// упорядоченном по началу события. Каждый узел помнит наибольшее окончание
+// в своем поддереве, поэтому поиск пересечений с интервалом отбрасывает
+// поддеревья целиком и стоит O(log n + k).
+type intervalTree struct {
+	root  *intervalNode
+	nodes map[string]*intervalNode // ключ: eventID
+}
+
+// intervalNode хранит событие вместе с его началом и окончанием на момент вставки.
+// Хранилище вставляет собственную копию события (см. MemoryRepository.put),
+// поэтому изменения вызывающего кода не нарушают порядок в дереве.
+type intervalNode struct {
+	event    *domain.Event
+	start    time.Time
+	end      time.Time
+	maxEnd   time.Time
+	priority uint32
+	left     *intervalNode
+	right    *intervalNode
+}
+
+func newIntervalTree() *intervalTree {
+	return &intervalTree{nodes: make(map[string]*intervalNode)}
+}
+
+// Len возвращает число событий в дереве
+func (t *intervalTree) Len() int {
+	return len(t.nodes)
+}
+
+// Insert добавляет событие или заменяет событие с тем же ID
+func (t *intervalTree) Insert(event *domain.Event) {
+	t.Delete(event.ID)
+
+	end := event.EndTime()
+	if end.Before(event.Date) {
+		end = event.Date
+	}
+	node := &intervalNode{
+		event:    event,
+		start:    event.Date,
+		end:      end,
+		maxEnd:   end,
+		priority: rand.Uint32(),
+	}
+	t.nodes[event.ID] = node
+	t.root = insertNode(t.root, node)
+}
+
+// Delete удаляет событие по ID
+func (t *intervalTree) Delete(eventID string) {
+	node, ok := t.nodes[eventID]
+	if !ok {
+		return
+	}
+	delete(t.nodes, eventID)
+	t.root = deleteNode(t.root, node)
+}
+
+// Overlapping вызывает visit для событий, пересекающихся с интервалом [start, end),
+// в порядке возрастания начала. Событие без длительности попадает в интервал
+// по своему началу, как в domain.Event.Overlaps.
+func (t *intervalTree) Overlapping(start, end time.Time, visit func(*domain.Event)) {
+	overlapping(t.root, start, end, visit)
+}
+
+func overlapping(n *intervalNode, start, end time.Time, visit func(*domain.Event)) {
+	if n == nil || n.maxEnd.Before(start) {
+		return
+	}
+	overlapping(n.left, start, end, visit)
+	// У узла и всего правого поддерева начало не раньше n.start
+	if !n.start.Before(end) {
+		return
+	}
+	if n.overlaps(start, end) {
+		visit(n.event)
+	}
+	overlapping(n.right, start, end, visit)
+}
+
+func (n *intervalNode) overlaps(start, end time.Time) bool {
+	if !n.end.After(n.start) {
+		return !n.start.Before(start) && n.start.Before(end)
+	}
+	return n.start.Before(end) && n.end.After(start)
+}
+
+// less упорядочивает узлы по началу, а при равном начале по ID
+func (n *intervalNode) less(other *intervalNode) bool {
+	if !n.start.Equal(other.start) {
+		return n.start.Before(other.start)
+	}
+	return n.event.ID < other.event.ID
+}
+
+// update пересчитывает наибольшее окончание в поддереве
+func (n *intervalNode) update() {
+	n.maxEnd = n.end
+	if n.left != nil && n.left.maxEnd.After(n.maxEnd) {
+		n.maxEnd = n.left.maxEnd
+	}
+	if n.right != nil && n.right.maxEnd.After(n.maxEnd) {
+		n.maxEnd = n.right.maxEnd
+	}
+}
+
+func insertNode(root, node *intervalNode) *intervalNode {
+	if root == nil {
+		return node
+	}
+	if node.priority > root.priority {
+		node.left, node.right = split(root, node)
+		node.update()
+		return node
+	}
+	if node.less(root) {
+		root.left = insertNode(root.left, node)
+	} else {
+		root.right = insertNode(root.right, node)
+	}
+	root.update()
+	return root
+}
+
+func deleteNode(root, node *intervalNode) *intervalNode {
+	if root == nil {
+		return nil
+	}
+	switch {
+	case root == node:
+		return merge(root.left, root.right)
+	case node.less(root):
+		root.left = deleteNode(root.left, node)
+	default:
+		root.right = deleteNode(root.right, node)
+	}
+	root.update()
+	return root
+}
+
+// split делит поддерево на узлы меньше key и остальные
+func split(root, key *intervalNode) (*intervalNode, *intervalNode) {
+	if root == nil {
+		return nil, nil
+	}
+	if root.less(key) {
+		left, right := split(root.right, key)
+		root.right = left
+		root.update()
+		return root, right
+	}
+	left, right := split(root.left, key)
+	root.left = right
+	root.update()
+	return left, root
+}
+
+// merge объединяет поддеревья, все узлы left меньше узлов right
+func merge(left, right *intervalNode) *intervalNode {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	case left.priority > right.priority:
+		left.right = merge(left.right, right)
+		left.update()
+		return left
+	default:
+		right.left = merge(left, right.left)
+		right.update()
+		return right
+	}
+}
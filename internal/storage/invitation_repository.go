@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const invitationsFileName = "invitations.json"
+
+// MemoryInvitationRepository хранит приглашения в памяти
+type MemoryInvitationRepository struct {
+	mu      sync.RWMutex
+	byEvent map[string][]string // ключ: ownerID:eventID, значение - участники
+}
+
+// NewMemoryInvitationRepository создает новое хранилище приглашений
+func NewMemoryInvitationRepository() *MemoryInvitationRepository {
+	return &MemoryInvitationRepository{
+		byEvent: make(map[string][]string),
+	}
+}
+
+func invitationKey(ownerID, eventID string) string {
+	return ownerID + ":" + eventID
+}
+
+// SetAttendees заменяет участников события
+func (r *MemoryInvitationRepository) SetAttendees(ownerID, eventID string, userIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.set(invitationKey(ownerID, eventID), userIDs)
+	return nil
+}
+
+func (r *MemoryInvitationRepository) set(key string, userIDs []string) {
+	if len(userIDs) == 0 {
+		delete(r.byEvent, key)
+		return
+	}
+	r.byEvent[key] = append([]string(nil), userIDs...)
+}
+
+// ListByUser возвращает приглашения участника по владельцам и событиям
+func (r *MemoryInvitationRepository) ListByUser(userID string) ([]*domain.Invitation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.Invitation
+	for key, attendees := range r.byEvent {
+		for _, attendee := range attendees {
+			if attendee != userID {
+				continue
+			}
+			ownerID, eventID := splitInvitationKey(key)
+			result = append(result, &domain.Invitation{UserID: userID, OwnerID: ownerID, EventID: eventID})
+			break
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return invitationKey(result[i].OwnerID, result[i].EventID) < invitationKey(result[j].OwnerID, result[j].EventID)
+	})
+	return result, nil
+}
+
+// splitInvitationKey разбирает ключ ownerID:eventID
+func splitInvitationKey(key string) (string, string) {
+	ownerID, eventID, _ := strings.Cut(key, ":")
+	return ownerID, eventID
+}
+
+// FileInvitationRepository хранит приглашения в JSON-файле.
+// Файл перезаписывается целиком при изменении участников события.
+type FileInvitationRepository struct {
+	*MemoryInvitationRepository
+	path string
+}
+
+// NewFileInvitationRepository открывает хранилище приглашений в директории dir
+func NewFileInvitationRepository(dir string) (*FileInvitationRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileInvitationRepository{
+		MemoryInvitationRepository: NewMemoryInvitationRepository(),
+		path:                       filepath.Join(dir, invitationsFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read invitations: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.byEvent); err != nil {
+		return nil, fmt.Errorf("decode invitations: %w", err)
+	}
+	return r, nil
+}
+
+// SetAttendees заменяет участников события и записывает файл.
+// Если участники не изменились, файл не перезаписывается.
+func (r *FileInvitationRepository) SetAttendees(ownerID, eventID string, userIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := invitationKey(ownerID, eventID)
+	previous, existed := r.byEvent[key]
+	if slices.Equal(previous, userIDs) {
+		return nil
+	}
+	r.set(key, userIDs)
+	if err := writeJSONFile(r.path, r.byEvent); err != nil {
+		if existed {
+			r.byEvent[key] = previous
+		} else {
+			delete(r.byEvent, key)
+		}
+		return err
+	}
+	return nil
+}
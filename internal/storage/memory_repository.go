@@ -6,18 +6,32 @@ import (
 	"time"
 
 	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/search"
 )
 
-// MemoryRepository реализует хранение событий в памяти
+// MemoryRepository реализует хранение событий в памяти.
+// Сохраняются и возвращаются копии событий. Запросы по пользователю обслуживаются его собственными индексами
+// и не зависят от числа событий других пользователей.
 type MemoryRepository struct {
 	mu     sync.RWMutex
 	events map[string]*domain.Event // ключ: userID:eventID
+	users  map[string]*userEvents   // ключ: userID
+	text   *search.Index            // полнотекстовый индекс по Event.Text
+}
+
+// userEvents индексирует события одного пользователя
+type userEvents struct {
+	active    *intervalTree            // активные события по времени
+	recurring map[string]*domain.Event // активные серии
+	archived  map[string]*domain.Event
 }
 
 // NewMemoryRepository создает новый репозиторий в памяти
 func NewMemoryRepository() *MemoryRepository {
 	return &MemoryRepository{
 		events: make(map[string]*domain.Event),
+		users:  make(map[string]*userEvents),
+		text:   search.NewIndex(),
 	}
 }
 
@@ -36,7 +50,7 @@ func (r *MemoryRepository) Create(event *domain.Event) error {
 		return errors.New("event already exists")
 	}
 
-	r.events[key] = event
+	r.put(event)
 	return nil
 }
 
@@ -50,7 +64,7 @@ func (r *MemoryRepository) Update(event *domain.Event) error {
 		return domain.ErrEventNotFound
 	}
 
-	r.events[key] = event
+	r.put(event)
 	return nil
 }
 
@@ -64,10 +78,61 @@ func (r *MemoryRepository) Delete(userID, eventID string) error {
 		return domain.ErrEventNotFound
 	}
 
-	delete(r.events, key)
+	r.remove(userID, eventID)
 	return nil
 }
 
+// put сохраняет копию события и обновляет индексы. Вызывается под блокировкой на запись.
+// Хранилище не разделяет указатели с вызывающим кодом: иначе изменение события
+// без вызова Update нарушило бы согласованность индексов.
+func (r *MemoryRepository) put(event *domain.Event) {
+	event = event.Clone()
+	r.events[r.key(event.UserID, event.ID)] = event
+	r.text.Add(event.UserID, event.ID, event.Text)
+
+	u := r.users[event.UserID]
+	if u == nil {
+		u = &userEvents{
+			active:    newIntervalTree(),
+			recurring: make(map[string]*domain.Event),
+			archived:  make(map[string]*domain.Event),
+		}
+		r.users[event.UserID] = u
+	}
+	u.remove(event.ID)
+	u.add(event)
+}
+
+// remove удаляет событие из хранилища и индексов. Вызывается под блокировкой на запись.
+func (r *MemoryRepository) remove(userID, eventID string) {
+	delete(r.events, r.key(userID, eventID))
+	r.text.Remove(userID, eventID)
+
+	if u := r.users[userID]; u != nil {
+		u.remove(eventID)
+		if u.active.Len() == 0 && len(u.archived) == 0 {
+			delete(r.users, userID)
+		}
+	}
+}
+
+func (u *userEvents) add(event *domain.Event) {
+	if event.Archived {
+		u.archived[event.ID] = event
+		return
+	}
+	u.active.Insert(event)
+	if event.IsRecurring() {
+		u.recurring[event.ID] = event
+	}
+}
+
+func (u *userEvents) remove(eventID string) {
+	u.active.Delete(eventID)
+	delete(u.recurring, eventID)
+	delete(u.archived, eventID)
+}
+
 // GetByID получает событие по ID
 func (r *MemoryRepository) GetByID(userID, eventID string) (*domain.Event, error) {
 	r.mu.RLock()
@@ -79,21 +144,20 @@ func (r *MemoryRepository) GetByID(userID, eventID string) (*domain.Event, error
 		return nil, domain.ErrEventNotFound
 	}
 
-	return event, nil
+	return event.Clone(), nil
 }
 
-// GetByDateRange получает события в диапазоне дат
+// GetByDateRange получает активные события, пересекающиеся с диапазоном дат,
+// в порядке возрастания начала
 func (r *MemoryRepository) GetByDateRange(userID string, start, end time.Time) ([]*domain.Event, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var result []*domain.Event
-	for _, event := range r.events {
-		if event.UserID == userID && !event.Archived {
-			if (event.Date.Equal(start) || event.Date.After(start)) && event.Date.Before(end) {
-				result = append(result, event)
-			}
-		}
+	if u := r.users[userID]; u != nil {
+		u.active.Overlapping(start, end, func(event *domain.Event) {
+			result = append(result, event.Clone())
+		})
 	}
 
 	return result, nil
@@ -104,27 +168,96 @@ func (r *MemoryRepository) GetAllActive(userID string) ([]*domain.Event, error)
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
+	u := r.users[userID]
+	if u == nil {
+		return nil, nil
+	}
+	result := make([]*domain.Event, 0, u.active.Len())
+	for _, node := range u.active.nodes {
+		result = append(result, node.event.Clone())
+	}
+
+	return result, nil
+}
+
+// GetRecurring получает активные повторяющиеся события пользователя
+func (r *MemoryRepository) GetRecurring(userID string) ([]*domain.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.Event
+	if u := r.users[userID]; u != nil {
+		for _, event := range u.recurring {
+			result = append(result, event.Clone())
+		}
+	}
+
+	return result, nil
+}
+
+// GetArchived получает архивные события пользователя
+func (r *MemoryRepository) GetArchived(userID string) ([]*domain.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.Event
+	if u := r.users[userID]; u != nil {
+		for _, event := range u.archived {
+			result = append(result, event.Clone())
+		}
+	}
+
+	return result, nil
+}
+
+// GetWithReminders получает активные события всех пользователей, у которых есть напоминания
+func (r *MemoryRepository) GetWithReminders() ([]*domain.Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	var result []*domain.Event
 	for _, event := range r.events {
-		if event.UserID == userID && !event.Archived {
-			result = append(result, event)
+		if !event.Archived && event.HasReminders() {
+			result = append(result, event.Clone())
 		}
 	}
 
 	return result, nil
 }
 
-// ArchiveOldEvents архивирует события старше указанного времени
-func (r *MemoryRepository) ArchiveOldEvents(before time.Time) error {
+// SearchText находит события пользователя, включая архивные, по словам текста
+// и возвращает их по убыванию релевантности
+func (r *MemoryRepository) SearchText(userID, query string) ([]domain.TextMatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.text.Search(userID, query)
+	result := make([]domain.TextMatch, 0, len(matches))
+	for _, match := range matches {
+		if event, ok := r.events[r.key(userID, match.ID)]; ok {
+			result = append(result, domain.TextMatch{Event: event.Clone(), Score: match.Score})
+		}
+	}
+
+	return result, nil
+}
+
+// ArchiveOldEvents архивирует события старше указанного времени и возвращает копии архивированных
+func (r *MemoryRepository) ArchiveOldEvents(before time.Time) ([]*domain.Event, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	var archived []*domain.Event
 	for _, event := range r.events {
-		if event.Date.Before(before) && !event.Archived {
+		if event.EndsBefore(before) && !event.Archived {
 			event.Archived = true
+			// Событие переходит из индекса по времени в архивные
+			u := r.users[event.UserID]
+			u.remove(event.ID)
+			u.add(event)
+			archived = append(archived, event.Clone())
 		}
 	}
 
-	return nil
+	return archived, nil
 }
-
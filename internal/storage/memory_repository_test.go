@@ -0,0 +1,225 @@
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// randomEvent создает событие со случайным началом и длительностью:
+// без длительности, короткое, на весь день или длинное (до месяца)
+func randomEvent(rnd *rand.Rand, userID, id string, base time.Time) *domain.Event {
+	start := base.Add(time.Duration(rnd.Intn(365*24)) * time.Hour)
+	event := &domain.Event{
+		ID:        id,
+		UserID:    userID,
+		Text:      "Event " + id,
+		Date:      start,
+		CreatedAt: start,
+		UpdatedAt: start,
+	}
+	switch rnd.Intn(4) {
+	case 1:
+		event.End = start.Add(time.Duration(1+rnd.Intn(180)) * time.Minute)
+	case 2:
+		event.AllDay = true
+	case 3:
+		event.End = start.Add(time.Duration(1+rnd.Intn(30*24)) * time.Hour)
+	}
+	return event
+}
+
+func eventIDs(events []*domain.Event) []string {
+	ids := make([]string, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func TestMemoryRepository_GetByDateRange_MatchesScan(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewMemoryRepository()
+
+	var all []*domain.Event
+	for i := 0; i < 2000; i++ {
+		event := randomEvent(rnd, "user1", fmt.Sprintf("e%d", i), base)
+		if err := repo.Create(event); err != nil {
+			t.Fatalf("Failed to create event: %v", err)
+		}
+		all = append(all, event)
+	}
+	repo.Create(randomEvent(rnd, "user2", "other", base))
+
+	// Перенести и удалить часть событий, чтобы проверить обновление индекса
+	for i := 0; i < 300; i++ {
+		moved := randomEvent(rnd, "user1", all[i].ID, base)
+		if err := repo.Update(moved); err != nil {
+			t.Fatalf("Failed to update event: %v", err)
+		}
+		all[i] = moved
+	}
+	for i := 300; i < 400; i++ {
+		if err := repo.Delete("user1", all[i].ID); err != nil {
+			t.Fatalf("Failed to delete event: %v", err)
+		}
+	}
+	all = append(all[:300], all[400:]...)
+
+	for q := 0; q < 200; q++ {
+		start := base.Add(time.Duration(rnd.Intn(400*24)-10*24) * time.Hour)
+		end := start.Add(time.Duration(rnd.Intn(14*24)) * time.Hour)
+
+		var want []*domain.Event
+		for _, event := range all {
+			if event.Overlaps(start, end) {
+				want = append(want, event)
+			}
+		}
+
+		got, err := repo.GetByDateRange("user1", start, end)
+		if err != nil {
+			t.Fatalf("GetByDateRange failed: %v", err)
+		}
+		gotIDs, wantIDs := eventIDs(got), eventIDs(want)
+		if fmt.Sprint(gotIDs) != fmt.Sprint(wantIDs) {
+			t.Fatalf("Range [%v, %v): got %d events, want %d", start, end, len(gotIDs), len(wantIDs))
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i].Date.Before(got[i-1].Date) {
+				t.Fatalf("Expected events ordered by start")
+			}
+		}
+	}
+}
+
+func TestMemoryRepository_ArchiveMovesOutOfRange(t *testing.T) {
+	repo := NewMemoryRepository()
+	date := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	repo.Create(newTestEvent("old", date))
+	repo.Create(newTestEvent("new", date.AddDate(0, 1, 0)))
+
+	archivedNow, err := repo.ArchiveOldEvents(date.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("Failed to archive events: %v", err)
+	}
+	if ids := eventIDs(archivedNow); len(ids) != 1 || ids[0] != "old" {
+		t.Errorf("Expected archived events to be returned, got %v", ids)
+	}
+
+	events, _ := repo.GetByDateRange("user1", date, date.AddDate(1, 0, 0))
+	if ids := eventIDs(events); len(ids) != 1 || ids[0] != "new" {
+		t.Errorf("Expected only the active event in range, got %v", ids)
+	}
+	archived, _ := repo.GetArchived("user1")
+	if ids := eventIDs(archived); len(ids) != 1 || ids[0] != "old" {
+		t.Errorf("Expected archived event, got %v", ids)
+	}
+}
+
+func TestMemoryRepository_ReturnsCopies(t *testing.T) {
+	repo := NewMemoryRepository()
+	date := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	event := newTestEvent("e1", date)
+	repo.Create(event)
+
+	// Изменение события без Update не должно влиять на хранилище и индексы
+	event.Date = date.AddDate(0, 1, 0)
+	stored, _ := repo.GetByID("user1", "e1")
+	stored.Text = "Changed"
+
+	events, _ := repo.GetByDateRange("user1", date, date.Add(time.Hour))
+	if len(events) != 1 || events[0].Text != "Event e1" || !events[0].Date.Equal(date) {
+		t.Errorf("Expected stored event to be unchanged, got %+v", events)
+	}
+}
+
+const (
+	benchUsers         = 1000
+	benchEventsPerUser = 1000
+)
+
+var (
+	benchOnce   sync.Once
+	benchRepo   *MemoryRepository
+	benchEvents []*domain.Event
+	benchBase   = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// benchFixture создает репозиторий с 1M событий: 1000 пользователей по 1000 событий за год
+func benchFixture(b *testing.B) (*MemoryRepository, []*domain.Event) {
+	b.Helper()
+	benchOnce.Do(func() {
+		rnd := rand.New(rand.NewSource(1))
+		benchRepo = NewMemoryRepository()
+		benchEvents = make([]*domain.Event, 0, benchUsers*benchEventsPerUser)
+		for u := 0; u < benchUsers; u++ {
+			userID := fmt.Sprintf("user%d", u)
+			for i := 0; i < benchEventsPerUser; i++ {
+				event := randomEvent(rnd, userID, fmt.Sprintf("%s-e%d", userID, i), benchBase)
+				benchRepo.Create(event)
+				benchEvents = append(benchEvents, event)
+			}
+		}
+	})
+	return benchRepo, benchEvents
+}
+
+// BenchmarkGetByDateRange - недельная выборка одного пользователя из 1M событий
+func BenchmarkGetByDateRange(b *testing.B) {
+	repo, _ := benchFixture(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		start := benchBase.AddDate(0, 0, i%358)
+		repo.GetByDateRange(fmt.Sprintf("user%d", i%benchUsers), start, start.AddDate(0, 0, 7))
+	}
+}
+
+// BenchmarkGetByDateRange_FullScan - та же выборка полным перебором,
+// как в реализации до индексов по пользователям
+func BenchmarkGetByDateRange_FullScan(b *testing.B) {
+	_, events := benchFixture(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		userID := fmt.Sprintf("user%d", i%benchUsers)
+		start := benchBase.AddDate(0, 0, i%358)
+		end := start.AddDate(0, 0, 7)
+		var result []*domain.Event
+		for _, event := range events {
+			if event.UserID == userID && !event.Archived && event.Overlaps(start, end) {
+				result = append(result, event)
+			}
+		}
+	}
+}
+
+// BenchmarkGetAllActive - все активные события одного пользователя из 1M событий
+func BenchmarkGetAllActive(b *testing.B) {
+	repo, _ := benchFixture(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		repo.GetAllActive(fmt.Sprintf("user%d", i%benchUsers))
+	}
+}
+
+// BenchmarkCreate - добавление события в репозиторий с 1M событий
+func BenchmarkCreate(b *testing.B) {
+	repo, _ := benchFixture(b)
+	rnd := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		userID := fmt.Sprintf("user%d", i%benchUsers)
+		event := randomEvent(rnd, userID, fmt.Sprintf("bench-create-%d", i), benchBase)
+		repo.Create(event)
+		b.StopTimer()
+		repo.Delete(userID, event.ID)
+		b.StartTimer()
+	}
+}
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const sharesFileName = "shares.json"
+
+// MemoryShareRepository хранит списки доступа к календарям в памяти
+type MemoryShareRepository struct {
+	mu     sync.RWMutex
+	shares map[string]*domain.Share // ключ: ownerID:granteeID
+}
+
+// NewMemoryShareRepository создает новое хранилище списков доступа
+func NewMemoryShareRepository() *MemoryShareRepository {
+	return &MemoryShareRepository{
+		shares: make(map[string]*domain.Share),
+	}
+}
+
+func shareKey(ownerID, granteeID string) string {
+	return ownerID + ":" + granteeID
+}
+
+// Save создает запись доступа или заменяет существующую
+func (r *MemoryShareRepository) Save(share *domain.Share) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	copied := *share
+	r.shares[shareKey(share.OwnerID, share.GranteeID)] = &copied
+	return nil
+}
+
+// Get получает запись доступа пользователя к календарю владельца
+func (r *MemoryShareRepository) Get(ownerID, granteeID string) (*domain.Share, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	share, exists := r.shares[shareKey(ownerID, granteeID)]
+	if !exists {
+		return nil, domain.ErrShareNotFound
+	}
+	copied := *share
+	return &copied, nil
+}
+
+// Delete удаляет запись доступа
+func (r *MemoryShareRepository) Delete(ownerID, granteeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := shareKey(ownerID, granteeID)
+	if _, exists := r.shares[key]; !exists {
+		return domain.ErrShareNotFound
+	}
+	delete(r.shares, key)
+	return nil
+}
+
+// ListByOwner возвращает записи доступа к календарю владельца по пользователям
+func (r *MemoryShareRepository) ListByOwner(ownerID string) ([]*domain.Share, error) {
+	return r.list(func(share *domain.Share) bool { return share.OwnerID == ownerID }), nil
+}
+
+// ListByGrantee возвращает календари, открытые пользователю, по владельцам
+func (r *MemoryShareRepository) ListByGrantee(granteeID string) ([]*domain.Share, error) {
+	return r.list(func(share *domain.Share) bool { return share.GranteeID == granteeID }), nil
+}
+
+func (r *MemoryShareRepository) list(match func(*domain.Share) bool) []*domain.Share {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.Share
+	for _, share := range r.shares {
+		if match(share) {
+			copied := *share
+			result = append(result, &copied)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return shareKey(result[i].OwnerID, result[i].GranteeID) < shareKey(result[j].OwnerID, result[j].GranteeID)
+	})
+	return result
+}
+
+// FileShareRepository хранит списки доступа в JSON-файле.
+// Доступ меняется редко, поэтому файл перезаписывается целиком.
+type FileShareRepository struct {
+	*MemoryShareRepository
+	path string
+}
+
+// NewFileShareRepository открывает хранилище списков доступа в директории dir
+func NewFileShareRepository(dir string) (*FileShareRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileShareRepository{
+		MemoryShareRepository: NewMemoryShareRepository(),
+		path:                  filepath.Join(dir, sharesFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read shares: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.shares); err != nil {
+		return nil, fmt.Errorf("decode shares: %w", err)
+	}
+	return r, nil
+}
+
+// Save сохраняет запись доступа и записывает файл
+func (r *FileShareRepository) Save(share *domain.Share) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := shareKey(share.OwnerID, share.GranteeID)
+	previous, existed := r.shares[key]
+	copied := *share
+	r.shares[key] = &copied
+	if err := writeJSONFile(r.path, r.shares); err != nil {
+		if existed {
+			r.shares[key] = previous
+		} else {
+			delete(r.shares, key)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет запись доступа и записывает файл
+func (r *FileShareRepository) Delete(ownerID, granteeID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := shareKey(ownerID, granteeID)
+	share, exists := r.shares[key]
+	if !exists {
+		return domain.ErrShareNotFound
+	}
+	delete(r.shares, key)
+	if err := writeJSONFile(r.path, r.shares); err != nil {
+		r.shares[key] = share
+		return err
+	}
+	return nil
+}
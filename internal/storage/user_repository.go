@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const usersFileName = "users.json"
+
+// MemoryUserRepository хранит настройки пользователей в памяти
+type MemoryUserRepository struct {
+	mu    sync.RWMutex
+	users map[string]*domain.User
+}
+
+// NewMemoryUserRepository создает новый репозиторий пользователей в памяти
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users: make(map[string]*domain.User),
+	}
+}
+
+// Get получает настройки пользователя
+func (r *MemoryUserRepository) Get(userID string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
+
+	return user.Clone(), nil
+}
+
+// Save создает или обновляет настройки пользователя
+func (r *MemoryUserRepository) Save(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[user.ID] = user.Clone()
+	return nil
+}
+
+// FileUserRepository хранит настройки пользователей в JSON-файле.
+// Настроек немного и они меняются редко, поэтому файл перезаписывается целиком.
+type FileUserRepository struct {
+	*MemoryUserRepository
+	path string
+}
+
+// NewFileUserRepository открывает репозиторий пользователей в директории dir
+func NewFileUserRepository(dir string) (*FileUserRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileUserRepository{
+		MemoryUserRepository: NewMemoryUserRepository(),
+		path:                 filepath.Join(dir, usersFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read users: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.users); err != nil {
+		return nil, fmt.Errorf("decode users: %w", err)
+	}
+	return r, nil
+}
+
+// Save создает или обновляет настройки пользователя и сохраняет файл
+func (r *FileUserRepository) Save(user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	previous, existed := r.users[user.ID]
+	r.users[user.ID] = user.Clone()
+
+	if err := writeJSONFile(r.path, r.users); err != nil {
+		// Откатить изменение, чтобы память не расходилась с диском
+		if existed {
+			r.users[user.ID] = previous
+		} else {
+			delete(r.users, user.ID)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+const webhooksFileName = "webhooks.json"
+
+// maxWebhookDeliveries - число последних попыток доставки, хранимых для каждой подписки
+const maxWebhookDeliveries = 100
+
+// MemoryWebhookRepository хранит подписки на webhook и историю доставки в памяти
+type MemoryWebhookRepository struct {
+	mu         sync.RWMutex
+	subs       map[string]*domain.WebhookSubscription // ключ: ID подписки
+	deliveries map[string][]*domain.WebhookDelivery   // ключ: ID подписки, от старых к новым
+}
+
+// NewMemoryWebhookRepository создает новое хранилище подписок на webhook
+func NewMemoryWebhookRepository() *MemoryWebhookRepository {
+	return &MemoryWebhookRepository{
+		subs:       make(map[string]*domain.WebhookSubscription),
+		deliveries: make(map[string][]*domain.WebhookDelivery),
+	}
+}
+
+// Create сохраняет новую подписку
+func (r *MemoryWebhookRepository) Create(sub *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subs[sub.ID] = sub.Clone()
+	return nil
+}
+
+// Get получает подписку пользователя по ID
+func (r *MemoryWebhookRepository) Get(userID, id string) (*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	sub, exists := r.subs[id]
+	if !exists || sub.UserID != userID {
+		return nil, domain.ErrWebhookNotFound
+	}
+	return sub.Clone(), nil
+}
+
+// List возвращает подписки пользователя в порядке создания
+func (r *MemoryWebhookRepository) List(userID string) ([]*domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var result []*domain.WebhookSubscription
+	for _, sub := range r.subs {
+		if sub.UserID == userID {
+			result = append(result, sub.Clone())
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result, nil
+}
+
+// Delete удаляет подписку вместе с историей доставки
+func (r *MemoryWebhookRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, exists := r.subs[id]
+	if !exists || sub.UserID != userID {
+		return domain.ErrWebhookNotFound
+	}
+	delete(r.subs, id)
+	delete(r.deliveries, id)
+	return nil
+}
+
+// AddDelivery добавляет попытку доставки в историю подписки,
+// отбрасывая самые старые записи сверх maxWebhookDeliveries
+func (r *MemoryWebhookRepository) AddDelivery(delivery *domain.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.subs[delivery.SubscriptionID]; !exists {
+		return domain.ErrWebhookNotFound
+	}
+	copied := *delivery
+	history := append(r.deliveries[delivery.SubscriptionID], &copied)
+	if len(history) > maxWebhookDeliveries {
+		history = append([]*domain.WebhookDelivery(nil), history[len(history)-maxWebhookDeliveries:]...)
+	}
+	r.deliveries[delivery.SubscriptionID] = history
+	return nil
+}
+
+// Deliveries возвращает историю доставки подписки, начиная с последних попыток
+func (r *MemoryWebhookRepository) Deliveries(subscriptionID string) ([]*domain.WebhookDelivery, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	history := r.deliveries[subscriptionID]
+	result := make([]*domain.WebhookDelivery, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		copied := *history[i]
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// FileWebhookRepository хранит подписки на webhook в JSON-файле.
+// История доставки нужна только для отладки и хранится в памяти.
+type FileWebhookRepository struct {
+	*MemoryWebhookRepository
+	path string
+}
+
+// NewFileWebhookRepository открывает хранилище подписок на webhook в директории dir
+func NewFileWebhookRepository(dir string) (*FileWebhookRepository, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+
+	r := &FileWebhookRepository{
+		MemoryWebhookRepository: NewMemoryWebhookRepository(),
+		path:                    filepath.Join(dir, webhooksFileName),
+	}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read webhooks: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.subs); err != nil {
+		return nil, fmt.Errorf("decode webhooks: %w", err)
+	}
+	return r, nil
+}
+
+// Create сохраняет новую подписку и записывает файл
+func (r *FileWebhookRepository) Create(sub *domain.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.subs[sub.ID] = sub.Clone()
+	if err := writeJSONFile(r.path, r.subs); err != nil {
+		delete(r.subs, sub.ID)
+		return err
+	}
+	return nil
+}
+
+// Delete удаляет подписку и записывает файл
+func (r *FileWebhookRepository) Delete(userID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	sub, exists := r.subs[id]
+	if !exists || sub.UserID != userID {
+		return domain.ErrWebhookNotFound
+	}
+	delete(r.subs, id)
+	if err := writeJSONFile(r.path, r.subs); err != nil {
+		r.subs[id] = sub
+		return err
+	}
+	delete(r.deliveries, id)
+	return nil
+}
@@ -0,0 +1,100 @@
+// Package stream рассылает изменения календарей подключенным клиентам
+package stream
+
+import (
+	"sync"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// DefaultBuffer - число изменений, которые подписка накапливает, пока клиент их не прочитал
+const DefaultBuffer = 64
+
+// Hub рассылает изменения подпискам пользователя. Notify не блокируется:
+// подписка, не успевающая читать изменения, закрывается, и клиент
+// переподключается, заново загрузив календарь.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[string]map[*Subscription]struct{} // ключ: userID
+	buffer int
+}
+
+// Subscription - подписка на изменения календаря одного пользователя
+type Subscription struct {
+	// C получает изменения; канал закрывается при отключении подписки
+	C <-chan *domain.Change
+
+	ch     chan *domain.Change
+	hub    *Hub
+	userID string
+}
+
+// NewHub создает хаб с буфером buffer изменений на подписку
+func NewHub(buffer int) *Hub {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		subs:   make(map[string]map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscribe подписывает на изменения календаря пользователя
+func (h *Hub) Subscribe(userID string) *Subscription {
+	ch := make(chan *domain.Change, h.buffer)
+	sub := &Subscription{C: ch, ch: ch, hub: h, userID: userID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = make(map[*Subscription]struct{})
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+// Close отключает подписку. Повторный вызов ничего не делает.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Notify рассылает изменение подпискам пользователя
+func (h *Hub) Notify(change *domain.Change) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[change.UserID] {
+		select {
+		case sub.ch <- change:
+		default:
+			// Клиент отстал: пропущенные изменения не восстановить
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribers возвращает число подписок пользователя
+func (h *Hub) Subscribers(userID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs[userID])
+}
+
+// remove удаляет подписку и закрывает ее канал. Вызывается под блокировкой.
+func (h *Hub) remove(sub *Subscription) {
+	subs, ok := h.subs[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(h.subs, sub.userID)
+	}
+}
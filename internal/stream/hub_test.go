@@ -0,0 +1,61 @@
+package stream
+
+import (
+	"testing"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+func TestHub_Notify(t *testing.T) {
+	hub := NewHub(2)
+	first := hub.Subscribe("user1")
+	second := hub.Subscribe("user1")
+	other := hub.Subscribe("user2")
+
+	hub.Notify(&domain.Change{Type: domain.ChangeCreated, UserID: "user1", EventID: "e1"})
+
+	for _, sub := range []*Subscription{first, second} {
+		select {
+		case change := <-sub.C:
+			if change.EventID != "e1" {
+				t.Errorf("Unexpected change %+v", change)
+			}
+		default:
+			t.Errorf("Expected change for every subscription of the user")
+		}
+	}
+	select {
+	case change := <-other.C:
+		t.Errorf("Change of user1 delivered to user2: %+v", change)
+	default:
+	}
+
+	second.Close()
+	second.Close()
+	if _, ok := <-second.C; ok {
+		t.Errorf("Expected closed channel after Close")
+	}
+	if n := hub.Subscribers("user1"); n != 1 {
+		t.Errorf("Expected 1 subscriber, got %d", n)
+	}
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := NewHub(1)
+	sub := hub.Subscribe("user1")
+
+	hub.Notify(&domain.Change{Type: domain.ChangeCreated, UserID: "user1", EventID: "e1"})
+	hub.Notify(&domain.Change{Type: domain.ChangeUpdated, UserID: "user1", EventID: "e1"})
+
+	// Накопленное изменение читается, затем канал закрыт
+	if change, ok := <-sub.C; !ok || change.Type != domain.ChangeCreated {
+		t.Fatalf("Expected buffered change, got %+v", change)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Errorf("Expected subscription to be closed after overflow")
+	}
+	if n := hub.Subscribers("user1"); n != 0 {
+		t.Errorf("Expected no subscribers, got %d", n)
+	}
+	sub.Close()
+}
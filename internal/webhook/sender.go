@@ -0,0 +1,122 @@
+// Package webhook отправляет изменения событий на адреса подписок внешних систем
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/reminder"
+)
+
+const (
+	// EventHeader содержит тип изменения: created, updated, deleted или archived
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader содержит ID сообщения, одинаковый во всех попытках доставки
+	DeliveryHeader = "X-Webhook-Delivery"
+)
+
+// HTTPSender отправляет сообщения POST-запросом на адрес подписки.
+// Тело подписывается секретом подписки так же, как напоминания канала webhook.
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender создает новый отправитель webhook с таймаутом запроса timeout
+func NewHTTPSender(timeout time.Duration) *HTTPSender {
+	return &HTTPSender{client: &http.Client{Timeout: timeout}}
+}
+
+// Payload - тело запроса с изменением события
+type Payload struct {
+	ID         string        `json:"id"`
+	Type       string        `json:"type"`
+	UserID     string        `json:"user_id"`
+	EventID    string        `json:"event_id"`
+	OccurredAt time.Time     `json:"occurred_at"`
+	Event      *EventPayload `json:"event,omitempty"`
+}
+
+// EventPayload - событие после изменения; у удаленных событий отсутствует
+type EventPayload struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	UID       string    `json:"uid,omitempty"`
+	Text      string    `json:"text"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	AllDay    bool      `json:"all_day"`
+	TimeZone  string    `json:"time_zone,omitempty"`
+	RRule     string    `json:"rrule,omitempty"`
+	SeriesID  string    `json:"series_id,omitempty"`
+	Archived  bool      `json:"archived"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SendWebhook отправляет сообщение и возвращает статус ответа.
+// Ответ вне диапазона 2xx считается ошибкой.
+func (s *HTTPSender) SendWebhook(sub *domain.WebhookSubscription, msg *domain.WebhookMessage) (int, error) {
+	body, err := json.Marshal(newPayload(msg))
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(msg.Change.Type))
+	req.Header.Set(DeliveryHeader, msg.ID)
+	req.Header.Set(reminder.TimestampHeader, timestamp)
+	req.Header.Set(reminder.SignatureHeader, reminder.Sign([]byte(sub.Secret), timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	// Дочитать ответ, чтобы соединение вернулось в пул
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("send webhook: unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func newPayload(msg *domain.WebhookMessage) Payload {
+	change := msg.Change
+	payload := Payload{
+		ID:         msg.ID,
+		Type:       string(change.Type),
+		UserID:     change.UserID,
+		EventID:    change.EventID,
+		OccurredAt: change.At,
+	}
+	if e := change.Event; e != nil {
+		payload.Event = &EventPayload{
+			ID:        e.ID,
+			UserID:    e.UserID,
+			UID:       e.UID,
+			Text:      e.Text,
+			Start:     e.Date,
+			End:       e.EndTime(),
+			AllDay:    e.AllDay,
+			TimeZone:  e.TimeZone,
+			SeriesID:  e.SeriesID,
+			Archived:  e.Archived,
+			UpdatedAt: e.UpdatedAt,
+		}
+		if e.Recurrence != nil {
+			payload.Event.RRule = e.Recurrence.String()
+		}
+	}
+	return payload
+}
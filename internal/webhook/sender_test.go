@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/reminder"
+)
+
+func TestHTTPSender_SignsPayload(t *testing.T) {
+	received := make(chan Payload, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		expected := reminder.Sign([]byte("sub-secret"), r.Header.Get(reminder.TimestampHeader), body)
+		if r.Header.Get(reminder.SignatureHeader) != expected {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventHeader) != "created" || r.Header.Get(DeliveryHeader) != "m1" {
+			http.Error(w, "bad headers", http.StatusBadRequest)
+			return
+		}
+		var payload Payload
+		json.Unmarshal(body, &payload)
+		received <- payload
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sub := &domain.WebhookSubscription{ID: "s1", UserID: "user1", URL: server.URL, Secret: "sub-secret"}
+	msg := &domain.WebhookMessage{ID: "m1", SubscriptionID: "s1", Change: &domain.Change{
+		Type:    domain.ChangeCreated,
+		UserID:  "user1",
+		EventID: "e1",
+		Event:   &domain.Event{ID: "e1", UserID: "user1", Text: "Meeting", Date: time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)},
+	}}
+
+	status, err := NewHTTPSender(time.Second).SendWebhook(sub, msg)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Expected 204 without error, got %d %v", status, err)
+	}
+	payload := <-received
+	if payload.ID != "m1" || payload.EventID != "e1" || payload.Event == nil || payload.Event.Text != "Meeting" {
+		t.Errorf("Unexpected payload %+v", payload)
+	}
+}
+
+func TestHTTPSender_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	sub := &domain.WebhookSubscription{ID: "s1", UserID: "user1", URL: server.URL}
+	msg := &domain.WebhookMessage{ID: "m1", Change: &domain.Change{Type: domain.ChangeDeleted, UserID: "user1", EventID: "e1"}}
+	status, err := NewHTTPSender(time.Second).SendWebhook(sub, msg)
+	if err == nil || status != http.StatusBadGateway {
+		t.Errorf("Expected error with status 502, got %d %v", status, err)
+	}
+}
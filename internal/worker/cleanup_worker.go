@@ -7,9 +7,14 @@ import (
 	"github.com/oziev02/event-calendar-service/pkg/logger"
 )
 
+// Archiver архивирует события, закончившиеся раньше before
+type Archiver interface {
+	ArchiveOldEvents(before time.Time) ([]*domain.Event, error)
+}
+
 // CleanupWorker архивирует старые события
 type CleanupWorker struct {
-	repo         domain.EventRepository
+	archiver     Archiver
 	logger       logger.Logger
 	interval     time.Duration
 	archiveAfter time.Duration
@@ -18,13 +23,13 @@ type CleanupWorker struct {
 
 // NewCleanupWorker создает новый воркер очистки
 func NewCleanupWorker(
-	repo domain.EventRepository,
+	archiver Archiver,
 	log logger.Logger,
 	interval time.Duration,
 	archiveAfter time.Duration,
 ) *CleanupWorker {
 	return &CleanupWorker{
-		repo:         repo,
+		archiver:     archiver,
 		logger:       log,
 		interval:     interval,
 		archiveAfter: archiveAfter,
@@ -63,13 +68,14 @@ func (w *CleanupWorker) process() {
 // archiveOldEvents архивирует события старше archiveAfter
 func (w *CleanupWorker) archiveOldEvents() {
 	cutoff := time.Now().Add(-w.archiveAfter)
-	if err := w.repo.ArchiveOldEvents(cutoff); err != nil {
+	if archived, err := w.archiver.ArchiveOldEvents(cutoff); err != nil {
 		w.logger.Log(logger.LevelError, "Failed to archive old events", map[string]interface{}{
 			"error": err.Error(),
 		})
 	} else {
 		w.logger.Log(logger.LevelInfo, "Archived old events", map[string]interface{}{
-			"cutoff": cutoff,
+			"cutoff":   cutoff,
+			"archived": len(archived),
 		})
 	}
 }
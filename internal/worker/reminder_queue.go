@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// reminderItem - элемент очереди; index нужен для удаления отмененных напоминаний.
+// due совпадает со временем напоминания, а для повторных попыток сдвигается.
+type reminderItem struct {
+	task  *domain.ReminderTask
+	due   time.Time
+	index int
+}
+
+// reminderQueue - очередь напоминаний с минимальным временем в вершине (container/heap)
+type reminderQueue []*reminderItem
+
+func (q reminderQueue) Len() int { return len(q) }
+
+func (q reminderQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q reminderQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *reminderQueue) Push(x interface{}) {
+	item := x.(*reminderItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *reminderQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
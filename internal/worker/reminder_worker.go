@@ -1,37 +1,62 @@
 package worker
 
 import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/oziev02/event-calendar-service/internal/domain"
 	"github.com/oziev02/event-calendar-service/pkg/logger"
 )
 
-// ReminderWorker обрабатывает задачи напоминаний
+// ReminderWorker хранит запланированные напоминания в куче по времени отправки
+// и ждет ближайшее из них на одном таймере
 type ReminderWorker struct {
-	taskChan     chan *domain.ReminderTask
-	sender       domain.ReminderSender
-	logger       logger.Logger
+	mu            sync.Mutex
+	queue         reminderQueue
+	byEvent       map[string][]*reminderItem // ключ: eventID
+	wake          chan struct{}
+	sender        domain.ReminderSender
+	logger        logger.Logger
 	checkInterval time.Duration
-	done         chan struct{}
+	followUp      func(task *domain.ReminderTask) *domain.ReminderTask
+	retry         RetryPolicy
+	deadLetters   domain.DeadLetterRepository
+	done          chan struct{}
 }
 
 // NewReminderWorker создает новый воркер напоминаний
 func NewReminderWorker(
-	taskChan chan *domain.ReminderTask,
 	sender domain.ReminderSender,
 	log logger.Logger,
 	checkInterval time.Duration,
 ) *ReminderWorker {
 	return &ReminderWorker{
-		taskChan:      taskChan,
+		byEvent:       make(map[string][]*reminderItem),
+		wake:          make(chan struct{}, 1),
 		sender:        sender,
 		logger:        log,
 		checkInterval: checkInterval,
+		retry:         RetryPolicy{MaxAttempts: 1},
 		done:          make(chan struct{}),
 	}
 }
 
+// SetFollowUp задает функцию, возвращающую следующее напоминание после
+// отправленного (например, для следующего вхождения серии). Вызывается до Start.
+func (w *ReminderWorker) SetFollowUp(fn func(task *domain.ReminderTask) *domain.ReminderTask) {
+	w.followUp = fn
+}
+
+// SetRetryPolicy задает повторные попытки доставки и хранилище напоминаний,
+// исчерпавших попытки. Вызывается до Start.
+func (w *ReminderWorker) SetRetryPolicy(policy RetryPolicy, deadLetters domain.DeadLetterRepository) {
+	w.retry = policy
+	w.deadLetters = deadLetters
+}
+
 // Start запускает воркер напоминаний
 func (w *ReminderWorker) Start() {
 	go w.process()
@@ -42,41 +67,191 @@ func (w *ReminderWorker) Stop() {
 	close(w.done)
 }
 
-// process обрабатывает задачи напоминаний
+// Schedule добавляет напоминание в очередь. Просроченное напоминание
+// отправляется при ближайшем пробуждении воркера.
+func (w *ReminderWorker) Schedule(task *domain.ReminderTask) {
+	// Разбудить воркер, только если изменилось ближайшее время
+	if w.push(task, task.Time) {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// push добавляет напоминание в очередь со временем due и сообщает, стало ли оно ближайшим
+func (w *ReminderWorker) push(task *domain.ReminderTask, due time.Time) bool {
+	item := &reminderItem{task: task, due: due}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	heap.Push(&w.queue, item)
+	w.byEvent[task.EventID] = append(w.byEvent[task.EventID], item)
+	return w.queue[0] == item
+}
+
+// scheduled проверяет, есть ли в очереди то же напоминание события для того же
+// получателя (например, уже запланированное после повторно отправленного dead letter)
+func (w *ReminderWorker) scheduled(task *domain.ReminderTask) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, item := range w.byEvent[task.EventID] {
+		if item.task.Index == task.Index && item.task.UserID == task.UserID && item.task.Time.Equal(task.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// Cancel удаляет из очереди все напоминания события
+func (w *ReminderWorker) Cancel(eventID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, item := range w.byEvent[eventID] {
+		heap.Remove(&w.queue, item.index)
+	}
+	delete(w.byEvent, eventID)
+}
+
+// Pending возвращает число запланированных напоминаний
+func (w *ReminderWorker) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return len(w.queue)
+}
+
+// process ждет ближайшее напоминание и отправляет наступившие
 func (w *ReminderWorker) process() {
 	ticker := time.NewTicker(w.checkInterval)
 	defer ticker.Stop()
 
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
 	for {
 		select {
-		case task := <-w.taskChan:
-			now := time.Now()
-			if task.Time.Before(now) || task.Time.Equal(now) {
-				if err := w.sender.SendReminder(task); err != nil {
-					w.logger.Log(logger.LevelError, "Failed to send reminder", map[string]interface{}{
-						"error":   err.Error(),
-						"event_id": task.EventID,
-					})
-				}
-			} else {
-				// Переназначить на позже
-				go w.scheduleReminder(task)
-			}
+		case <-timer.C:
+		case <-w.wake:
 		case <-ticker.C:
-			// Периодическая проверка просроченных напоминаний
+			// Периодическая проверка на случай перевода системных часов
 		case <-w.done:
 			return
 		}
+
+		for _, task := range w.popDue(time.Now()) {
+			w.send(task)
+			// Следующее напоминание планируется один раз, а не после каждой попытки
+			if w.followUp != nil && task.Attempt == 0 {
+				if next := w.followUp(task); next != nil && !w.scheduled(next) {
+					w.push(next, next.Time)
+				}
+			}
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if next, ok := w.next(); ok {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// popDue извлекает из очереди напоминания, время которых наступило
+func (w *ReminderWorker) popDue(now time.Time) []*domain.ReminderTask {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var due []*domain.ReminderTask
+	for len(w.queue) > 0 && !w.queue[0].due.After(now) {
+		item := heap.Pop(&w.queue).(*reminderItem)
+		w.forget(item)
+		due = append(due, item.task)
+	}
+	return due
+}
+
+// next возвращает время ближайшего напоминания
+func (w *ReminderWorker) next() (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.queue) == 0 {
+		return time.Time{}, false
 	}
+	return w.queue[0].due, true
 }
 
-// scheduleReminder планирует напоминание для проверки позже
-func (w *ReminderWorker) scheduleReminder(task *domain.ReminderTask) {
-	now := time.Now()
-	delay := task.Time.Sub(now)
-	if delay > 0 {
-		time.Sleep(delay)
-		w.taskChan <- task
+// forget удаляет извлеченный элемент из индекса по событиям
+func (w *ReminderWorker) forget(item *reminderItem) {
+	eventID := item.task.EventID
+	items := w.byEvent[eventID]
+	for i, it := range items {
+		if it == item {
+			items = append(items[:i], items[i+1:]...)
+			break
+		}
+	}
+	if len(items) == 0 {
+		delete(w.byEvent, eventID)
+	} else {
+		w.byEvent[eventID] = items
 	}
 }
 
+// send отправляет напоминание. При ошибке напоминание возвращается в очередь
+// с задержкой, а после последней попытки переносится в dead-letter хранилище.
+// Если известны каналы, в которые доставить не удалось, повторяется только их доставка.
+func (w *ReminderWorker) send(task *domain.ReminderTask) {
+	err := w.sender.SendReminder(task)
+	if err == nil {
+		return
+	}
+
+	attempt := task.Attempt + 1
+	fields := map[string]interface{}{
+		"error":    err.Error(),
+		"event_id": task.EventID,
+		"attempt":  attempt,
+	}
+
+	// Повторяется доставка только в каналы, которые не получили напоминание
+	failed := *task
+	var channelErr *domain.ChannelDeliveryError
+	if errors.As(err, &channelErr) {
+		failed.Channels = channelErr.Failed
+		fields["channels"] = channelErr.Failed
+	}
+
+	if attempt < w.retry.MaxAttempts {
+		failed.Attempt = attempt
+		w.push(&failed, time.Now().Add(w.retry.Backoff(attempt)))
+		w.logger.Log(logger.LevelError, "Failed to send reminder, will retry", fields)
+		return
+	}
+
+	w.logger.Log(logger.LevelError, "Failed to send reminder", fields)
+	if w.deadLetters == nil {
+		return
+	}
+	failedAt := time.Now()
+	letter := &domain.DeadLetter{
+		ID:        fmt.Sprintf("%s-%d", task.EventID, failedAt.UnixNano()),
+		Task:      failed,
+		Attempts:  attempt,
+		LastError: err.Error(),
+		FailedAt:  failedAt,
+	}
+	if err := w.deadLetters.Add(letter); err != nil {
+		w.logger.Log(logger.LevelError, "Failed to store dead letter", map[string]interface{}{
+			"error":    err.Error(),
+			"event_id": task.EventID,
+		})
+	}
+}
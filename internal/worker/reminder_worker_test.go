@@ -0,0 +1,238 @@
+package worker
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+type nopLogger struct{}
+
+func (nopLogger) Log(logger.LogLevel, string, map[string]interface{}) {}
+
+func (nopLogger) Close() error { return nil }
+
+func newTestLogger() logger.Logger { return nopLogger{} }
+
+type chanSender chan *domain.ReminderTask
+
+func (c chanSender) SendReminder(task *domain.ReminderTask) error {
+	c <- task
+	return nil
+}
+
+func TestReminderWorker_DeliversInTimeOrder(t *testing.T) {
+	sent := make(chanSender, 10)
+	w := NewReminderWorker(sent, newTestLogger(), time.Hour)
+	w.Start()
+	defer w.Stop()
+
+	now := time.Now()
+	w.Schedule(&domain.ReminderTask{EventID: "late", Time: now.Add(60 * time.Millisecond)})
+	w.Schedule(&domain.ReminderTask{EventID: "early", Time: now.Add(20 * time.Millisecond)})
+	w.Schedule(&domain.ReminderTask{EventID: "overdue", Time: now.Add(-time.Minute)})
+
+	for _, want := range []string{"overdue", "early", "late"} {
+		select {
+		case task := <-sent:
+			if task.EventID != want {
+				t.Errorf("Expected reminder %s, got %s", want, task.EventID)
+			}
+			if time.Now().Before(task.Time) {
+				t.Errorf("Reminder %s sent before its time", task.EventID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Reminder %s was not sent", want)
+		}
+	}
+	if w.Pending() != 0 {
+		t.Errorf("Expected empty queue, got %d", w.Pending())
+	}
+}
+
+func TestReminderWorker_FutureReminderWaits(t *testing.T) {
+	sent := make(chanSender, 1)
+	w := NewReminderWorker(sent, newTestLogger(), time.Hour)
+	w.Start()
+	defer w.Stop()
+
+	w.Schedule(&domain.ReminderTask{EventID: "e1", Time: time.Now().Add(time.Hour)})
+
+	select {
+	case task := <-sent:
+		t.Fatalf("Unexpected reminder %s", task.EventID)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if w.Pending() != 1 {
+		t.Errorf("Expected 1 pending reminder, got %d", w.Pending())
+	}
+}
+
+func TestReminderWorker_Cancel(t *testing.T) {
+	sent := make(chanSender, 10)
+	w := NewReminderWorker(sent, newTestLogger(), time.Hour)
+	w.Start()
+	defer w.Stop()
+
+	now := time.Now()
+	w.Schedule(&domain.ReminderTask{EventID: "deleted", Time: now.Add(20 * time.Millisecond)})
+	w.Schedule(&domain.ReminderTask{EventID: "deleted", Time: now.Add(30 * time.Millisecond)})
+	w.Schedule(&domain.ReminderTask{EventID: "kept", Time: now.Add(40 * time.Millisecond)})
+	w.Cancel("deleted")
+
+	select {
+	case task := <-sent:
+		if task.EventID != "kept" {
+			t.Errorf("Expected reminder %s, got %s", "kept", task.EventID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reminder was not sent")
+	}
+
+	select {
+	case task := <-sent:
+		t.Errorf("Unexpected reminder %s", task.EventID)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// flakySender отклоняет первые failures попыток
+type flakySender struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     chan *domain.ReminderTask
+}
+
+func (s *flakySender) SendReminder(task *domain.ReminderTask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.attempts <= s.failures {
+		return errors.New("temporary failure")
+	}
+	s.sent <- task
+	return nil
+}
+
+func TestReminderWorker_RetriesWithBackoff(t *testing.T) {
+	sender := &flakySender{failures: 2, sent: make(chan *domain.ReminderTask, 1)}
+	deadLetters := storage.NewMemoryDeadLetterRepository()
+
+	w := NewReminderWorker(sender, newTestLogger(), time.Hour)
+	w.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond}, deadLetters)
+	w.Start()
+	defer w.Stop()
+
+	w.Schedule(&domain.ReminderTask{EventID: "e1", Time: time.Now()})
+
+	select {
+	case task := <-sender.sent:
+		if task.Attempt != 2 {
+			t.Errorf("Expected delivery on attempt 2, got %d", task.Attempt)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reminder was not delivered after retries")
+	}
+
+	letters, _ := deadLetters.List()
+	if len(letters) != 0 {
+		t.Errorf("Expected no dead letters, got %d", len(letters))
+	}
+}
+
+func TestReminderWorker_DeadLetterAfterLastAttempt(t *testing.T) {
+	sender := &flakySender{failures: 100, sent: make(chan *domain.ReminderTask, 1)}
+	deadLetters := storage.NewMemoryDeadLetterRepository()
+
+	w := NewReminderWorker(sender, newTestLogger(), time.Hour)
+	w.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, deadLetters)
+	w.Start()
+	defer w.Stop()
+
+	w.Schedule(&domain.ReminderTask{EventID: "e1", Time: time.Now()})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		letters, _ := deadLetters.List()
+		if len(letters) == 1 {
+			if letters[0].Attempts != 3 || letters[0].Task.EventID != "e1" {
+				t.Errorf("Unexpected dead letter %+v", letters[0])
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Reminder was not moved to dead letters")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if w.Pending() != 0 {
+		t.Errorf("Expected empty queue, got %d", w.Pending())
+	}
+}
+
+// partialSender при первой попытке доставляет напоминание во все каналы, кроме email
+type partialSender struct {
+	mu       sync.Mutex
+	attempts int
+	sent     chan *domain.ReminderTask
+}
+
+func (s *partialSender) SendReminder(task *domain.ReminderTask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.attempts == 1 {
+		return &domain.ChannelDeliveryError{Failed: []domain.ReminderChannel{domain.ChannelEmail}, Err: errors.New("smtp unavailable")}
+	}
+	s.sent <- task
+	return nil
+}
+
+func TestReminderWorker_RetriesOnlyFailedChannels(t *testing.T) {
+	sender := &partialSender{sent: make(chan *domain.ReminderTask, 1)}
+
+	w := NewReminderWorker(sender, newTestLogger(), time.Hour)
+	w.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}, storage.NewMemoryDeadLetterRepository())
+	w.Start()
+	defer w.Stop()
+
+	w.Schedule(&domain.ReminderTask{EventID: "e1", Time: time.Now()})
+
+	select {
+	case task := <-sender.sent:
+		if len(task.Channels) != 1 || task.Channels[0] != domain.ChannelEmail {
+			t.Errorf("Expected retry only to email, got %v", task.Channels)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reminder was not retried")
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	cases := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{8, 5 * time.Second, 10 * time.Second},
+	}
+	for _, c := range cases {
+		for i := 0; i < 20; i++ {
+			if d := policy.Backoff(c.attempt); d < c.min || d > c.max {
+				t.Errorf("Backoff(%d) = %v, expected between %v and %v", c.attempt, d, c.min, c.max)
+			}
+		}
+	}
+}
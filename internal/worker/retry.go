@@ -0,0 +1,31 @@
+package worker
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy задает повторные попытки доставки напоминаний и webhook
+type RetryPolicy struct {
+	MaxAttempts int           // общее число попыток, включая первую
+	BaseDelay   time.Duration // задержка перед второй попыткой
+	MaxDelay    time.Duration // верхняя граница задержки
+}
+
+// Backoff возвращает задержку перед попыткой attempt (attempt >= 1).
+// Задержка растет экспоненциально и случайно уменьшается до половины,
+// чтобы повторы после общего сбоя не приходили одновременно.
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	delay := p.BaseDelay
+	for i := 1; i < attempt && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+)
+
+// webhookItem - сообщение в очереди доставки; due сдвигается для повторных попыток
+type webhookItem struct {
+	msg    *domain.WebhookMessage
+	userID string
+	due    time.Time
+}
+
+// webhookQueue - очередь сообщений с минимальным временем в вершине (container/heap)
+type webhookQueue []*webhookItem
+
+func (q webhookQueue) Len() int { return len(q) }
+
+func (q webhookQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+
+func (q webhookQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *webhookQueue) Push(x interface{}) {
+	*q = append(*q, x.(*webhookItem))
+}
+
+func (q *webhookQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
@@ -0,0 +1,293 @@
+package worker
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/pkg/logger"
+)
+
+// webhookWorkers ограничивает число одновременно отправляемых сообщений
+const webhookWorkers = 16
+
+// WebhookWorker доставляет изменения событий по подпискам на webhook.
+// Notify только ставит сообщения в очередь, отправка идет в пуле горутин
+// с повторными попытками по RetryPolicy. Каждая попытка записывается в историю подписки.
+// Сообщения одной подписки отправляются по одному, поэтому медленный получатель
+// занимает не больше одной горутины и не задерживает остальные подписки.
+type WebhookWorker struct {
+	mu     sync.Mutex
+	queue  webhookQueue
+	seq    uint64
+	wake   chan struct{}
+	subs   domain.WebhookRepository
+	sender domain.WebhookSender
+	logger logger.Logger
+	retry  RetryPolicy
+	done   chan struct{}
+	// busy - сообщения, ждущие окончания отправки в ту же подписку; ключ: ID подписки
+	busy  map[string][]*webhookItem
+	slots chan struct{}
+}
+
+// NewWebhookWorker создает новый воркер доставки webhook
+func NewWebhookWorker(
+	subs domain.WebhookRepository,
+	sender domain.WebhookSender,
+	log logger.Logger,
+	retry RetryPolicy,
+) *WebhookWorker {
+	if retry.MaxAttempts < 1 {
+		retry.MaxAttempts = 1
+	}
+	return &WebhookWorker{
+		wake:   make(chan struct{}, 1),
+		subs:   subs,
+		sender: sender,
+		logger: log,
+		retry:  retry,
+		done:   make(chan struct{}),
+		busy:   make(map[string][]*webhookItem),
+		slots:  make(chan struct{}, webhookWorkers),
+	}
+}
+
+// Start запускает воркер доставки
+func (w *WebhookWorker) Start() {
+	go w.process()
+}
+
+// Stop останавливает воркер доставки. Недоставленные сообщения теряются.
+func (w *WebhookWorker) Stop() {
+	close(w.done)
+}
+
+// Notify ставит в очередь сообщение для каждой подписки пользователя,
+// принимающей изменения этого типа
+func (w *WebhookWorker) Notify(change *domain.Change) {
+	subs, err := w.subs.List(change.UserID)
+	if err != nil {
+		w.logger.Log(logger.LevelError, "Failed to list webhook subscriptions", map[string]interface{}{
+			"error":   err.Error(),
+			"user_id": change.UserID,
+		})
+		return
+	}
+
+	now := time.Now()
+	queued := false
+	w.mu.Lock()
+	for _, sub := range subs {
+		if !sub.Accepts(change.Type) {
+			continue
+		}
+		w.seq++
+		heap.Push(&w.queue, &webhookItem{
+			msg: &domain.WebhookMessage{
+				ID:             fmt.Sprintf("%d-%d", now.UnixNano(), w.seq),
+				SubscriptionID: sub.ID,
+				Change:         change,
+			},
+			userID: change.UserID,
+			due:    now,
+		})
+		queued = true
+	}
+	w.mu.Unlock()
+
+	if queued {
+		w.signal()
+	}
+}
+
+// Pending возвращает число сообщений в очереди, включая ждущие своей подписки
+func (w *WebhookWorker) Pending() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	pending := len(w.queue)
+	for _, items := range w.busy {
+		pending += len(items)
+	}
+	return pending
+}
+
+func (w *WebhookWorker) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// process ждет ближайшее сообщение и отправляет наступившие
+func (w *WebhookWorker) process() {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-w.wake:
+		case <-w.done:
+			return
+		}
+
+		for _, item := range w.popDue(time.Now()) {
+			w.dispatch(item)
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		if next, ok := w.next(); ok {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// dispatch передает сообщение свободной горутине пула. Если в подписку уже идет
+// отправка, сообщение ждет ее окончания. Без свободных горутин dispatch ждет,
+// пока одна из них освободится.
+func (w *WebhookWorker) dispatch(item *webhookItem) {
+	id := item.msg.SubscriptionID
+	w.mu.Lock()
+	if items, ok := w.busy[id]; ok {
+		w.busy[id] = append(items, item)
+		w.mu.Unlock()
+		return
+	}
+	w.busy[id] = nil
+	w.mu.Unlock()
+
+	select {
+	case w.slots <- struct{}{}:
+	case <-w.done:
+		return
+	}
+	go w.run(item)
+}
+
+// run отправляет сообщение и следующие за ним сообщения той же подписки
+func (w *WebhookWorker) run(item *webhookItem) {
+	defer func() { <-w.slots }()
+	for item != nil {
+		w.deliver(item)
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+		item = w.nextFor(item.msg.SubscriptionID)
+	}
+}
+
+// nextFor возвращает следующее ждущее сообщение подписки или освобождает подписку
+func (w *WebhookWorker) nextFor(id string) *webhookItem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	items := w.busy[id]
+	if len(items) == 0 {
+		delete(w.busy, id)
+		return nil
+	}
+	w.busy[id] = items[1:]
+	return items[0]
+}
+
+// popDue извлекает из очереди сообщения, время отправки которых наступило
+func (w *WebhookWorker) popDue(now time.Time) []*webhookItem {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var due []*webhookItem
+	for len(w.queue) > 0 && !w.queue[0].due.After(now) {
+		due = append(due, heap.Pop(&w.queue).(*webhookItem))
+	}
+	return due
+}
+
+// next возвращает время ближайшего сообщения
+func (w *WebhookWorker) next() (time.Time, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.queue) == 0 {
+		return time.Time{}, false
+	}
+	return w.queue[0].due, true
+}
+
+// deliver отправляет сообщение и записывает попытку в историю.
+// При ошибке сообщение возвращается в очередь с задержкой, пока не исчерпаны попытки.
+func (w *WebhookWorker) deliver(item *webhookItem) {
+	msg := item.msg
+	// Подписка могла быть удалена, пока сообщение ждало в очереди
+	sub, err := w.subs.Get(item.userID, msg.SubscriptionID)
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return
+	}
+	if err != nil {
+		w.logger.Log(logger.LevelError, "Failed to get webhook subscription", map[string]interface{}{
+			"error":           err.Error(),
+			"subscription_id": msg.SubscriptionID,
+		})
+		return
+	}
+
+	start := time.Now()
+	status, err := w.sender.SendWebhook(sub, msg)
+	delivery := &domain.WebhookDelivery{
+		MessageID:      msg.ID,
+		SubscriptionID: sub.ID,
+		Type:           msg.Change.Type,
+		EventID:        msg.Change.EventID,
+		Attempt:        msg.Attempt,
+		StatusCode:     status,
+		Duration:       time.Since(start),
+		At:             start,
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+	if err := w.subs.AddDelivery(delivery); err != nil && !errors.Is(err, domain.ErrWebhookNotFound) {
+		w.logger.Log(logger.LevelError, "Failed to record webhook delivery", map[string]interface{}{
+			"error":           err.Error(),
+			"subscription_id": sub.ID,
+		})
+	}
+	if err == nil {
+		return
+	}
+
+	attempt := msg.Attempt + 1
+	fields := map[string]interface{}{
+		"error":           err.Error(),
+		"subscription_id": sub.ID,
+		"message_id":      msg.ID,
+		"attempt":         attempt,
+	}
+	if attempt >= w.retry.MaxAttempts {
+		w.logger.Log(logger.LevelError, "Failed to deliver webhook", fields)
+		return
+	}
+
+	retry := *msg
+	retry.Attempt = attempt
+	w.mu.Lock()
+	heap.Push(&w.queue, &webhookItem{
+		msg:    &retry,
+		userID: item.userID,
+		due:    time.Now().Add(w.retry.Backoff(attempt)),
+	})
+	w.mu.Unlock()
+	// Отправка идет вне цикла process: его таймер нужно пересчитать
+	w.signal()
+	w.logger.Log(logger.LevelError, "Failed to deliver webhook, will retry", fields)
+}
@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/oziev02/event-calendar-service/internal/domain"
+	"github.com/oziev02/event-calendar-service/internal/storage"
+)
+
+// flakyWebhookSender отвечает ошибкой failures раз, затем передает сообщения в sent
+type flakyWebhookSender struct {
+	failures int
+	sent     chan *domain.WebhookMessage
+}
+
+func (s *flakyWebhookSender) SendWebhook(sub *domain.WebhookSubscription, msg *domain.WebhookMessage) (int, error) {
+	if s.failures > 0 {
+		s.failures--
+		return http.StatusServiceUnavailable, errors.New("unavailable")
+	}
+	s.sent <- msg
+	return http.StatusOK, nil
+}
+
+func TestWebhookWorker_RetriesAndRecordsHistory(t *testing.T) {
+	subs := storage.NewMemoryWebhookRepository()
+	subs.Create(&domain.WebhookSubscription{ID: "s1", UserID: "user1", URL: "http://example.com", Types: []domain.ChangeType{domain.ChangeCreated}})
+	subs.Create(&domain.WebhookSubscription{ID: "s2", UserID: "user2", URL: "http://example.com"})
+	sender := &flakyWebhookSender{failures: 2, sent: make(chan *domain.WebhookMessage, 10)}
+
+	w := NewWebhookWorker(subs, sender, newTestLogger(), RetryPolicy{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: 20 * time.Millisecond})
+	w.Start()
+	defer w.Stop()
+
+	// Подписка s1 не принимает updated, а s2 принадлежит другому пользователю
+	w.Notify(&domain.Change{Type: domain.ChangeUpdated, UserID: "user1", EventID: "e1"})
+	w.Notify(&domain.Change{Type: domain.ChangeCreated, UserID: "user1", EventID: "e1"})
+
+	select {
+	case msg := <-sender.sent:
+		if msg.SubscriptionID != "s1" || msg.Change.Type != domain.ChangeCreated || msg.Attempt != 2 {
+			t.Errorf("Unexpected message %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Webhook was not delivered after retries")
+	}
+
+	history, _ := subs.Deliveries("s1")
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 recorded attempts, got %d", len(history))
+	}
+	if !history[0].Succeeded() || history[0].Attempt != 2 || history[0].StatusCode != http.StatusOK {
+		t.Errorf("Expected latest attempt to succeed, got %+v", history[0])
+	}
+	if history[2].Succeeded() || history[2].StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected first attempt to fail, got %+v", history[2])
+	}
+	if history[0].MessageID != history[2].MessageID {
+		t.Errorf("Expected the same message ID across attempts")
+	}
+	select {
+	case msg := <-sender.sent:
+		t.Errorf("Unexpected message %+v", msg)
+	default:
+	}
+}
+
+func TestWebhookWorker_GivesUpAfterLastAttempt(t *testing.T) {
+	subs := storage.NewMemoryWebhookRepository()
+	subs.Create(&domain.WebhookSubscription{ID: "s1", UserID: "user1", URL: "http://example.com"})
+	sender := &flakyWebhookSender{failures: 100, sent: make(chan *domain.WebhookMessage, 1)}
+
+	w := NewWebhookWorker(subs, sender, newTestLogger(), RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+	w.Start()
+	defer w.Stop()
+
+	w.Notify(&domain.Change{Type: domain.ChangeDeleted, UserID: "user1", EventID: "e1"})
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		history, _ := subs.Deliveries("s1")
+		if len(history) == 2 && w.Pending() == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected 2 attempts, got %d", len(history))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if history, _ := subs.Deliveries("s1"); len(history) != 2 {
+		t.Errorf("Expected no attempts after the last one, got %d", len(history))
+	}
+}
+
+// blockingWebhookSender не отвечает подписке slow, пока не закрыт release
+type blockingWebhookSender struct {
+	release chan struct{}
+	sent    chan *domain.WebhookMessage
+}
+
+func (s *blockingWebhookSender) SendWebhook(sub *domain.WebhookSubscription, msg *domain.WebhookMessage) (int, error) {
+	if sub.ID == "slow" {
+		<-s.release
+	}
+	s.sent <- msg
+	return http.StatusOK, nil
+}
+
+func TestWebhookWorker_SlowSubscriberDoesNotBlockOthers(t *testing.T) {
+	subs := storage.NewMemoryWebhookRepository()
+	subs.Create(&domain.WebhookSubscription{ID: "slow", UserID: "user1", URL: "http://slow.example.com"})
+	subs.Create(&domain.WebhookSubscription{ID: "fast", UserID: "user2", URL: "http://example.com"})
+	sender := &blockingWebhookSender{release: make(chan struct{}), sent: make(chan *domain.WebhookMessage, 10)}
+
+	w := NewWebhookWorker(subs, sender, newTestLogger(), RetryPolicy{MaxAttempts: 1})
+	w.Start()
+	defer w.Stop()
+
+	w.Notify(&domain.Change{Type: domain.ChangeCreated, UserID: "user1", EventID: "e1"})
+	w.Notify(&domain.Change{Type: domain.ChangeCreated, UserID: "user1", EventID: "e2"})
+	w.Notify(&domain.Change{Type: domain.ChangeCreated, UserID: "user2", EventID: "e3"})
+
+	select {
+	case msg := <-sender.sent:
+		if msg.SubscriptionID != "fast" {
+			t.Errorf("Expected fast subscription first, got %+v", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Slow subscriber blocked delivery to others")
+	}
+
+	// Сообщения медленной подписки отправляются по порядку после ответа
+	close(sender.release)
+	for _, eventID := range []string{"e1", "e2"} {
+		select {
+		case msg := <-sender.sent:
+			if msg.Change.EventID != eventID {
+				t.Errorf("Expected %s, got %s", eventID, msg.Change.EventID)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Message %s was not delivered", eventID)
+		}
+	}
+}